@@ -0,0 +1,102 @@
+package resources
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	"github.com/pkg/errors"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"sigs.k8s.io/kind/pkg/cluster"
+	"sigs.k8s.io/kind/pkg/log"
+)
+
+/*
+DeleteAllEphemeralClusters deletes every kind cluster that was created by an EphemeralCluster with the name prefix,
+along with its kubeconfig file, e.g. the clusters of a test binary that was killed before it could stop them. An empty
+prefix deletes the clusters with the default ephemeral-cluster prefix. Every cluster is attempted, and the errors of
+the clusters that could not be deleted are aggregated.
+
+Example:
+
+	func TestMain(m *testing.M) {
+		code := m.Run()
+
+		err := resources.DeleteAllEphemeralClusters("")
+		if err != nil {
+			fmt.Println(err)
+		}
+
+		os.Exit(code)
+	}
+*/
+func DeleteAllEphemeralClusters(prefix string) error {
+	if prefix == "" {
+		prefix = defaultNamePrefix
+	}
+
+	provider := cluster.NewProvider(cluster.ProviderWithLogger(log.NoopLogger{}))
+
+	clusterNames, err := provider.List()
+	if err != nil {
+		return errors.Wrapf(
+			err,
+			"could not list kind clusters",
+		)
+	}
+
+	errs := []error{}
+	for _, clusterName := range ephemeralClusterNames(prefix, clusterNames) {
+		err := provider.Delete(clusterName, "")
+		if err != nil {
+			errs = append(errs, errors.Wrapf(
+				err,
+				"could not delete ephemeral cluster %s",
+				clusterName,
+			))
+			continue
+		}
+
+		// The kubeconfig files are created by Start in the temporary directory
+		kubeconfigs, err := filepath.Glob(filepath.Join(os.TempDir(), fmt.Sprintf("%s-*.kubeconfig", clusterName)))
+		if err != nil {
+			errs = append(errs, errors.Wrapf(
+				err,
+				"could not find kubeconfig files of ephemeral cluster %s",
+				clusterName,
+			))
+			continue
+		}
+
+		for _, kubeconfig := range kubeconfigs {
+			err := os.Remove(kubeconfig)
+			if err != nil {
+				errs = append(errs, errors.Wrapf(
+					err,
+					"could not delete kubeconfig file %s",
+					kubeconfig,
+				))
+			}
+		}
+	}
+
+	return utilerrors.NewAggregate(errs)
+}
+
+/*
+ephemeralClusterNames returns the cluster names that an EphemeralCluster with the name prefix generates, leaving out
+clusters that only share the prefix.
+*/
+func ephemeralClusterNames(prefix string, clusterNames []string) []string {
+	pattern := regexp.MustCompile(fmt.Sprintf(`^%s-[a-z0-9]{%d}$`, regexp.QuoteMeta(prefix), nameSuffixLength))
+
+	names := []string{}
+	for _, clusterName := range clusterNames {
+		if pattern.MatchString(clusterName) {
+			names = append(names, clusterName)
+		}
+	}
+
+	return names
+}
@@ -0,0 +1,39 @@
+package resources
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/kind/pkg/cluster"
+)
+
+func TestDeleteAllEphemeralClusters(t *testing.T) {
+	t.Run("ephemeralClusterNames_only_matches_generated_names", func(t *testing.T) {
+		names := ephemeralClusterNames("ephemeral-cluster", []string{
+			"ephemeral-cluster-a1b2c3",
+			"ephemeral-cluster-prod-a1b2c3",
+			"ephemeral-cluster-a1b2c3d",
+			"kind",
+			"my-project-a1b2c3",
+		})
+
+		assert.Equal(t, []string{"ephemeral-cluster-a1b2c3"}, names)
+	})
+
+	t.Run("DeleteAllEphemeralClusters_deletes_every_cluster_with_the_prefix", func(t *testing.T) {
+		prefix := "go-kube-cleanup"
+
+		for i := 0; i < 2; i++ {
+			// The clusters are left running, like the clusters of a test binary that was killed
+			ec := NewEphemeralCluster(WithNamePrefix(prefix))
+			require.NoError(t, ec.Start())
+		}
+
+		require.NoError(t, DeleteAllEphemeralClusters(prefix))
+
+		clusterNames, err := cluster.NewProvider().List()
+		require.NoError(t, err)
+		assert.Empty(t, ephemeralClusterNames(prefix, clusterNames))
+	})
+}
@@ -3,11 +3,16 @@ package resources
 import (
 	"fmt"
 	"os"
+	"regexp"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 	"sigs.k8s.io/kind/pkg/apis/config/v1alpha4"
@@ -20,15 +25,52 @@ type EphemeralCluster struct {
 	nodeImage   string `default:"kindest/node"`
 	nodeVersion string `default:"v1.26.2"`
 
-	clusterName string
+	clusterName     string
+	namePrefix      string
+	runtime         ContainerRuntime
+	registryMirrors []registryMirror
 
 	clientset          *kubernetes.Clientset
 	kubeConfigFilePath string
 	provider           *cluster.Provider
 	restConfig         *rest.Config
 	dynamicClient      *dynamic.DynamicClient
+	restClient         rest.Interface
 }
 
+// EphemeralClusterOption configures an EphemeralCluster before it is started
+type EphemeralClusterOption func(*EphemeralCluster)
+
+const (
+	// The prefix of the names of ephemeral clusters, unless another prefix is given with WithNamePrefix
+	defaultNamePrefix = "ephemeral-cluster"
+	// The number of random characters after the prefix of a cluster name
+	nameSuffixLength = 6
+	// The node containers are named after the cluster, e.g. NAME-control-plane, and have to fit in a 63 character hostname
+	maxNamePrefixLength = 40
+)
+
+var (
+	// kind only accepts lower case alphanumeric characters, '-' and '.' in cluster names
+	namePrefixPattern = regexp.MustCompile(`^[a-z0-9]([a-z0-9.-]*[a-z0-9])?$`)
+)
+
+// registryMirror routes the image pulls from a registry through the mirror endpoints
+type registryMirror struct {
+	registry  string
+	endpoints []string
+}
+
+// ContainerRuntime is the container runtime that kind runs the cluster nodes in
+type ContainerRuntime string
+
+const (
+	// RuntimeDetect lets kind detect the container runtime to use
+	RuntimeDetect ContainerRuntime = ""
+	RuntimeDocker ContainerRuntime = "docker"
+	RuntimePodman ContainerRuntime = "podman"
+)
+
 // GenericCluster is a wrapper around a kubernetes clientset and a kubeconfig file.
 type GenericCluster struct {
 	clientset          *kubernetes.Clientset
@@ -36,6 +78,7 @@ type GenericCluster struct {
 	provider           *cluster.Provider
 	restConfig         *rest.Config
 	dynamicClient      *dynamic.DynamicClient
+	restClient         rest.Interface
 }
 
 /*
@@ -70,18 +113,121 @@ func NewExistingCluster(kubeconfig string) (*GenericCluster, error) {
 		)
 	}
 
+	restClient, err := newRESTClient(restConfig)
+	if err != nil {
+		return nil, errors.Wrapf(
+			err,
+			"could not create rest client for generic cluster",
+		)
+	}
+
 	gc.restConfig = restConfig
 	gc.clientset = clientset
 	gc.dynamicClient = dynamicClient
+	gc.restClient = restClient
 	gc.kubeConfigFilePath = kubeconfig
 
 	return gc, nil
 }
 
-func NewEphemeralCluster() *EphemeralCluster {
-	return &EphemeralCluster{
+/*
+NewEphemeralCluster creates a new EphemeralCluster with the given options. The cluster is not created before Start is called.
+
+Example:
+
+	c := resources.NewEphemeralCluster(resources.WithProvider(resources.RuntimePodman))
+	require.NoError(t, c.Start())
+*/
+func NewEphemeralCluster(opts ...EphemeralClusterOption) *EphemeralCluster {
+	ec := &EphemeralCluster{
 		nodeImage:   "kindest/node",
 		nodeVersion: "v1.26.2",
+		namePrefix:  defaultNamePrefix,
+	}
+
+	for _, opt := range opts {
+		opt(ec)
+	}
+
+	return ec
+}
+
+/*
+WithProvider pins the container runtime that kind runs the cluster nodes in, instead of letting kind detect it.
+*/
+func WithProvider(runtime ContainerRuntime) EphemeralClusterOption {
+	return func(ec *EphemeralCluster) {
+		ec.runtime = runtime
+	}
+}
+
+/*
+WithRegistryMirror makes the cluster nodes pull the images of the registry through the mirror endpoint, e.g. a pull-through
+cache of Docker Hub. The option can be given several times, for several registries or several endpoints of a registry.
+
+Example:
+
+	c := resources.NewEphemeralCluster(resources.WithRegistryMirror("docker.io", "http://registry-cache:5000"))
+	require.NoError(t, c.Start())
+*/
+func WithRegistryMirror(registry, mirrorEndpoint string) EphemeralClusterOption {
+	return func(ec *EphemeralCluster) {
+		for i := range ec.registryMirrors {
+			if ec.registryMirrors[i].registry == registry {
+				ec.registryMirrors[i].endpoints = append(ec.registryMirrors[i].endpoints, mirrorEndpoint)
+				return
+			}
+		}
+
+		ec.registryMirrors = append(ec.registryMirrors, registryMirror{
+			registry:  registry,
+			endpoints: []string{mirrorEndpoint},
+		})
+	}
+}
+
+/*
+WithNamePrefix replaces the ephemeral-cluster prefix of the generated cluster name, e.g. to tell apart the clusters of
+several projects sharing a host. The prefix must follow the kind naming rules, which is checked when the cluster is started.
+
+Example:
+
+	c := resources.NewEphemeralCluster(resources.WithNamePrefix("my-project"))
+	require.NoError(t, c.Start())
+*/
+func WithNamePrefix(prefix string) EphemeralClusterOption {
+	return func(ec *EphemeralCluster) {
+		ec.namePrefix = prefix
+	}
+}
+
+/*
+WithNodeVersion replaces the v1.26.2 version of the node image that the cluster is created with, e.g. to test against
+several Kubernetes versions. The version is the tag of the node image.
+
+Example:
+
+	c := resources.NewEphemeralCluster(resources.WithNodeVersion("v1.29.0"))
+	require.NoError(t, c.Start())
+*/
+func WithNodeVersion(version string) EphemeralClusterOption {
+	return func(ec *EphemeralCluster) {
+		ec.nodeVersion = version
+	}
+}
+
+/*
+WithNodeImage replaces the kindest/node image that the cluster is created with, e.g. with a mirror of it or a node
+image built with kind build node-image. The image is given without its tag, which is the node version.
+
+Example:
+
+	c := resources.NewEphemeralCluster(resources.WithNodeImage("myrepo/node"), resources.WithNodeVersion("v1.29.0"))
+	require.NoError(t, c.Start())
+*/
+func WithNodeImage(image string) EphemeralClusterOption {
+	return func(ec *EphemeralCluster) {
+		ec.nodeImage = image
 	}
 }
 
@@ -89,20 +235,162 @@ func (gc *GenericCluster) Client() *kubernetes.Clientset {
 	return gc.clientset
 }
 
+/*
+RESTClient returns a REST client for the cluster, for requests that the typed and dynamic clients cannot make, e.g.
+to the /version or /metrics endpoints. The requests are made with AbsPath.
+
+Example:
+
+	c, err := resources.NewExistingCluster("/Users/billy.bob/path/to/kubeconfig")
+	require.NoError(t, err)
+
+	body, err := c.RESTClient().Get().AbsPath("/version").DoRaw(ctx)
+	require.NoError(t, err)
+*/
+func (gc *GenericCluster) RESTClient() rest.Interface {
+	return gc.restClient
+}
+
+/*
+RestConfig returns the rest config of the cluster, e.g. to build informers or controller-runtime managers. The config
+is shared with the clients of the cluster, so it should be copied with rest.CopyConfig before it is changed.
+
+Example:
+
+	c, err := resources.NewExistingCluster("/Users/billy.bob/path/to/kubeconfig")
+	require.NoError(t, err)
+
+	factory := informers.NewSharedInformerFactory(kubernetes.NewForConfigOrDie(c.RestConfig()), time.Minute)
+*/
+func (gc *GenericCluster) RestConfig() *rest.Config {
+	return gc.restConfig
+}
+
+/*
+DynamicClient returns the dynamic client of the cluster, e.g. to read and write custom resources.
+
+Example:
+
+	c, err := resources.NewExistingCluster("/Users/billy.bob/path/to/kubeconfig")
+	require.NoError(t, err)
+
+	gvr := schema.GroupVersionResource{Group: "example.com", Version: "v1", Resource: "widgets"}
+	list, err := c.DynamicClient().Resource(gvr).Namespace("default").List(ctx, metav1.ListOptions{})
+	require.NoError(t, err)
+*/
+func (gc *GenericCluster) DynamicClient() *dynamic.DynamicClient {
+	return gc.dynamicClient
+}
+
 func (gc *GenericCluster) KubeConfigFilePath() string {
 	return gc.kubeConfigFilePath
 }
 
+/*
+CurrentContext returns the name of the current-context in the kubeconfig file that the GenericCluster was created from.
+
+Example:
+
+	c, err := resources.NewExistingCluster("/Users/billy.bob/path/to/kubeconfig")
+	require.NoError(t, err)
+
+	ctxName, err := c.CurrentContext()
+	require.NoError(t, err)
+*/
+func (gc *GenericCluster) CurrentContext() (string, error) {
+	kubeconfig, err := clientcmd.LoadFromFile(gc.kubeConfigFilePath)
+	if err != nil {
+		return "", errors.Wrapf(
+			err,
+			"could not load kubeconfig file %s",
+			gc.kubeConfigFilePath,
+		)
+	}
+
+	return kubeconfig.CurrentContext, nil
+}
+
 func (ec *EphemeralCluster) image() string {
 	return fmt.Sprintf("%s:%s", ec.nodeImage, ec.nodeVersion)
 }
 
-func (ec *EphemeralCluster) Start() error {
-	provider := cluster.NewProvider(
+// kindConfig returns the kind configuration of the cluster with the given name
+func (ec *EphemeralCluster) kindConfig(clusterName string) *v1alpha4.Cluster {
+	return &v1alpha4.Cluster{
+		Name: clusterName,
+		Nodes: []v1alpha4.Node{
+			{
+				Role:  v1alpha4.ControlPlaneRole,
+				Image: ec.image(),
+			},
+		},
+		ContainerdConfigPatches: ec.containerdConfigPatches(),
+	}
+}
+
+// containerdConfigPatches returns the containerd configuration that routes image pulls through the registry mirrors
+func (ec *EphemeralCluster) containerdConfigPatches() []string {
+	patches := []string{}
+
+	for _, mirror := range ec.registryMirrors {
+		endpoints := []string{}
+		for _, endpoint := range mirror.endpoints {
+			endpoints = append(endpoints, strconv.Quote(endpoint))
+		}
+
+		patches = append(patches, fmt.Sprintf(
+			"[plugins.\"io.containerd.grpc.v1.cri\".registry.mirrors.%s]\n  endpoint = [%s]\n",
+			strconv.Quote(mirror.registry),
+			strings.Join(endpoints, ", "),
+		))
+	}
+
+	return patches
+}
+
+// newClusterName returns a random cluster name with the name prefix of the cluster
+func (ec *EphemeralCluster) newClusterName() (string, error) {
+	if len(ec.namePrefix) > maxNamePrefixLength || !namePrefixPattern.MatchString(ec.namePrefix) {
+		return "", errors.Errorf(
+			"invalid cluster name prefix %q, must be at most %d lower case alphanumeric characters, '-' or '.'",
+			ec.namePrefix,
+			maxNamePrefixLength,
+		)
+	}
+
+	return randomName(len(ec.namePrefix)+1+nameSuffixLength, []string{ec.namePrefix})
+}
+
+func (ec *EphemeralCluster) providerOptions() ([]cluster.ProviderOption, error) {
+	providerOpts := []cluster.ProviderOption{
 		cluster.ProviderWithLogger(log.NoopLogger{}),
-	)
+	}
 
-	clusterName := randomName(24, []string{"ephemeral", "cluster"})
+	switch ec.runtime {
+	case RuntimeDetect:
+	case RuntimeDocker:
+		providerOpts = append(providerOpts, cluster.ProviderWithDocker())
+	case RuntimePodman:
+		providerOpts = append(providerOpts, cluster.ProviderWithPodman())
+	default:
+		return nil, errors.Errorf("unsupported container runtime %s", ec.runtime)
+	}
+
+	return providerOpts, nil
+}
+
+func (ec *EphemeralCluster) Start() error {
+	providerOpts, err := ec.providerOptions()
+	if err != nil {
+		return err
+	}
+
+	provider := cluster.NewProvider(providerOpts...)
+
+	clusterName, err := ec.newClusterName()
+	if err != nil {
+		return err
+	}
 
 	tmpFile, err := os.CreateTemp("", fmt.Sprintf("%s-*.kubeconfig", clusterName))
 	if err != nil {
@@ -113,20 +401,15 @@ func (ec *EphemeralCluster) Start() error {
 		)
 	}
 
-	err = provider.Create(clusterName,
+	release := acquireCreationSlot()
+	err = createCluster(provider, clusterName,
 		cluster.CreateWithKubeconfigPath(tmpFile.Name()),
 		cluster.CreateWithWaitForReady(5*time.Minute),
-		cluster.CreateWithV1Alpha4Config(&v1alpha4.Cluster{
-			Name: clusterName,
-			Nodes: []v1alpha4.Node{
-				{
-					Role:  v1alpha4.ControlPlaneRole,
-					Image: ec.image(),
-				},
-			},
-		}),
+		cluster.CreateWithV1Alpha4Config(ec.kindConfig(clusterName)),
 	)
+	release()
 	if err != nil {
+		os.Remove(tmpFile.Name())
 		return errors.Wrapf(
 			err,
 			"could not create ephemeral cluster %s",
@@ -157,9 +440,19 @@ func (ec *EphemeralCluster) Start() error {
 		)
 	}
 
+	restClient, err := newRESTClient(restConfig)
+	if err != nil {
+		return errors.Wrapf(
+			err,
+			"could not create rest client for ephemeral cluster %s",
+			clusterName,
+		)
+	}
+
 	ec.restConfig = restConfig
 	ec.clientset = clientset
 	ec.dynamicClient = dynamicClient
+	ec.restClient = restClient
 	ec.clusterName = clusterName
 	ec.kubeConfigFilePath = tmpFile.Name()
 	ec.provider = provider
@@ -200,3 +493,62 @@ func (ec *EphemeralCluster) KubeConfigFilePath() string {
 func (ec *EphemeralCluster) Client() *kubernetes.Clientset {
 	return ec.clientset
 }
+
+/*
+RESTClient returns a REST client for the cluster, for requests that the typed and dynamic clients cannot make, e.g.
+to the /version or /metrics endpoints. The requests are made with AbsPath.
+
+Example:
+
+	c := resources.NewEphemeralCluster()
+	require.NoError(t, c.Start())
+
+	body, err := c.RESTClient().Get().AbsPath("/version").DoRaw(ctx)
+	require.NoError(t, err)
+*/
+func (ec *EphemeralCluster) RESTClient() rest.Interface {
+	return ec.restClient
+}
+
+/*
+RestConfig returns the rest config of the cluster, e.g. to build informers or controller-runtime managers. The config
+is shared with the clients of the cluster, so it should be copied with rest.CopyConfig before it is changed. The
+cluster must be started.
+
+Example:
+
+	c := resources.NewEphemeralCluster()
+	require.NoError(t, c.Start())
+
+	factory := informers.NewSharedInformerFactory(kubernetes.NewForConfigOrDie(c.RestConfig()), time.Minute)
+*/
+func (ec *EphemeralCluster) RestConfig() *rest.Config {
+	return ec.restConfig
+}
+
+/*
+DynamicClient returns the dynamic client of the cluster, e.g. to read and write custom resources. The cluster must be
+started.
+
+Example:
+
+	c := resources.NewEphemeralCluster()
+	require.NoError(t, c.Start())
+
+	gvr := schema.GroupVersionResource{Group: "example.com", Version: "v1", Resource: "widgets"}
+	list, err := c.DynamicClient().Resource(gvr).Namespace("default").List(ctx, metav1.ListOptions{})
+	require.NoError(t, err)
+*/
+func (ec *EphemeralCluster) DynamicClient() *dynamic.DynamicClient {
+	return ec.dynamicClient
+}
+
+// newRESTClient returns a REST client that is not bound to an API group, as the requests give their absolute path
+func newRESTClient(restConfig *rest.Config) (*rest.RESTClient, error) {
+	config := rest.CopyConfig(restConfig)
+	config.GroupVersion = &schema.GroupVersion{}
+	config.APIPath = "/"
+	config.NegotiatedSerializer = scheme.Codecs.WithoutConversion()
+
+	return rest.UnversionedRESTClientFor(config)
+}
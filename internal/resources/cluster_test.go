@@ -0,0 +1,221 @@
+package resources
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/version"
+	"k8s.io/client-go/kubernetes"
+)
+
+const testKubeConfig = `
+apiVersion: v1
+kind: Config
+clusters:
+- name: test-cluster
+  cluster:
+    server: https://127.0.0.1:6443
+contexts:
+- name: test-context
+  context:
+    cluster: test-cluster
+    user: test-user
+- name: other-context
+  context:
+    cluster: test-cluster
+    user: test-user
+current-context: test-context
+users:
+- name: test-user
+  user:
+    token: not-a-real-token
+`
+
+func writeTestKubeConfig(t *testing.T) string {
+	tmpFile, err := os.CreateTemp("", "test-kubeconfig-*")
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		os.Remove(tmpFile.Name())
+	})
+
+	_, err = tmpFile.WriteString(testKubeConfig)
+	require.NoError(t, err)
+	require.NoError(t, tmpFile.Close())
+
+	return tmpFile.Name()
+}
+
+func TestGenericCluster(t *testing.T) {
+	t.Run("CurrentContext_returns_the_kubeconfig_current_context", func(t *testing.T) {
+		c, err := NewExistingCluster(writeTestKubeConfig(t))
+		require.NoError(t, err)
+
+		ctxName, err := c.CurrentContext()
+		assert.NoError(t, err)
+		assert.Equal(t, "test-context", ctxName)
+	})
+
+	t.Run("RESTClient_is_created_with_the_cluster", func(t *testing.T) {
+		c, err := NewExistingCluster(writeTestKubeConfig(t))
+		require.NoError(t, err)
+
+		assert.NotNil(t, c.RESTClient())
+	})
+
+	t.Run("RestConfig_and_DynamicClient_are_created_with_the_cluster", func(t *testing.T) {
+		c, err := NewExistingCluster(writeTestKubeConfig(t))
+		require.NoError(t, err)
+
+		require.NotNil(t, c.RestConfig())
+		assert.Equal(t, "https://127.0.0.1:6443", c.RestConfig().Host)
+		assert.Equal(t, "not-a-real-token", c.RestConfig().BearerToken)
+		assert.NotNil(t, c.DynamicClient())
+	})
+}
+
+func TestRESTClient(t *testing.T) {
+	c := NewEphemeralCluster()
+	require.NoError(t, c.Start())
+
+	t.Cleanup(func() {
+		require.NoError(t, c.Stop())
+	})
+
+	t.Run("RESTClient_can_get_the_server_version", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		statusCode := 0
+		body, err := c.RESTClient().Get().AbsPath("/version").Do(ctx).StatusCode(&statusCode).Raw()
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, statusCode)
+
+		info := version.Info{}
+		require.NoError(t, json.Unmarshal(body, &info))
+		assert.NotEmpty(t, info.GitVersion)
+	})
+
+	t.Run("DynamicClient_can_list_namespaces_with_the_RestConfig", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		gvr := schema.GroupVersionResource{Version: "v1", Resource: "namespaces"}
+		list, err := c.DynamicClient().Resource(gvr).List(ctx, metav1.ListOptions{})
+		require.NoError(t, err)
+		assert.NotEmpty(t, list.Items)
+
+		clientset, err := kubernetes.NewForConfig(c.RestConfig())
+		require.NoError(t, err)
+
+		_, err = clientset.CoreV1().Namespaces().Get(ctx, "default", metav1.GetOptions{})
+		assert.NoError(t, err)
+	})
+}
+
+func TestEphemeralCluster(t *testing.T) {
+	t.Run("WithProvider_pins_the_container_runtime", func(t *testing.T) {
+		ec := NewEphemeralCluster(WithProvider(RuntimePodman))
+		assert.Equal(t, RuntimePodman, ec.runtime)
+
+		providerOpts, err := ec.providerOptions()
+		assert.NoError(t, err)
+		assert.Len(t, providerOpts, 2)
+	})
+
+	t.Run("WithProvider_rejects_unsupported_runtimes", func(t *testing.T) {
+		ec := NewEphemeralCluster(WithProvider(ContainerRuntime("containerd")))
+
+		assert.Error(t, ec.Start())
+	})
+
+	t.Run("WithProvider_can_start_cluster_with_the_runtime", func(t *testing.T) {
+		for _, runtime := range []ContainerRuntime{RuntimeDocker, RuntimePodman} {
+			if _, err := exec.LookPath(string(runtime)); err != nil {
+				t.Logf("skipping %s as it is not available", runtime)
+				continue
+			}
+
+			ec := NewEphemeralCluster(WithProvider(runtime))
+			require.NoError(t, ec.Start())
+
+			nodes, err := ec.provider.ListNodes(ec.clusterName)
+			assert.NoError(t, err)
+			assert.NotEmpty(t, nodes)
+
+			require.NoError(t, ec.Stop())
+		}
+	})
+
+	t.Run("WithRegistryMirror_adds_containerd_mirror_patches", func(t *testing.T) {
+		ec := NewEphemeralCluster(
+			WithRegistryMirror("docker.io", "http://registry-cache:5000"),
+			WithRegistryMirror("quay.io", "http://quay-cache:5000"),
+			WithRegistryMirror("docker.io", "https://mirror.gcr.io"),
+		)
+
+		config := ec.kindConfig("test-cluster")
+
+		assert.Equal(t, []string{
+			"[plugins.\"io.containerd.grpc.v1.cri\".registry.mirrors.\"docker.io\"]\n  endpoint = [\"http://registry-cache:5000\", \"https://mirror.gcr.io\"]\n",
+			"[plugins.\"io.containerd.grpc.v1.cri\".registry.mirrors.\"quay.io\"]\n  endpoint = [\"http://quay-cache:5000\"]\n",
+		}, config.ContainerdConfigPatches)
+	})
+
+	t.Run("WithNamePrefix_replaces_the_cluster_name_prefix", func(t *testing.T) {
+		defaultName, err := NewEphemeralCluster().newClusterName()
+		require.NoError(t, err)
+		assert.Len(t, defaultName, 24)
+		assert.True(t, strings.HasPrefix(defaultName, "ephemeral-cluster-"))
+
+		name, err := NewEphemeralCluster(WithNamePrefix("my-project")).newClusterName()
+		require.NoError(t, err)
+		assert.Regexp(t, `^my-project-[a-z0-9]{6}$`, name)
+	})
+
+	t.Run("WithNamePrefix_rejects_invalid_prefixes", func(t *testing.T) {
+		for _, prefix := range []string{"", "My-Project", "my_project", "-project", strings.Repeat("a", 41)} {
+			ec := NewEphemeralCluster(WithNamePrefix(prefix))
+
+			_, err := ec.newClusterName()
+			assert.Error(t, err, prefix)
+			assert.Error(t, ec.Start(), prefix)
+		}
+	})
+
+	t.Run("WithNamePrefix_can_start_cluster_with_the_prefix", func(t *testing.T) {
+		ec := NewEphemeralCluster(WithNamePrefix("go-kube-test"))
+		require.NoError(t, ec.Start())
+
+		t.Cleanup(func() {
+			require.NoError(t, ec.Stop())
+		})
+
+		assert.True(t, strings.HasPrefix(ec.clusterName, "go-kube-test-"))
+	})
+
+	t.Run("WithNodeVersion_and_WithNodeImage_replace_the_node_image", func(t *testing.T) {
+		assert.Equal(t, "kindest/node:v1.26.2", NewEphemeralCluster().image())
+		assert.Equal(t, "kindest/node:v1.29.0", NewEphemeralCluster(WithNodeVersion("v1.29.0")).image())
+
+		ec := NewEphemeralCluster(WithNodeImage("myrepo/node"), WithNodeVersion("v1.29.0"))
+		assert.Equal(t, "myrepo/node:v1.29.0", ec.image())
+		assert.Equal(t, "myrepo/node:v1.29.0", ec.kindConfig("test-cluster").Nodes[0].Image)
+	})
+
+	t.Run("NewEphemeralCluster_has_no_containerd_patches_by_default", func(t *testing.T) {
+		config := NewEphemeralCluster().kindConfig("test-cluster")
+
+		assert.Empty(t, config.ContainerdConfigPatches)
+	})
+}
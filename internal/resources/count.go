@@ -0,0 +1,58 @@
+package resources
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+/*
+CountResources counts the resources of the given kind in the namespace that match the label selector.
+An empty namespace counts the resources in every namespace, and an empty selector matches every resource.
+
+Example:
+
+	c, err := resources.NewExistingCluster("/Users/billy.bob/path/to/kubeconfig")
+	require.NoError(t, err)
+
+	count, err := c.CountResources(ctx, schema.GroupVersionResource{Version: "v1", Resource: "pods"}, "default", "app=nginx")
+	require.NoError(t, err)
+*/
+func (gc *GenericCluster) CountResources(ctx context.Context, gvr schema.GroupVersionResource, namespace, labelSelector string) (int, error) {
+	return countResources(ctx, gc.dynamicClient, gvr, namespace, labelSelector)
+}
+
+/*
+CountResources counts the resources of the given kind in the namespace that match the label selector.
+An empty namespace counts the resources in every namespace, and an empty selector matches every resource.
+
+Example:
+
+	c := resources.NewEphemeralCluster()
+	require.NoError(t, c.Start())
+
+	count, err := c.CountResources(ctx, schema.GroupVersionResource{Version: "v1", Resource: "pods"}, "default", "app=nginx")
+	require.NoError(t, err)
+*/
+func (ec *EphemeralCluster) CountResources(ctx context.Context, gvr schema.GroupVersionResource, namespace, labelSelector string) (int, error) {
+	return countResources(ctx, ec.dynamicClient, gvr, namespace, labelSelector)
+}
+
+func countResources(ctx context.Context, dynamicClient dynamic.Interface, gvr schema.GroupVersionResource, namespace, labelSelector string) (int, error) {
+	list, err := dynamicClient.Resource(gvr).Namespace(namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: labelSelector,
+	})
+	if err != nil {
+		return 0, errors.Wrapf(
+			err,
+			"could not list %s matching selector %q",
+			gvr.String(),
+			labelSelector,
+		)
+	}
+
+	return len(list.Items), nil
+}
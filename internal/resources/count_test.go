@@ -0,0 +1,65 @@
+package resources
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+func TestCountResources(t *testing.T) {
+	c := NewEphemeralCluster()
+	require.NoError(t, c.Start())
+
+	t.Cleanup(func() {
+		require.NoError(t, c.Stop())
+	})
+
+	t.Run("CountResources_should_count_the_pods_of_a_deployment", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Minute)
+		defer cancel()
+
+		replicas := int32(3)
+		labels := map[string]string{"app": "count-resources"}
+
+		_, err := c.Client().AppsV1().Deployments("default").Create(ctx, &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "count-resources"},
+			Spec: appsv1.DeploymentSpec{
+				Replicas: &replicas,
+				Selector: &metav1.LabelSelector{MatchLabels: labels},
+				Template: corev1.PodTemplateSpec{
+					ObjectMeta: metav1.ObjectMeta{Labels: labels},
+					Spec: corev1.PodSpec{
+						Containers: []corev1.Container{
+							{Name: "app", Image: "busybox:1.36", Command: []string{"sleep", "3600"}},
+						},
+					},
+				},
+			},
+		}, metav1.CreateOptions{})
+		require.NoError(t, err)
+
+		pods := schema.GroupVersionResource{Version: "v1", Resource: "pods"}
+
+		err = wait.PollUntilContextCancel(ctx, time.Second, true, func(ctx context.Context) (bool, error) {
+			count, err := c.CountResources(ctx, pods, "default", "app=count-resources")
+			if err != nil {
+				return false, err
+			}
+
+			return count == 3, nil
+		})
+		require.NoError(t, err)
+
+		count, err := c.CountResources(ctx, pods, "default", "app=does-not-exist")
+		require.NoError(t, err)
+		require.Zero(t, count)
+	})
+}
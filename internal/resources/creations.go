@@ -0,0 +1,59 @@
+package resources
+
+import (
+	"runtime"
+	"sync"
+
+	"sigs.k8s.io/kind/pkg/cluster"
+)
+
+var (
+	// Guards the creation slots, which are replaced when the limit changes
+	creationsMu sync.Mutex
+	// Holds a value for every cluster that is being created, and blocks further creations while it is full
+	creationSlots = make(chan struct{}, runtime.NumCPU())
+
+	// Creates the kind cluster, replaced in tests to observe the creations without a container runtime
+	createCluster = func(provider *cluster.Provider, name string, options ...cluster.CreateOption) error {
+		return provider.Create(name, options...)
+	}
+)
+
+/*
+SetMaxConcurrentClusterCreations limits how many ephemeral clusters are created at once by Start within the process,
+i.e. within a single test binary. Further Starts wait until a creation finishes, which keeps parallel tests of a package
+from overloading the container runtime. go test runs the binaries of several packages as separate processes, which
+each have their own limit, so use -p to bound the packages that are tested in parallel. The limit defaults to the
+number of CPUs, and values below one are treated as one. Creations that already wait keep waiting for the previous
+limit.
+
+Example:
+
+	func TestMain(m *testing.M) {
+		resources.SetMaxConcurrentClusterCreations(2)
+		os.Exit(m.Run())
+	}
+*/
+func SetMaxConcurrentClusterCreations(n int) {
+	if n < 1 {
+		n = 1
+	}
+
+	creationsMu.Lock()
+	defer creationsMu.Unlock()
+
+	creationSlots = make(chan struct{}, n)
+}
+
+/*
+acquireCreationSlot waits until a cluster may be created, and returns the function that releases the slot again.
+*/
+func acquireCreationSlot() func() {
+	creationsMu.Lock()
+	slots := creationSlots
+	creationsMu.Unlock()
+
+	slots <- struct{}{}
+
+	return func() { <-slots }
+}
@@ -0,0 +1,70 @@
+package resources
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/kind/pkg/cluster"
+)
+
+func TestMaxConcurrentClusterCreations(t *testing.T) {
+	t.Run("Start_should_not_create_more_clusters_at_once_than_the_limit", func(t *testing.T) {
+		current := atomic.Int32{}
+		peak := atomic.Int32{}
+
+		originalCreate := createCluster
+		t.Cleanup(func() {
+			createCluster = originalCreate
+			SetMaxConcurrentClusterCreations(runtime.NumCPU())
+		})
+
+		// The wrapped creation records how many creations run at once, and fails such that no cluster is created
+		createCluster = func(provider *cluster.Provider, name string, options ...cluster.CreateOption) error {
+			n := current.Add(1)
+			defer current.Add(-1)
+
+			for {
+				p := peak.Load()
+				if n <= p || peak.CompareAndSwap(p, n) {
+					break
+				}
+			}
+
+			time.Sleep(50 * time.Millisecond)
+
+			return assert.AnError
+		}
+
+		SetMaxConcurrentClusterCreations(2)
+
+		wg := sync.WaitGroup{}
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+
+				assert.Error(t, NewEphemeralCluster().Start())
+			}()
+		}
+		wg.Wait()
+
+		assert.Equal(t, int32(2), peak.Load())
+	})
+
+	t.Run("SetMaxConcurrentClusterCreations_should_allow_at_least_one_creation", func(t *testing.T) {
+		t.Cleanup(func() {
+			SetMaxConcurrentClusterCreations(runtime.NumCPU())
+		})
+
+		SetMaxConcurrentClusterCreations(0)
+
+		release := acquireCreationSlot()
+		release()
+
+		assert.Equal(t, 1, cap(creationSlots))
+	})
+}
@@ -0,0 +1,60 @@
+package resources
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+/*
+DeleteResource deletes the resource with the name in the namespace through the dynamic client. The namespace is left
+empty for cluster scoped resources. When ignoreNotFound is set, a resource that does not exist is not an error.
+
+Example:
+
+	c, err := resources.NewExistingCluster("/Users/billy.bob/path/to/kubeconfig")
+	require.NoError(t, err)
+
+	err = c.DeleteResource(ctx, schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}, "default", "my-config", metav1.DeleteOptions{}, true)
+	require.NoError(t, err)
+*/
+func (gc *GenericCluster) DeleteResource(ctx context.Context, gvr schema.GroupVersionResource, namespace, name string, opts metav1.DeleteOptions, ignoreNotFound bool) error {
+	return deleteResource(ctx, gc.dynamicClient, gvr, namespace, name, opts, ignoreNotFound)
+}
+
+/*
+DeleteResource deletes the resource with the name in the namespace through the dynamic client. The namespace is left
+empty for cluster scoped resources. When ignoreNotFound is set, a resource that does not exist is not an error.
+
+Example:
+
+	c := resources.NewEphemeralCluster()
+	require.NoError(t, c.Start())
+
+	err := c.DeleteResource(ctx, schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}, "default", "my-config", metav1.DeleteOptions{}, true)
+	require.NoError(t, err)
+*/
+func (ec *EphemeralCluster) DeleteResource(ctx context.Context, gvr schema.GroupVersionResource, namespace, name string, opts metav1.DeleteOptions, ignoreNotFound bool) error {
+	return deleteResource(ctx, ec.dynamicClient, gvr, namespace, name, opts, ignoreNotFound)
+}
+
+func deleteResource(ctx context.Context, dynamicClient dynamic.Interface, gvr schema.GroupVersionResource, namespace, name string, opts metav1.DeleteOptions, ignoreNotFound bool) error {
+	err := dynamicClient.Resource(gvr).Namespace(namespace).Delete(ctx, name, opts)
+	if ignoreNotFound && apierrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return errors.Wrapf(
+			err,
+			"could not delete %s %s",
+			gvr.String(),
+			name,
+		)
+	}
+
+	return nil
+}
@@ -0,0 +1,54 @@
+package resources
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestDeleteResource(t *testing.T) {
+	c := NewEphemeralCluster()
+	require.NoError(t, c.Start())
+
+	t.Cleanup(func() {
+		require.NoError(t, c.Stop())
+	})
+
+	configMaps := schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+
+	t.Run("DeleteResource_should_delete_the_resource", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		_, err := c.Client().CoreV1().ConfigMaps("default").Create(ctx, &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "delete-resource"},
+		}, metav1.CreateOptions{})
+		require.NoError(t, err)
+
+		err = c.DeleteResource(ctx, configMaps, "default", "delete-resource", metav1.DeleteOptions{}, false)
+		require.NoError(t, err)
+
+		_, err = c.Client().CoreV1().ConfigMaps("default").Get(ctx, "delete-resource", metav1.GetOptions{})
+		assert.True(t, apierrors.IsNotFound(err))
+	})
+
+	t.Run("DeleteResource_should_only_ignore_missing_resources_when_asked", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		err := c.DeleteResource(ctx, configMaps, "default", "does-not-exist", metav1.DeleteOptions{}, true)
+		assert.NoError(t, err)
+
+		err = c.DeleteResource(ctx, configMaps, "default", "does-not-exist", metav1.DeleteOptions{}, false)
+		assert.True(t, apierrors.IsNotFound(errors.Cause(err)))
+	})
+}
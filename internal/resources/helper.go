@@ -1,16 +1,21 @@
 package resources
 
 import (
-	"math/rand"
+	"crypto/rand"
+	"math/big"
 	"strings"
+
+	"github.com/pkg/errors"
 )
 
 var (
 	allowedChars    = []byte("abcdefghijklmnopqrstuvwxyz0123456789")
-	allowedCharsLen = len(allowedChars)
+	allowedCharsLen = big.NewInt(int64(len(allowedChars)))
 )
 
-func randomName(length int, prefixes []string) string {
+// randomName returns the prefixes joined by '-' with a random suffix, up to the length. The suffix is drawn from
+// crypto/rand, such that processes starting at the same instant do not get the same names
+func randomName(length int, prefixes []string) (string, error) {
 	builder := strings.Builder{}
 
 	builder.WriteString(strings.Join(prefixes, "-"))
@@ -18,12 +23,17 @@ func randomName(length int, prefixes []string) string {
 
 	remainingLength := length - builder.Len()
 	if remainingLength <= 0 {
-		return builder.String()
+		return builder.String(), nil
 	}
 
 	for i := 0; i < remainingLength; i++ {
-		builder.WriteByte(allowedChars[rand.Intn(allowedCharsLen)])
+		n, err := rand.Int(rand.Reader, allowedCharsLen)
+		if err != nil {
+			return "", errors.Wrap(err, "could not generate random name")
+		}
+
+		builder.WriteByte(allowedChars[n.Int64()])
 	}
 
-	return builder.String()
+	return builder.String(), nil
 }
@@ -0,0 +1,42 @@
+package resources
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRandomName(t *testing.T) {
+	t.Run("randomName_should_not_repeat_names", func(t *testing.T) {
+		seen := map[string]bool{}
+
+		for i := 0; i < 5000; i++ {
+			name, err := randomName(len("go-kube")+1+10, []string{"go-kube"})
+			require.NoError(t, err)
+
+			require.False(t, seen[name], "duplicate name %s", name)
+			seen[name] = true
+		}
+	})
+
+	t.Run("randomName_should_fill_the_length_with_allowed_characters", func(t *testing.T) {
+		name, err := randomName(len("go-kube")+1+nameSuffixLength, []string{"go-kube"})
+		require.NoError(t, err)
+
+		suffix, found := strings.CutPrefix(name, "go-kube-")
+		require.True(t, found, name)
+		assert.Len(t, suffix, nameSuffixLength)
+
+		for _, char := range []byte(suffix) {
+			assert.Contains(t, string(allowedChars), string(char))
+		}
+	})
+
+	t.Run("randomName_should_return_the_prefixes_when_there_is_no_room_left", func(t *testing.T) {
+		name, err := randomName(3, []string{"go", "kube"})
+		require.NoError(t, err)
+		assert.Equal(t, "go-kube-", name)
+	})
+}
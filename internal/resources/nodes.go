@@ -0,0 +1,117 @@
+package resources
+
+import (
+	"context"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// NodeContainerInfo is the state of the container that a node of an EphemeralCluster runs in
+type NodeContainerInfo struct {
+	// The name of the node, which is also the name of its container
+	Name string
+	// The role of the node, e.g. control-plane
+	Role string
+	// The state of the container, e.g. running or exited
+	State string
+	// How many times the container runtime restarted the container
+	RestartCount int
+}
+
+/*
+NodeContainerStatus returns the state of the container of every node in the cluster, as reported by the container
+runtime. This helps diagnose a cluster that never became ready, e.g. because a node container exited.
+
+Example:
+
+	c := resources.NewEphemeralCluster()
+	err := c.Start()
+	if err != nil {
+		nodes, _ := c.NodeContainerStatus(context.Background())
+		t.Logf("nodes: %+v", nodes)
+	}
+*/
+func (ec *EphemeralCluster) NodeContainerStatus(ctx context.Context) ([]NodeContainerInfo, error) {
+	if ec.provider == nil {
+		return nil, errors.New("ephemeral cluster is not started")
+	}
+
+	runtime, err := ec.runtimeBinary()
+	if err != nil {
+		return nil, err
+	}
+
+	nodes, err := ec.provider.ListNodes(ec.clusterName)
+	if err != nil {
+		return nil, errors.Wrapf(
+			err,
+			"could not list nodes of ephemeral cluster %s",
+			ec.clusterName,
+		)
+	}
+
+	infos := []NodeContainerInfo{}
+	for _, node := range nodes {
+		role, err := node.Role()
+		if err != nil {
+			return nil, errors.Wrapf(
+				err,
+				"could not get role of node %s",
+				node.String(),
+			)
+		}
+
+		out, err := exec.CommandContext(ctx, runtime, "inspect", "--format", "{{.State.Status}} {{.RestartCount}}", node.String()).Output()
+		if err != nil {
+			return nil, errors.Wrapf(
+				err,
+				"could not inspect container of node %s",
+				node.String(),
+			)
+		}
+
+		fields := strings.Fields(string(out))
+		if len(fields) != 2 {
+			return nil, errors.Errorf("unexpected inspect output for node %s: %q", node.String(), string(out))
+		}
+
+		restartCount, err := strconv.Atoi(fields[1])
+		if err != nil {
+			return nil, errors.Wrapf(
+				err,
+				"could not parse restart count of node %s",
+				node.String(),
+			)
+		}
+
+		infos = append(infos, NodeContainerInfo{
+			Name:         node.String(),
+			Role:         role,
+			State:        fields[0],
+			RestartCount: restartCount,
+		})
+	}
+
+	return infos, nil
+}
+
+/*
+runtimeBinary returns the binary of the container runtime that the cluster runs in. When the runtime is detected,
+docker is preferred over podman, the same way kind detects it.
+*/
+func (ec *EphemeralCluster) runtimeBinary() (string, error) {
+	if ec.runtime != RuntimeDetect {
+		return string(ec.runtime), nil
+	}
+
+	for _, runtime := range []ContainerRuntime{RuntimeDocker, RuntimePodman} {
+		if _, err := exec.LookPath(string(runtime)); err == nil {
+			return string(runtime), nil
+		}
+	}
+
+	return "", errors.New("could not detect the container runtime of the ephemeral cluster")
+}
@@ -0,0 +1,37 @@
+package resources
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNodeContainerStatus(t *testing.T) {
+	c := NewEphemeralCluster()
+	require.NoError(t, c.Start())
+
+	t.Cleanup(func() {
+		require.NoError(t, c.Stop())
+	})
+
+	t.Run("NodeContainerStatus_should_report_running_nodes", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		nodes, err := c.NodeContainerStatus(ctx)
+		require.NoError(t, err)
+		require.Len(t, nodes, 1)
+
+		assert.Equal(t, "control-plane", nodes[0].Role)
+		assert.Equal(t, "running", nodes[0].State)
+		assert.Zero(t, nodes[0].RestartCount)
+	})
+
+	t.Run("NodeContainerStatus_should_fail_before_the_cluster_is_started", func(t *testing.T) {
+		_, err := NewEphemeralCluster().NodeContainerStatus(context.Background())
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,171 @@
+package resources
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/discovery"
+)
+
+const (
+	// The field manager that the kubectl package applies objects with
+	libraryFieldManager = "go-kube"
+)
+
+var (
+	// The namespaces that kind clusters are created with
+	systemNamespaces = sets.New("default", "kube-system", "kube-public", "kube-node-lease", "local-path-storage")
+)
+
+/*
+Reset returns the cluster to the state it was created in, without recreating it. Every namespace that the cluster was
+not created with is deleted along with its contents, and the objects that the kubectl package applied to the namespaces
+the cluster was created with, or applied cluster wide, are deleted. Reset waits until the namespaces are gone.
+
+Example:
+
+	c := resources.NewEphemeralCluster()
+	require.NoError(t, c.Start())
+
+	t.Cleanup(func() {
+		require.NoError(t, c.Reset(context.Background()))
+	})
+*/
+func (ec *EphemeralCluster) Reset(ctx context.Context) error {
+	if ec.clientset == nil {
+		return errors.Errorf("ephemeral cluster is not started")
+	}
+
+	err := ec.deleteLibraryObjects(ctx)
+	if err != nil {
+		return err
+	}
+
+	namespaces, err := ec.clientset.CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return errors.Wrapf(
+			err,
+			"could not list namespaces of ephemeral cluster %s",
+			ec.clusterName,
+		)
+	}
+
+	for _, ns := range namespaces.Items {
+		if systemNamespaces.Has(ns.Name) {
+			continue
+		}
+
+		err := ec.clientset.CoreV1().Namespaces().Delete(ctx, ns.Name, metav1.DeleteOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			return errors.Wrapf(
+				err,
+				"could not delete namespace %s",
+				ns.Name,
+			)
+		}
+	}
+
+	err = wait.PollUntilContextCancel(ctx, time.Second, true, func(ctx context.Context) (bool, error) {
+		namespaces, err := ec.clientset.CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return false, err
+		}
+
+		for _, ns := range namespaces.Items {
+			if !systemNamespaces.Has(ns.Name) {
+				return false, nil
+			}
+		}
+
+		return true, nil
+	})
+	if err != nil {
+		return errors.Wrapf(
+			err,
+			"namespaces of ephemeral cluster %s were not deleted",
+			ec.clusterName,
+		)
+	}
+
+	return nil
+}
+
+/*
+deleteLibraryObjects deletes the cluster scoped objects, and the objects in the system namespaces, that the kubectl
+package applied. The objects in the other namespaces are deleted along with their namespace.
+*/
+func (ec *EphemeralCluster) deleteLibraryObjects(ctx context.Context) error {
+	resourceLists, err := ec.clientset.Discovery().ServerPreferredResources()
+	if err != nil && !discovery.IsGroupDiscoveryFailedError(err) {
+		return errors.Wrapf(
+			err,
+			"could not discover resources of ephemeral cluster %s",
+			ec.clusterName,
+		)
+	}
+
+	for _, resourceList := range resourceLists {
+		gv, err := schema.ParseGroupVersion(resourceList.GroupVersion)
+		if err != nil {
+			continue
+		}
+
+		for _, resource := range resourceList.APIResources {
+			// Subresources, e.g. pods/status, are deleted with their object
+			verbs := sets.New(resource.Verbs...)
+			if strings.Contains(resource.Name, "/") || !verbs.HasAll("list", "delete") || resource.Name == "namespaces" {
+				continue
+			}
+
+			gvr := gv.WithResource(resource.Name)
+
+			list, err := ec.dynamicClient.Resource(gvr).List(ctx, metav1.ListOptions{})
+			if err != nil {
+				return errors.Wrapf(
+					err,
+					"could not list %s",
+					gvr.String(),
+				)
+			}
+
+			for _, obj := range list.Items {
+				if !isLibraryManaged(&obj) {
+					continue
+				}
+
+				// The objects in the other namespaces are deleted with their namespace
+				if resource.Namespaced && !systemNamespaces.Has(obj.GetNamespace()) {
+					continue
+				}
+
+				err := deleteResource(ctx, ec.dynamicClient, gvr, obj.GetNamespace(), obj.GetName(), metav1.DeleteOptions{}, true)
+				if err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+/*
+isLibraryManaged reports whether the kubectl package applied the object.
+*/
+func isLibraryManaged(obj *unstructured.Unstructured) bool {
+	for _, entry := range obj.GetManagedFields() {
+		if entry.Manager == libraryFieldManager {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,81 @@
+package resources
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestReset(t *testing.T) {
+	t.Run("isLibraryManaged_checks_the_field_manager", func(t *testing.T) {
+		obj := &unstructured.Unstructured{}
+		assert.False(t, isLibraryManaged(obj))
+
+		obj.SetManagedFields([]metav1.ManagedFieldsEntry{{Manager: "kubectl-edit"}, {Manager: libraryFieldManager}})
+		assert.True(t, isLibraryManaged(obj))
+	})
+
+	t.Run("Reset_fails_before_the_cluster_is_started", func(t *testing.T) {
+		assert.Error(t, NewEphemeralCluster().Reset(context.Background()))
+	})
+
+	t.Run("Reset_leaves_only_the_system_namespaces", func(t *testing.T) {
+		c := NewEphemeralCluster()
+		require.NoError(t, c.Start())
+
+		t.Cleanup(func() {
+			require.NoError(t, c.Stop())
+		})
+
+		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Minute)
+		defer cancel()
+
+		created := metav1.CreateOptions{FieldManager: libraryFieldManager}
+
+		_, err := c.Client().CoreV1().Namespaces().Create(ctx, &corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{Name: "reset-test"},
+		}, created)
+		require.NoError(t, err)
+
+		_, err = c.Client().CoreV1().ConfigMaps("reset-test").Create(ctx, &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "reset-test"},
+		}, created)
+		require.NoError(t, err)
+
+		_, err = c.Client().CoreV1().ConfigMaps("default").Create(ctx, &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "reset-test"},
+		}, created)
+		require.NoError(t, err)
+
+		_, err = c.Client().RbacV1().ClusterRoles().Create(ctx, &rbacv1.ClusterRole{
+			ObjectMeta: metav1.ObjectMeta{Name: "reset-test"},
+		}, created)
+		require.NoError(t, err)
+
+		require.NoError(t, c.Reset(ctx))
+
+		namespaces, err := c.Client().CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
+		require.NoError(t, err)
+		for _, ns := range namespaces.Items {
+			assert.True(t, systemNamespaces.Has(ns.Name), ns.Name)
+		}
+
+		_, err = c.Client().CoreV1().ConfigMaps("default").Get(ctx, "reset-test", metav1.GetOptions{})
+		assert.True(t, apierrors.IsNotFound(err), "the config map in the default namespace should be deleted")
+
+		_, err = c.Client().RbacV1().ClusterRoles().Get(ctx, "reset-test", metav1.GetOptions{})
+		assert.True(t, apierrors.IsNotFound(err), "the cluster role should be deleted")
+
+		_, err = c.Client().RbacV1().ClusterRoles().Get(ctx, "cluster-admin", metav1.GetOptions{})
+		assert.NoError(t, err, "the cluster roles of the cluster should be kept")
+	})
+}
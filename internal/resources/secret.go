@@ -0,0 +1,93 @@
+package resources
+
+import (
+	"context"
+	"os"
+
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+/*
+NewClusterFromBytes creates a new GenericCluster from the contents of a kubeconfig file. The kubeconfig is written to a
+temporary file, such that KubeConfigFilePath can be given to the kubectl package. The caller may remove the file
+once the cluster is no longer used.
+
+Example:
+
+	c, err := resources.NewClusterFromBytes(kubeconfig)
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		os.Remove(c.KubeConfigFilePath())
+	})
+*/
+func NewClusterFromBytes(kubeconfig []byte) (*GenericCluster, error) {
+	tmpFile, err := os.CreateTemp("", "generic-cluster-*.kubeconfig")
+	if err != nil {
+		return nil, errors.Wrapf(
+			err,
+			"could not create temporary file for kubeconfig",
+		)
+	}
+	defer tmpFile.Close()
+
+	_, err = tmpFile.Write(kubeconfig)
+	if err != nil {
+		os.Remove(tmpFile.Name())
+		return nil, errors.Wrapf(
+			err,
+			"could not write kubeconfig to %s",
+			tmpFile.Name(),
+		)
+	}
+
+	gc, err := NewExistingCluster(tmpFile.Name())
+	if err != nil {
+		os.Remove(tmpFile.Name())
+		return nil, err
+	}
+
+	return gc, nil
+}
+
+/*
+NewClusterFromSecret creates a new GenericCluster from the kubeconfig stored under the key of the Secret in the namespace
+of the management cluster, e.g. the kubeconfig of a cluster that the management cluster provisioned. The cluster is
+created like with NewClusterFromBytes.
+
+Example:
+
+	mgmt, err := resources.NewExistingCluster("/Users/billy.bob/path/to/kubeconfig")
+	require.NoError(t, err)
+
+	c, err := resources.NewClusterFromSecret(ctx, mgmt, "clusters", "child-kubeconfig", "value")
+	require.NoError(t, err)
+*/
+func NewClusterFromSecret(ctx context.Context, mgmt *GenericCluster, namespace, secretName, key string) (*GenericCluster, error) {
+	if mgmt == nil {
+		return nil, errors.Errorf("management cluster cannot be nil")
+	}
+
+	secret, err := mgmt.clientset.CoreV1().Secrets(namespace).Get(ctx, secretName, metav1.GetOptions{})
+	if err != nil {
+		return nil, errors.Wrapf(
+			err,
+			"could not get secret %s/%s",
+			namespace,
+			secretName,
+		)
+	}
+
+	kubeconfig, ok := secret.Data[key]
+	if !ok || len(kubeconfig) == 0 {
+		return nil, errors.Errorf(
+			"secret %s/%s has no kubeconfig under key %s",
+			namespace,
+			secretName,
+			key,
+		)
+	}
+
+	return NewClusterFromBytes(kubeconfig)
+}
@@ -0,0 +1,77 @@
+package resources
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestNewClusterFromBytes(t *testing.T) {
+	t.Run("NewClusterFromBytes_writes_the_kubeconfig_to_a_file", func(t *testing.T) {
+		c, err := NewClusterFromBytes([]byte(testKubeConfig))
+		require.NoError(t, err)
+
+		t.Cleanup(func() {
+			os.Remove(c.KubeConfigFilePath())
+		})
+
+		data, err := os.ReadFile(c.KubeConfigFilePath())
+		require.NoError(t, err)
+		assert.Equal(t, testKubeConfig, string(data))
+
+		ctxName, err := c.CurrentContext()
+		require.NoError(t, err)
+		assert.Equal(t, "test-context", ctxName)
+	})
+
+	t.Run("NewClusterFromBytes_rejects_invalid_kubeconfigs", func(t *testing.T) {
+		_, err := NewClusterFromBytes([]byte("not a kubeconfig"))
+		assert.Error(t, err)
+	})
+}
+
+func TestNewClusterFromSecret(t *testing.T) {
+	ec := NewEphemeralCluster()
+	require.NoError(t, ec.Start())
+
+	t.Cleanup(func() {
+		require.NoError(t, ec.Stop())
+	})
+
+	mgmt, err := NewExistingCluster(ec.KubeConfigFilePath())
+	require.NoError(t, err)
+
+	t.Run("NewClusterFromSecret_creates_a_working_cluster", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		kubeconfig, err := os.ReadFile(ec.KubeConfigFilePath())
+		require.NoError(t, err)
+
+		_, err = mgmt.Client().CoreV1().Secrets("default").Create(ctx, &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "child-kubeconfig"},
+			Data:       map[string][]byte{"value": kubeconfig},
+		}, metav1.CreateOptions{})
+		require.NoError(t, err)
+
+		c, err := NewClusterFromSecret(ctx, mgmt, "default", "child-kubeconfig", "value")
+		require.NoError(t, err)
+
+		t.Cleanup(func() {
+			os.Remove(c.KubeConfigFilePath())
+		})
+
+		_, err = c.Client().CoreV1().Namespaces().Get(ctx, "kube-system", metav1.GetOptions{})
+		assert.NoError(t, err)
+
+		_, err = NewClusterFromSecret(ctx, mgmt, "default", "child-kubeconfig", "missing-key")
+		assert.Error(t, err)
+	})
+}
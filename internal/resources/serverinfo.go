@@ -0,0 +1,65 @@
+package resources
+
+import (
+	"os"
+
+	"github.com/pkg/errors"
+	"k8s.io/client-go/rest"
+)
+
+/*
+ServerInfo returns the URL of the API server of the cluster, and the PEM encoded certificate of the CA that signed
+its serving certificate, e.g. to generate kubeconfigs or webhook configurations.
+
+Example:
+
+	c, err := resources.NewExistingCluster("/Users/billy.bob/path/to/kubeconfig")
+	require.NoError(t, err)
+
+	serverURL, caData, err := c.ServerInfo()
+	require.NoError(t, err)
+*/
+func (gc *GenericCluster) ServerInfo() (serverURL string, caData []byte, err error) {
+	return serverInfo(gc.restConfig)
+}
+
+/*
+ServerInfo returns the URL of the API server of the cluster, and the PEM encoded certificate of the CA that signed
+its serving certificate, e.g. to generate kubeconfigs or webhook configurations. The cluster must be started.
+
+Example:
+
+	c := resources.NewEphemeralCluster()
+	require.NoError(t, c.Start())
+
+	serverURL, caData, err := c.ServerInfo()
+	require.NoError(t, err)
+*/
+func (ec *EphemeralCluster) ServerInfo() (serverURL string, caData []byte, err error) {
+	return serverInfo(ec.restConfig)
+}
+
+func serverInfo(restConfig *rest.Config) (string, []byte, error) {
+	if restConfig == nil {
+		return "", nil, errors.Errorf("cluster has no rest config, has it been started?")
+	}
+
+	caData := restConfig.TLSClientConfig.CAData
+	if len(caData) == 0 && restConfig.TLSClientConfig.CAFile != "" {
+		var err error
+		caData, err = os.ReadFile(restConfig.TLSClientConfig.CAFile)
+		if err != nil {
+			return "", nil, errors.Wrapf(
+				err,
+				"could not read CA file %s",
+				restConfig.TLSClientConfig.CAFile,
+			)
+		}
+	}
+
+	if len(caData) == 0 {
+		return "", nil, errors.Errorf("cluster %s has no CA certificate", restConfig.Host)
+	}
+
+	return restConfig.Host, caData, nil
+}
@@ -0,0 +1,62 @@
+package resources
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"k8s.io/apimachinery/pkg/version"
+	"k8s.io/client-go/rest"
+)
+
+func TestServerInfo(t *testing.T) {
+	t.Run("ServerInfo_should_error_without_a_CA", func(t *testing.T) {
+		c, err := NewExistingCluster(writeTestKubeConfig(t))
+		require.NoError(t, err)
+
+		_, _, err = c.ServerInfo()
+		assert.Error(t, err)
+	})
+
+	t.Run("ServerInfo_should_error_when_the_cluster_is_not_started", func(t *testing.T) {
+		_, _, err := NewEphemeralCluster().ServerInfo()
+		assert.Error(t, err)
+	})
+}
+
+func TestEphemeralClusterServerInfo(t *testing.T) {
+	ec := NewEphemeralCluster()
+	require.NoError(t, ec.Start())
+
+	t.Cleanup(func() {
+		require.NoError(t, ec.Stop())
+	})
+
+	t.Run("ServerInfo_should_return_what_a_client_connects_with", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		serverURL, caData, err := ec.ServerInfo()
+		require.NoError(t, err)
+		assert.NotEmpty(t, serverURL)
+		assert.Contains(t, string(caData), "BEGIN CERTIFICATE")
+
+		// The version is public, so a client that only trusts the CA can get it
+		restClient, err := newRESTClient(&rest.Config{
+			Host:            serverURL,
+			TLSClientConfig: rest.TLSClientConfig{CAData: caData},
+		})
+		require.NoError(t, err)
+
+		body, err := restClient.Get().AbsPath("/version").DoRaw(ctx)
+		require.NoError(t, err)
+
+		info := version.Info{}
+		require.NoError(t, json.Unmarshal(body, &info))
+		assert.NotEmpty(t, info.GitVersion)
+	})
+}
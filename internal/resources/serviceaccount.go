@@ -0,0 +1,113 @@
+package resources
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/pkg/errors"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+const (
+	// How long the tokens of the service account kubeconfigs are valid for
+	serviceAccountTokenExpiration = 3600
+)
+
+/*
+KubeConfigForServiceAccount returns a kubeconfig that authenticates as the ServiceAccount in the namespace, with a token
+requested through the TokenRequest API. The token expires after an hour.
+
+Example:
+
+	c, err := resources.NewExistingCluster("/Users/billy.bob/path/to/kubeconfig")
+	require.NoError(t, err)
+
+	kubeconfig, err := c.KubeConfigForServiceAccount(ctx, "default", "my-service-account")
+	require.NoError(t, err)
+*/
+func (gc *GenericCluster) KubeConfigForServiceAccount(ctx context.Context, namespace, name string) ([]byte, error) {
+	return kubeConfigForServiceAccount(ctx, gc.clientset, gc.restConfig, namespace, name)
+}
+
+/*
+KubeConfigForServiceAccount returns a kubeconfig that authenticates as the ServiceAccount in the namespace, with a token
+requested through the TokenRequest API. The token expires after an hour.
+
+Example:
+
+	c := resources.NewEphemeralCluster()
+	require.NoError(t, c.Start())
+
+	kubeconfig, err := c.KubeConfigForServiceAccount(ctx, "default", "my-service-account")
+	require.NoError(t, err)
+*/
+func (ec *EphemeralCluster) KubeConfigForServiceAccount(ctx context.Context, namespace, name string) ([]byte, error) {
+	return kubeConfigForServiceAccount(ctx, ec.clientset, ec.restConfig, namespace, name)
+}
+
+func kubeConfigForServiceAccount(ctx context.Context, clientset kubernetes.Interface, restConfig *rest.Config, namespace, name string) ([]byte, error) {
+	expiration := int64(serviceAccountTokenExpiration)
+
+	token, err := clientset.CoreV1().ServiceAccounts(namespace).CreateToken(ctx, name, &authenticationv1.TokenRequest{
+		Spec: authenticationv1.TokenRequestSpec{
+			ExpirationSeconds: &expiration,
+		},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		return nil, errors.Wrapf(
+			err,
+			"could not create token for service account %s/%s",
+			namespace,
+			name,
+		)
+	}
+
+	caData := restConfig.CAData
+	if len(caData) == 0 && restConfig.CAFile != "" {
+		caData, err = os.ReadFile(restConfig.CAFile)
+		if err != nil {
+			return nil, errors.Wrapf(
+				err,
+				"could not read certificate authority file %s",
+				restConfig.CAFile,
+			)
+		}
+	}
+
+	contextName := fmt.Sprintf("%s-%s", namespace, name)
+
+	kubeconfig := clientcmdapi.NewConfig()
+	kubeconfig.Clusters[contextName] = &clientcmdapi.Cluster{
+		Server:                   restConfig.Host,
+		CertificateAuthorityData: caData,
+		InsecureSkipTLSVerify:    restConfig.Insecure,
+		TLSServerName:            restConfig.ServerName,
+	}
+	kubeconfig.AuthInfos[contextName] = &clientcmdapi.AuthInfo{
+		Token: token.Status.Token,
+	}
+	kubeconfig.Contexts[contextName] = &clientcmdapi.Context{
+		Cluster:   contextName,
+		AuthInfo:  contextName,
+		Namespace: namespace,
+	}
+	kubeconfig.CurrentContext = contextName
+
+	data, err := clientcmd.Write(*kubeconfig)
+	if err != nil {
+		return nil, errors.Wrapf(
+			err,
+			"could not write kubeconfig for service account %s/%s",
+			namespace,
+			name,
+		)
+	}
+
+	return data, nil
+}
@@ -0,0 +1,80 @@
+package resources
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1apply "k8s.io/client-go/applyconfigurations/core/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+func TestKubeConfigForServiceAccount(t *testing.T) {
+	c := NewEphemeralCluster()
+	require.NoError(t, c.Start())
+
+	t.Cleanup(func() {
+		require.NoError(t, c.Stop())
+	})
+
+	t.Run("KubeConfigForServiceAccount_should_act_as_the_service_account", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		_, err := c.Client().CoreV1().ServiceAccounts("default").Create(ctx, &corev1.ServiceAccount{
+			ObjectMeta: metav1.ObjectMeta{Name: "config-reader"},
+		}, metav1.CreateOptions{})
+		require.NoError(t, err)
+
+		// The service account may only read config maps
+		_, err = c.Client().RbacV1().Roles("default").Create(ctx, &rbacv1.Role{
+			ObjectMeta: metav1.ObjectMeta{Name: "config-reader"},
+			Rules: []rbacv1.PolicyRule{
+				{APIGroups: []string{""}, Resources: []string{"configmaps"}, Verbs: []string{"get", "list"}},
+			},
+		}, metav1.CreateOptions{})
+		require.NoError(t, err)
+
+		_, err = c.Client().RbacV1().RoleBindings("default").Create(ctx, &rbacv1.RoleBinding{
+			ObjectMeta: metav1.ObjectMeta{Name: "config-reader"},
+			RoleRef:    rbacv1.RoleRef{APIGroup: "rbac.authorization.k8s.io", Kind: "Role", Name: "config-reader"},
+			Subjects:   []rbacv1.Subject{{Kind: "ServiceAccount", Name: "config-reader", Namespace: "default"}},
+		}, metav1.CreateOptions{})
+		require.NoError(t, err)
+
+		kubeconfig, err := c.KubeConfigForServiceAccount(ctx, "default", "config-reader")
+		require.NoError(t, err)
+
+		restConfig, err := clientcmd.RESTConfigFromKubeConfig(kubeconfig)
+		require.NoError(t, err)
+
+		clientset, err := kubernetes.NewForConfig(restConfig)
+		require.NoError(t, err)
+
+		_, err = clientset.CoreV1().ConfigMaps("default").List(ctx, metav1.ListOptions{})
+		assert.NoError(t, err, "the service account should be able to list config maps")
+
+		_, err = clientset.CoreV1().ConfigMaps("default").Apply(
+			ctx,
+			corev1apply.ConfigMap("forbidden", "default").WithData(map[string]string{"foo": "bar"}),
+			metav1.ApplyOptions{FieldManager: "go-kube"},
+		)
+		assert.True(t, apierrors.IsForbidden(err), "the service account should not be able to apply config maps")
+	})
+
+	t.Run("KubeConfigForServiceAccount_should_fail_for_missing_service_accounts", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		_, err := c.KubeConfigForServiceAccount(ctx, "default", "does-not-exist")
+		assert.Error(t, err)
+	})
+}
@@ -3,31 +3,212 @@ package kubectl
 import (
 	"context"
 	"fmt"
+	"os"
 	"strings"
-	"sync"
 	"time"
 
-	"k8s.io/cli-runtime/pkg/genericclioptions"
-	"k8s.io/cli-runtime/pkg/genericiooptions"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/rest"
 	"k8s.io/kubectl/pkg/cmd/apply"
 	"k8s.io/kubectl/pkg/cmd/create"
 	"k8s.io/kubectl/pkg/cmd/util"
 )
 
-var (
-	applyMutex = &sync.Mutex{}
-)
-
 type ApplyManifestsOptions struct {
-	// FIXME: When dry-run is working, we should add it here
-	// DryRun    DryRunType
+	/*
+		Runs a dry-run on all resources i.e. kubectl apply --dry-run. The zero value DryRunNone applies the
+		resources, DryRunClient only validates them locally without sending them to the API server, and
+		DryRunServer sends them to the API server without persisting them
+	*/
+	DryRun    DryRunType
 	Recursive bool
+	/*
+		Deletes the objects matching the Selector that are not in the applied manifests i.e. kubectl apply --prune
+	*/
+	Prune bool
+	/*
+		Label selector of the objects to prune, required when Prune is set
+	*/
+	Selector string
+	/*
+		Migrates objects that were applied client-side to server-side apply, by applying them server-side once
+		while forcing conflicts i.e. kubectl apply --server-side --force-conflicts. The fields owned by the
+		client-side apply are taken over by the FieldManager, such that subsequent server-side applies are clean
+	*/
+	MigrateToServerSide bool
+	/*
+		Applies the resources server-side i.e. kubectl apply --server-side
+	*/
+	ServerSide bool
+	/*
+		Decides how conflicts with other field managers are resolved when applying server-side
+	*/
+	ConflictPolicy ConflictPolicy
+	/*
+		Creates the namespaces that the manifests define or reference before applying them, such that namespaced
+		objects never race the creation of their namespace. Namespaces are not created on a dry-run
+	*/
+	EnsureNamespaces bool
+	/*
+		Applies the objects to their subresource server-side, e.g. status, instead of to the objects themselves
+		i.e. kubectl apply --subresource=status. The objects must already exist
+	*/
+	Subresource string
+	/*
+		Applies every object with its own kubectl apply, such that a slow object cannot use up the time of the others.
+		Each object gets the ObjectTimeout, or an even share of the time left of the context when ObjectTimeout is
+		not set. The objects are all attempted, and the result of ApplyManifestsWithResult is returned alongside the
+		error, with the timing and error of every object
+	*/
+	PerObject     bool
+	ObjectTimeout time.Duration
+	/*
+		Discards the warnings of the API server, e.g. about deprecated API versions. Otherwise the warnings are
+		collected in the Warnings of the ApplyResult
+	*/
+	SuppressWarnings bool
+	/*
+		Receives every warning of the API server as it arrives, e.g. to log deprecation notices, instead of the
+		warnings being collected in the Warnings of the ApplyResult. Each warning is only received once per apply.
+		The handler may be called from several goroutines at once. SuppressWarnings takes precedence
+	*/
+	WarningHandler func(warning string)
+	/*
+		Validates every object of the manifests before anything is sent to the cluster. The apply is aborted with
+		the errors of all validators when any object fails validation
+	*/
+	Validators []Validator
+	/*
+		Checks that the image of every container of the manifests exists, in its registry or in the local docker
+		daemon, e.g. images loaded into a kind cluster. The apply is aborted with all missing images before anything
+		is sent to the cluster. Only anonymous access to registries is supported
+	*/
+	VerifyImages bool
+	/*
+		Applies the objects grouped by kind, one group at a time in the order of the kinds, e.g. DefaultInstallOrder.
+		Kinds that are not in the order are applied last. The objects are applied in file order when not set
+	*/
+	InstallOrder []string
+	/*
+		Waits until every applied object is ready, or the context is done. Deployments, StatefulSets, DaemonSets,
+		Pods and Jobs have built-in readiness checks, and failed Jobs end the wait. Other objects with a status are
+		ready once their Ready condition is true, and objects without a status as soon as they exist
+	*/
+	WaitForReady bool
+	/*
+		Readiness checks of kinds, e.g. custom resources, used instead of the built-in checks when waiting for ready
+	*/
+	ReadinessChecks map[schema.GroupVersionKind]ReadinessCheck
+	/*
+		Decides what happens when several manifests define the same object, i.e. the same kind, namespace and name.
+		By default, the apply fails before anything is applied
+	*/
+	DuplicateStrategy DuplicateStrategy
+	/*
+		Decides what happens to objects that are too large for the last-applied annotation of a client-side apply.
+		By default, the apply fails before anything is applied. Server-side applies are not affected
+	*/
+	OversizePolicy OversizePolicy
+	/*
+		Compares the objects to the cluster with a server-side dry-run instead of applying them, and returns a
+		DriftError carrying the diff when any object would be created or changed
+	*/
+	FailOnDrift bool
+	/*
+		Server-side dry-run applies the objects without forcing conflicts instead of applying them, and collects the
+		fields that other field managers own in the Conflicts of the ApplyResult. Nothing is changed, such that the
+		conflicts can be reviewed before applying with ConflictPolicyForce
+	*/
+	PreviewConflicts bool
+	/*
+		Changes every object of the manifests before it is applied, in the order of the transforms. The transforms
+		run after duplicates are resolved
+	*/
+	Transforms []Transform
+	/*
+		Sets the requests and limits that a container does not set itself, on every container of the objects that run
+		pods, e.g. to cap the resources of a test cluster. A default is skipped when it would put the request of a
+		container above its limit. Runs after the Transforms
+	*/
+	DefaultResources *corev1.ResourceRequirements
+	/*
+		Leaves the fields out of every object that has them, such that the apply never claims them and they are left
+		to other managers, e.g. spec.replicas of a Deployment scaled by a HorizontalPodAutoscaler. The fields are
+		given as dot-separated paths. Runs after the DefaultResources
+	*/
+	OmitFields []string
+	/*
+		The Pod Security Standard that the namespaces of the manifests enforce, i.e. privileged, baseline or
+		restricted. The pod-security.kubernetes.io/enforce label is set on the namespaces that the manifests define,
+		unless they set it themselves, and on the namespaces that EnsureNamespaces creates
+	*/
+	PodSecurityLevel string
+	/*
+		Pins the image of every container to the digest its tag resolves to in the registry, e.g. nginx:1.25 is
+		applied as nginx@sha256:..., such that the applied objects keep running the same images when the tags
+		move. Runs after the other transforms, and fails the apply when a tag cannot be resolved. Only anonymous
+		access to registries is supported
+	*/
+	PinImages bool
+	/*
+		Kinds whose objects are created instead of applied i.e. kubectl create, and left as they are when they already
+		exist, such that bundles mixing create-only and applied objects can be applied again, e.g. Jobs. The kinds are
+		given as Kind or Kind.group, e.g. Job or Job.batch. The objects are created after the other objects are
+		applied, and are reported as unchanged in the ApplyResult when they already exist. Pruning is not supported
+	*/
+	CreateOnlyKinds []string
+	/*
+		Applies again while the apply fails because a webhook is still being registered, e.g. right after a controller
+		installed a MutatingWebhookConfiguration and before its TLS bundle is injected or its pods are serving. Only
+		webhook errors about untrusted certificates, refused connections and missing services or endpoints are
+		retried, until the context is done
+	*/
+	RetryWebhookRegistration bool
+	/*
+		Only applies the manifest files that the filter accepts, e.g. the files ending in .prod.yaml. Directories
+		are walked into files first, recursively when Recursive is set, and the filter is given the path of every file
+	*/
+	PathFilter func(path string) bool
+	/*
+		Server-side applies the objects through the API instead of kubectl, up to the given number of objects at once.
+		Namespaces and CRDs are applied before any other object. The objects are all attempted, and the result of
+		ApplyManifestsWithResult is returned alongside the error, with the timing and error of every object. Pruning
+		is not supported, and conflicts are only overridden with ConflictPolicyForce
+	*/
+	Concurrency int
+	/*
+		Is called after every object that is applied with Concurrency, with the time its apply took and its error, if
+		any, e.g. to find the objects that admission is slow for. The callback may be called from several goroutines
+		at once
+	*/
+	OnObjectApplied func(ref ObjectRef, d time.Duration, err error)
+
+	// The field manager of the Source being applied, see Source.WithFieldManager
+	fieldManager string
 }
 
 type ApplyKustomizationOptions struct {
-	// FIXME: When dry-run is working, we should add it here
-	// DryRun    DryRunType
+	/*
+		Runs a dry-run on all resources i.e. kubectl apply --dry-run. The zero value DryRunNone applies the
+		resources, DryRunClient only validates them locally without sending them to the API server, and
+		DryRunServer sends them to the API server without persisting them
+	*/
+	DryRun    DryRunType
 	Recursive bool
+	/*
+		Decides which files the kustomizations may load i.e. kustomize build --load-restrictor. Remote bases, e.g.
+		github.com/org/repo//overlays/prod?ref=v1, are allowed either way
+	*/
+	LoadRestrictor LoadRestrictor
+	/*
+		Bounds how long building the kustomizations may take, including cloning their remote bases with git. The
+		building is bounded by the context alone when not set. Every git command of kustomize is also bounded by
+		the timeout of its URL, e.g. ?timeout=60s, which defaults to 27 seconds
+	*/
+	BuildTimeout time.Duration
 }
 
 /*
@@ -38,13 +219,36 @@ type applyOptions struct {
 		Runs a dry-run on all resources. This is the server-side dry-run i.e. kubectl apply --dry-run=server
 	*/
 	DryRun          DryRunType
-	Recursive       bool `default:"false"`
-	IsKustomization bool `default:"false"`
+	Recursive       bool   `default:"false"`
+	IsKustomization bool   `default:"false"`
+	Prune           bool   `default:"false"`
+	Selector        string `default:""`
+	ServerSide      bool   `default:"false"`
+	ForceConflicts  bool   `default:"false"`
+	/*
+		The field manager of server-side applies, FieldManager when not set. Client-side applies keep the one of kubectl
+	*/
+	FieldManager string `default:""`
+	/*
+		Discards the warnings of the API server instead of collecting them in the Result
+	*/
+	SuppressWarnings bool `default:"false"`
+	/*
+		Receives the warnings of the API server instead of the Result
+	*/
+	WarningHandler func(warning string)
+	/*
+		When set, the outcome of the apply is parsed into the result
+	*/
+	Result *ApplyResult
 }
 
 /*
 ApplyManifests applies the given files to the cluster that the kubeconfigPath points to with the given ApplyManifestsOptions.
 
+The kubectl commands of this package, e.g. apply, delete and patch, never run in parallel, also not against different
+clusters, as kubectl reports their errors through package-global state. Concurrent calls wait for each other.
+
 Example:
 
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
@@ -65,17 +269,263 @@ Example:
 	}
 */
 func ApplyManifests(ctx context.Context, kubeconfigPath string, opts *ApplyManifestsOptions, filePaths ...string) error {
+	_, err := ApplyManifestsWithResult(ctx, kubeconfigPath, opts, filePaths...)
+
+	return err
+}
+
+/*
+ApplyManifestsWithResult applies the given files like ApplyManifests, and returns what kubectl did to each object.
+
+Example:
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	result, err := ApplyManifestsWithResult(
+		ctx,
+		"/path/to/kubeconfig",
+		&ApplyManifestsOptions{
+		},
+		"/path/to/manifest1.yaml",
+	)
+	if err != nil {
+		// Handle error
+	}
+
+	for _, obj := range result.Objects {
+		fmt.Printf("%s/%s %s\n", obj.Kind, obj.Name, obj.Operation)
+	}
+*/
+func ApplyManifestsWithResult(ctx context.Context, kubeconfigPath string, opts *ApplyManifestsOptions, filePaths ...string) (*ApplyResult, error) {
+	if opts == nil {
+		return nil, fmt.Errorf("options cannot be nil")
+	}
+
+	if opts.PathFilter != nil {
+		var err error
+		filePaths, err = filterManifestPaths(opts.PathFilter, opts.Recursive, filePaths...)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if len(opts.Validators) > 0 {
+		err := validateManifests(opts.Validators, opts.Recursive, filePaths...)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	filePaths, cleanup, err := dedupeManifests(opts.DuplicateStrategy, opts.Recursive, filePaths...)
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+
+	transforms, err := applyTransforms(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	filePaths, transformCleanup, err := transformManifests(transforms, opts.Recursive, filePaths...)
+	if err != nil {
+		return nil, err
+	}
+	defer transformCleanup()
+
+	if opts.VerifyImages {
+		err := verifyImages(ctx, opts.Recursive, filePaths...)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if opts.FailOnDrift {
+		result, err := checkDrift(ctx, kubeconfigPath, opts, filePaths...)
+		result.setNamespaces(kubeconfigPath, opts.Recursive, filePaths...)
+
+		return result, err
+	}
+
+	if opts.PreviewConflicts {
+		result, err := previewConflicts(ctx, kubeconfigPath, opts, filePaths...)
+		result.setNamespaces(kubeconfigPath, opts.Recursive, filePaths...)
+
+		return result, err
+	}
+
+	if !isServerSide(opts) && opts.Subresource == "" && opts.OversizePolicy == OversizePolicyError {
+		err := checkObjectSizes(opts.Recursive, filePaths...)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if opts.EnsureNamespaces && opts.DryRun == DryRunNone {
+		err := ensureNamespaces(ctx, kubeconfigPath, podSecurityLabels(opts.PodSecurityLevel), opts.Recursive, filePaths...)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	applyPaths := filePaths
+	createOnly := []*unstructured.Unstructured{}
+
+	if len(opts.CreateOnlyKinds) > 0 {
+		if opts.Prune {
+			return nil, fmt.Errorf("create-only kinds cannot be pruned")
+		}
+
+		var createCleanup func()
+		applyPaths, createOnly, createCleanup, err = splitCreateOnly(opts.CreateOnlyKinds, opts.Recursive, filePaths...)
+		if err != nil {
+			return nil, err
+		}
+		defer createCleanup()
+	}
+
+	result, err := applyWithStrategy(ctx, kubeconfigPath, opts, applyPaths...)
+	if opts.RetryWebhookRegistration && isTransientWebhookError(err) {
+		// The error of the last attempt is returned when the context is done before the webhook is registered
+		_ = wait.PollUntilContextCancel(ctx, webhookRetryInterval, false, func(ctx context.Context) (bool, error) {
+			result, err = applyWithStrategy(ctx, kubeconfigPath, opts, applyPaths...)
+
+			return !isTransientWebhookError(err), nil
+		})
+	}
+
+	if err == nil && len(createOnly) > 0 {
+		var created []ObjectResult
+		created, err = createObjects(ctx, kubeconfigPath, fieldManagerOf(opts), opts.DryRun, createOnly)
+		result.add(created)
+	}
+
+	result.setNamespaces(kubeconfigPath, opts.Recursive, filePaths...)
+	if err != nil {
+		return result, asWebhookError(err)
+	}
+
+	if opts.WaitForReady && opts.DryRun == DryRunNone {
+		err = waitForReady(ctx, kubeconfigPath, opts.ReadinessChecks, opts.Recursive, filePaths...)
+		if err != nil {
+			return result, err
+		}
+	}
+
+	return result, nil
+}
+
+/*
+fieldManagerOf returns the field manager that the options apply with, which is FieldManager unless the Source being
+applied has its own.
+*/
+func fieldManagerOf(opts *ApplyManifestsOptions) string {
+	if opts.fieldManager != "" {
+		return opts.fieldManager
+	}
+
+	return FieldManager
+}
+
+/*
+applyWithStrategy applies the given files in the way that the options ask for, e.g. per object or concurrently.
+*/
+func applyWithStrategy(ctx context.Context, kubeconfigPath string, opts *ApplyManifestsOptions, filePaths ...string) (*ApplyResult, error) {
+	switch {
+	case len(filePaths) == 0:
+		return &ApplyResult{}, nil
+	case opts.Subresource != "":
+		return applySubresource(ctx, kubeconfigPath, opts, filePaths...)
+	case opts.Concurrency > 0:
+		return applyConcurrently(ctx, kubeconfigPath, opts, filePaths...)
+	case opts.InstallOrder != nil:
+		return applyInOrder(ctx, kubeconfigPath, opts, filePaths...)
+	case opts.PerObject:
+		return applyPerObject(ctx, kubeconfigPath, opts, filePaths...)
+	default:
+		return applyManifestsFunc(ctx, kubeconfigPath, opts, filePaths...)
+	}
+}
+
+/*
+applyManifestsFunc applies the given files with a single kubectl apply, retrying on conflicts when the ConflictPolicy says so.
+The result is returned also when the apply fails.
+*/
+func applyManifestsFunc(ctx context.Context, kubeconfigPath string, opts *ApplyManifestsOptions, filePaths ...string) (*ApplyResult, error) {
+	if !isServerSide(opts) && opts.OversizePolicy == OversizePolicyServerSide {
+		return applyOversizedServerSide(ctx, kubeconfigPath, opts, filePaths...)
+	}
+
+	result := &ApplyResult{}
+
 	// Translate ApplyManifestsOptions to ApplyOptions
 	applyOpts := &applyOptions{
-		Recursive:       opts.Recursive,
-		IsKustomization: false,
+		DryRun:           opts.DryRun,
+		Recursive:        opts.Recursive,
+		IsKustomization:  false,
+		Prune:            opts.Prune,
+		Selector:         opts.Selector,
+		ServerSide:       isServerSide(opts),
+		ForceConflicts:   opts.MigrateToServerSide || opts.ConflictPolicy == ConflictPolicyForce,
+		FieldManager:     fieldManagerOf(opts),
+		SuppressWarnings: opts.SuppressWarnings,
+		WarningHandler:   opts.WarningHandler,
+		Result:           result,
 	}
 
-	return applyFunc(ctx, kubeconfigPath, applyOpts, filePaths...)
+	err := applyFunc(ctx, kubeconfigPath, applyOpts, filePaths...)
+	if err != nil && applyOpts.ServerSide && opts.ConflictPolicy == ConflictPolicyRetryMerge && isConflictError(err) {
+		// We retry once, leaving the fields that other field managers own to them
+		mergedPath, mergeErr := withoutForeignFields(ctx, kubeconfigPath, fieldManagerOf(opts), opts.Recursive, filePaths...)
+		if mergeErr != nil {
+			return nil, mergeErr
+		}
+		defer os.Remove(mergedPath)
+
+		result = &ApplyResult{}
+		applyOpts.Result = result
+
+		err = applyFunc(ctx, kubeconfigPath, applyOpts, mergedPath)
+	}
+
+	// The result holds the objects that were applied before the apply failed
+	return result, err
+}
+
+/*
+isServerSide reports whether the options apply the objects server-side.
+*/
+func isServerSide(opts *ApplyManifestsOptions) bool {
+	return opts.ServerSide || opts.MigrateToServerSide || opts.Concurrency > 0
+}
+
+/*
+ApplyChanged applies the given files like ApplyManifests, and reports whether the apply changed anything in the cluster.
+
+Example:
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	changed, err := ApplyChanged(ctx, "/path/to/kubeconfig", &ApplyManifestsOptions{}, "/path/to/manifest1.yaml")
+	if err != nil {
+		// Handle error
+	}
+*/
+func ApplyChanged(ctx context.Context, kubeconfigPath string, opts *ApplyManifestsOptions, filePaths ...string) (bool, error) {
+	result, err := ApplyManifestsWithResult(ctx, kubeconfigPath, opts, filePaths...)
+	if err != nil {
+		return false, err
+	}
+
+	return result.Changed(), nil
 }
 
 /*
 ApplyKustomization applies the given files to the cluster that the kubeconfigPath points to with the given ApplyKustomizationOptions.
+The files are kustomization directories or remote kustomizations, which are built before anything is applied. Remote
+bases are cloned with git, which has to be installed.
 
 Example:
 
@@ -96,23 +546,52 @@ Example:
 	}
 */
 func ApplyKustomization(ctx context.Context, kubeconfigPath string, opts *ApplyKustomizationOptions, filePaths ...string) error {
+	if kubeconfigPath == "" {
+		return fmt.Errorf("kubeconfig path cannot be empty")
+	}
+
+	if opts == nil {
+		return fmt.Errorf("options cannot be nil")
+	}
+
+	if len(filePaths) == 0 {
+		return fmt.Errorf("no files to apply")
+	}
+
+	// The kustomizations are built before applying them, such that remote bases are cloned within the time given
+	buildCtx := ctx
+	if opts.BuildTimeout > 0 {
+		var cancel context.CancelFunc
+		buildCtx, cancel = context.WithTimeout(ctx, opts.BuildTimeout)
+		defer cancel()
+	}
+
+	manifestPath, cleanup, err := buildKustomizations(buildCtx, opts.LoadRestrictor, filePaths...)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
 	// Translate ApplyKustomizationOptions to ApplyOptions
 	applyOpts := &applyOptions{
-		Recursive:       opts.Recursive,
-		IsKustomization: true,
+		DryRun:    opts.DryRun,
+		Recursive: opts.Recursive,
 	}
 
-	return applyFunc(ctx, kubeconfigPath, applyOpts, filePaths...)
+	return applyFunc(ctx, kubeconfigPath, applyOpts, manifestPath)
 }
 
 /*
 Apply applies the given files to the cluster that the kubeconfigPath points to with the given ApplyOptions.
 */
 func applyFunc(ctx context.Context, kubeconfigPath string, opts *applyOptions, filePaths ...string) error {
-	// We lock the mutex as we need to change the global behaviour when
-	// the `kubectl apply` function encounters a fatal error
-	applyMutex.Lock()
-	defer applyMutex.Unlock()
+	// We register the apply as in flight, and lock the kubectl mutex as we need to
+	// change the global behaviour when the `kubectl apply` function encounters a fatal error
+	lock, err := beginKubectl(ctx, "kubectl apply")
+	if err != nil {
+		return err
+	}
+	defer lock.unlock()
 
 	if kubeconfigPath == "" {
 		return fmt.Errorf("kubeconfig path cannot be empty")
@@ -126,16 +605,39 @@ func applyFunc(ctx context.Context, kubeconfigPath string, opts *applyOptions, f
 		return fmt.Errorf("no files to apply")
 	}
 
+	if opts.Prune && opts.Selector == "" {
+		return fmt.Errorf("a selector is required when pruning")
+	}
+
 	// We create empty streams - we don't want to see output from the apply command
-	ioStreams, streamOut, _, streamErr := genericiooptions.NewTestIOStreams()
+	ioStreams, streamOut, streamErr := newIOStreams()
+
+	// The warnings of the API server are logged by default, we collect or discard them instead
+	var warningHandler rest.WarningHandler = rest.NoWarnings{}
+	warnings := &warningCollector{handler: opts.WarningHandler}
+	if !opts.SuppressWarnings {
+		warningHandler = warnings
+	}
 
-	config := genericclioptions.
-		NewConfigFlags(true).
-		WithDeprecatedPasswordFlag().
-		WithDiscoveryBurst(300).
-		WithDiscoveryQPS(50.0)
+	// We use an error channel to communicate if the apply command finished successfully or not
+	errChan := newErrChan()
+
+	// Send an error to the error channel when the context is cancelled or its deadline is reached
+	operation := fmt.Sprintf("kubectl apply of %d files", len(filePaths))
+	timeLeft, stopWatching := watchContext(ctx, operation, errChan, streamOut, streamErr)
+	defer stopWatching()
+
+	// The deadline may have passed while we waited for the kubectl lock
+	if err := expiredError(operation, timeLeft, streamOut, streamErr); err != nil {
+		return err
+	}
+
+	config := withRequestTimeout(newConfigFlags(kubeconfigPath, ""), timeLeft)
+	config.WrapConfigFn = func(c *rest.Config) *rest.Config {
+		c.WarningHandler = warningHandler
+		return c
+	}
 
-	config.KubeConfig = &kubeconfigPath
 	f := util.NewFactory(config)
 
 	// We create a "parent" command for the apply command,
@@ -143,61 +645,61 @@ func applyFunc(ctx context.Context, kubeconfigPath string, opts *applyOptions, f
 	createCmd := create.NewCmdCreate(f, ioStreams)
 	util.AddServerSideApplyFlags(createCmd)
 
-	// We use an error channel to communicate if the apply command finished successfully or not
-	errChan := make(chan error)
-
-	// We find out if the context have a deadline, from there we derive amount of time left
-	deadline, ok := ctx.Deadline()
-	if !ok {
-		deadline = time.Now().Add(15 * time.Second) // This deadline is arbitary
+	// The apply command reads the field manager from the command it is run with,
+	// so it has to be set on the "parent" command. Client-side applies keep the
+	// field manager of kubectl, whose fields server-side applies take over
+	if opts.ServerSide {
+		fieldManager := opts.FieldManager
+		if fieldManager == "" {
+			fieldManager = FieldManager
+		}
+		createCmd.Flags().Set("field-manager", fieldManager)
 	}
-	timeLeft := deadline.Sub(time.Now())
-
-	// Send a timeout error to the error channel when the deadline is reached
-	time.AfterFunc(timeLeft, func() {
-		errChan <- context.DeadlineExceeded
-	})
 
-	// We set a custom handler for when the apply command encounters a fatal error
-	// in kubectl, this is executed when a command fails - it prints the error message
-	// and exits with the given error code
-	util.BehaviorOnFatal(func(msg string, errCode int) {
-		err := fmt.Errorf(
-			"fatal error: %s\nerror code: %d\nout stream: %s\nerror stream: %s\n",
-			msg,
-			errCode,
-			streamOut.String(),
-			streamErr.String(),
-		)
-		errChan <- err
-	})
+	applyCmd := apply.NewCmdApply("kubectl", f, ioStreams)
 
-	// We restore the default behavior for fatal errors when we are done
-	defer util.DefaultBehaviorOnFatal()
+	// Like the field manager, the apply command reads the dry-run strategy and
+	// the server-side apply flags from the "parent" command
+	createCmd.Flags().Set("dry-run", opts.DryRun.String())
 
-	applyCmd := apply.NewCmdApply("kubectl", f, ioStreams)
-	applyCmd.Flags().Set("request-timeout", fmt.Sprint(int(timeLeft.Seconds())))
+	if opts.ServerSide {
+		createCmd.Flags().Set("server-side", "true")
+	}
 
-	// FIXME: setting dry-run to anything doesn't really work
-	applyCmd.Flags().Set("dry-run", DryRunNone.String())
+	if opts.ForceConflicts {
+		createCmd.Flags().Set("force-conflicts", "true")
+	}
 
 	if opts.Recursive {
 		applyCmd.Flags().Set("recursive", "true")
 	}
 
+	if opts.Prune {
+		applyCmd.Flags().Set("prune", "true")
+		applyCmd.Flags().Set("selector", opts.Selector)
+	}
+
 	if opts.IsKustomization {
 		applyCmd.Flags().Set("kustomize", strings.Join(filePaths, ","))
 	} else {
 		applyCmd.Flags().Set("filename", strings.Join(filePaths, ","))
 	}
 
-	go func() {
+	lock.run(errChan, streamOut, streamErr, func() error {
 		// applyCmd is blocking. Should it fail it should have called the fatal error handler which
-		// we override earlier to send an error to errChan
+		// run overrides to send an error to errChan
 		applyCmd.Run(createCmd, []string{})
-		errChan <- nil
-	}()
+		return nil
+	})
 
 	// We return the first item in the error channel
-	return <-errChan
+	err = <-errChan
+
+	// kubectl prints the objects it applied before it failed as well, such that they can be cleaned up
+	if opts.Result != nil {
+		opts.Result.add(parseApplyOutput(streamOut.String()))
+		opts.Result.Warnings = append(opts.Result.Warnings, warnings.Warnings()...)
+	}
+
+	return err
 }
@@ -69,6 +69,212 @@ metadata:
 		})
 	})
 
+	t.Run("ApplyChanged_reports_whether_the_cluster_changed", func(t *testing.T) {
+		t.Parallel()
+
+		randomNS := fmt.Sprintf("test-ns-%s", uuid.New().String())
+
+		tmpFile, err := os.CreateTemp("", "test-apply-*.yaml")
+		require.NoError(t, err)
+		t.Cleanup(func() {
+			os.Remove(tmpFile.Name())
+		})
+
+		_, err = tmpFile.WriteString(namespaceManifest(randomNS))
+		require.NoError(t, err)
+		require.NoError(t, tmpFile.Close())
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		changed, err := ApplyChanged(ctx, c.KubeConfigFilePath(), &ApplyManifestsOptions{}, tmpFile.Name())
+		require.NoError(t, err)
+		assert.True(t, changed, "first apply should create the namespace")
+
+		changed, err = ApplyChanged(ctx, c.KubeConfigFilePath(), &ApplyManifestsOptions{}, tmpFile.Name())
+		require.NoError(t, err)
+		assert.False(t, changed, "second apply should leave the namespace unchanged")
+
+		t.Cleanup(func() {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			defer cancel()
+
+			_ = c.Client().CoreV1().Namespaces().Delete(ctx, randomNS, metav1.DeleteOptions{})
+		})
+	})
+
+	t.Run("ApplyManifestsWithResult_reports_pruned_objects", func(t *testing.T) {
+		t.Parallel()
+
+		label := uuid.New().String()
+		keptName := fmt.Sprintf("test-cm-%s", uuid.New().String())
+		prunedName := fmt.Sprintf("test-cm-%s", uuid.New().String())
+
+		bothFile := writeTestManifest(t, labelledConfigMapManifest(keptName, label)+"---"+labelledConfigMapManifest(prunedName, label))
+		keptFile := writeTestManifest(t, labelledConfigMapManifest(keptName, label))
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		_, err := ApplyManifestsWithResult(ctx, c.KubeConfigFilePath(), &ApplyManifestsOptions{}, bothFile)
+		require.NoError(t, err)
+
+		opts := &ApplyManifestsOptions{
+			Prune:    true,
+			Selector: fmt.Sprintf("test=%s", label),
+		}
+
+		result, err := ApplyManifestsWithResult(ctx, c.KubeConfigFilePath(), opts, keptFile)
+		require.NoError(t, err)
+
+		assert.Equal(t, []ObjectRef{{Kind: "configmap", Name: prunedName}}, result.Pruned)
+		assert.Empty(t, result.WouldPrune)
+
+		_, err = c.Client().CoreV1().ConfigMaps("default").Get(ctx, prunedName, metav1.GetOptions{})
+		assert.Error(t, err, "pruned config map should be deleted")
+
+		t.Cleanup(func() {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			defer cancel()
+
+			_ = c.Client().CoreV1().ConfigMaps("default").Delete(ctx, keptName, metav1.DeleteOptions{})
+		})
+	})
+
+	t.Run("ApplyManifestsWithResult_should_not_create_objects_on_client_dry_run", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		name := fmt.Sprintf("test-cm-%s", uuid.New().String())
+
+		result, err := ApplyManifestsWithResult(ctx, c.KubeConfigFilePath(), &ApplyManifestsOptions{DryRun: DryRunClient}, writeTestManifest(t, configMapDataManifest(name, "foo", "bar")))
+		require.NoError(t, err)
+
+		assert.Equal(t, []ObjectResult{
+			{ObjectRef: ObjectRef{Kind: "configmap", Name: name}, Namespace: "default", Operation: OperationCreated, DryRun: true},
+		}, result.Objects)
+
+		_, err = c.Client().CoreV1().ConfigMaps("default").Get(ctx, name, metav1.GetOptions{})
+		assert.Error(t, err, "config map should not be created on a dry-run")
+	})
+
+	t.Run("ApplyManifestsWithResult_reports_the_namespaces_of_the_objects", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		namespace := fmt.Sprintf("test-ns-%s", uuid.New().String())
+		name := fmt.Sprintf("test-cm-%s", uuid.New().String())
+
+		manifest := writeTestManifest(t, fmt.Sprintf(`apiVersion: v1
+kind: Namespace
+metadata:
+  name: %[1]s
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: %[2]s
+  namespace: %[1]s
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: %[2]s
+`, namespace, name))
+
+		result, err := ApplyManifestsWithResult(ctx, c.KubeConfigFilePath(), &ApplyManifestsOptions{}, manifest)
+		require.NoError(t, err)
+
+		assert.Equal(t, []ObjectResult{
+			{ObjectRef: ObjectRef{Kind: "namespace", Name: namespace}, Operation: OperationCreated},
+			{ObjectRef: ObjectRef{Kind: "configmap", Name: name}, Namespace: namespace, Operation: OperationCreated},
+			{ObjectRef: ObjectRef{Kind: "configmap", Name: name}, Namespace: "default", Operation: OperationCreated},
+		}, result.Objects)
+
+		t.Cleanup(func() {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			defer cancel()
+
+			_ = DeleteManifests(ctx, c.KubeConfigFilePath(), manifest)
+		})
+	})
+
+	t.Run("ApplyManifestsWithResult_reports_would_prune_objects_on_dry_run", func(t *testing.T) {
+		t.Parallel()
+
+		label := uuid.New().String()
+		keptName := fmt.Sprintf("test-cm-%s", uuid.New().String())
+		prunedName := fmt.Sprintf("test-cm-%s", uuid.New().String())
+
+		bothFile := writeTestManifest(t, labelledConfigMapManifest(keptName, label)+"---"+labelledConfigMapManifest(prunedName, label))
+		keptFile := writeTestManifest(t, labelledConfigMapManifest(keptName, label))
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		_, err := ApplyManifestsWithResult(ctx, c.KubeConfigFilePath(), &ApplyManifestsOptions{}, bothFile)
+		require.NoError(t, err)
+
+		opts := &ApplyManifestsOptions{
+			DryRun:   DryRunServer,
+			Prune:    true,
+			Selector: fmt.Sprintf("test=%s", label),
+		}
+
+		result, err := ApplyManifestsWithResult(ctx, c.KubeConfigFilePath(), opts, keptFile)
+		require.NoError(t, err)
+
+		assert.Equal(t, []ObjectRef{{Kind: "configmap", Name: prunedName}}, result.WouldPrune)
+		assert.Empty(t, result.Pruned)
+
+		_, err = c.Client().CoreV1().ConfigMaps("default").Get(ctx, prunedName, metav1.GetOptions{})
+		assert.NoError(t, err, "config map should survive a dry-run prune")
+
+		t.Cleanup(func() {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			defer cancel()
+
+			_ = c.Client().CoreV1().ConfigMaps("default").Delete(ctx, keptName, metav1.DeleteOptions{})
+			_ = c.Client().CoreV1().ConfigMaps("default").Delete(ctx, prunedName, metav1.DeleteOptions{})
+		})
+	})
+
+	t.Run("ApplyManifests_can_migrate_to_server_side_apply", func(t *testing.T) {
+		t.Parallel()
+
+		name := fmt.Sprintf("test-cm-%s", uuid.New().String())
+		manifest := writeTestManifest(t, labelledConfigMapManifest(name, "migrate"))
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		// Create the config map with a client-side apply
+		err := applyFunc(ctx, c.KubeConfigFilePath(), &applyOptions{}, manifest)
+		require.NoError(t, err)
+
+		t.Cleanup(func() {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			defer cancel()
+
+			_ = c.Client().CoreV1().ConfigMaps("default").Delete(ctx, name, metav1.DeleteOptions{})
+		})
+
+		err = ApplyManifests(ctx, c.KubeConfigFilePath(), &ApplyManifestsOptions{MigrateToServerSide: true}, manifest)
+		require.NoError(t, err)
+
+		cm, err := c.Client().CoreV1().ConfigMaps("default").Get(ctx, name, metav1.GetOptions{})
+		require.NoError(t, err)
+
+		for _, entry := range cm.ManagedFields {
+			assert.Equal(t, FieldManager, entry.Manager)
+			assert.Equal(t, metav1.ManagedFieldsOperationApply, entry.Operation)
+		}
+	})
+
 	t.Run("applyFunc_can_apply_kustomization", func(t *testing.T) {
 		t.Parallel()
 
@@ -132,3 +338,33 @@ configMapGenerator:
 		})
 	})
 }
+
+func labelledConfigMapManifest(name, label string) string {
+	return fmt.Sprintf(`
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: %s
+  namespace: default
+  labels:
+    test: %s
+data:
+  foo: bar
+`, name, label)
+}
+
+// writeTestManifest writes the manifest to a temporary file that is removed when the test finishes
+func writeTestManifest(t *testing.T, manifest string) string {
+	tmpFile, err := os.CreateTemp("", "test-manifest-*.yaml")
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		os.Remove(tmpFile.Name())
+	})
+
+	_, err = tmpFile.WriteString(manifest)
+	require.NoError(t, err)
+	require.NoError(t, tmpFile.Close())
+
+	return tmpFile.Name()
+}
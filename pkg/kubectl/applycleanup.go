@@ -0,0 +1,111 @@
+package kubectl
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+var (
+	// How long the cleanup of ApplyWithCleanup may take to delete the applied objects
+	cleanupTimeout = time.Minute
+)
+
+/*
+ApplyWithCleanup applies the given files like ApplyManifests, and returns a function that deletes exactly the objects
+that kubectl reported as applied. The cleanup outlives the context, so it can be deferred or passed to t.Cleanup, and
+may be called more than once. When the apply fails, the cleanup deletes the objects that kubectl reported as applied
+before the failure.
+
+Example:
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	cleanup, err := ApplyWithCleanup(ctx, "/path/to/kubeconfig", &ApplyManifestsOptions{}, "/path/to/manifest.yaml")
+	defer cleanup()
+	if err != nil {
+		// Handle error
+	}
+*/
+func ApplyWithCleanup(ctx context.Context, kubeconfigPath string, opts *ApplyManifestsOptions, filePaths ...string) (func() error, error) {
+	result, applyErr := ApplyManifestsWithResult(ctx, kubeconfigPath, opts, filePaths...)
+	if result == nil || opts.DryRun != DryRunNone {
+		return func() error { return nil }, applyErr
+	}
+
+	objs, err := readManifests(filePaths, opts.Recursive)
+	if err != nil {
+		return func() error { return nil }, err
+	}
+
+	namespace, _, err := newFactory(kubeconfigPath).ToRawKubeConfigLoader().Namespace()
+	if err != nil {
+		return func() error { return nil }, fmt.Errorf("could not determine default namespace: %w", err)
+	}
+
+	applied := appliedObjects(objs, result, namespace)
+
+	// Objects that are already gone are ignored, so calling the cleanup again retries what was left behind
+	cleanup := func() error {
+		return deleteObjects(context.WithoutCancel(ctx), kubeconfigPath, applied)
+	}
+
+	return cleanup, applyErr
+}
+
+/*
+appliedObjects returns the objects that the result reports as applied without errors, matching them by their namespace
+as well, where objects without a namespace are in the default namespace. Pruned objects are left out, as they are no
+longer in the cluster.
+*/
+func appliedObjects(objs []*unstructured.Unstructured, result *ApplyResult, defaultNamespace string) []*unstructured.Unstructured {
+	type namespacedRef struct {
+		ObjectRef
+		namespace string
+	}
+
+	reported := map[namespacedRef]bool{}
+	for _, obj := range result.Objects {
+		if obj.Operation == OperationPruned || obj.DryRun || obj.Err != nil {
+			continue
+		}
+
+		reported[namespacedRef{obj.ObjectRef, obj.Namespace}] = true
+	}
+
+	applied := []*unstructured.Unstructured{}
+	for _, obj := range objs {
+		ref := objectRefOf(obj)
+
+		// Cluster-scoped objects have no namespace in the result, namespaced ones the default namespace when left out
+		if reported[namespacedRef{ref, obj.GetNamespace()}] || (obj.GetNamespace() == "" && reported[namespacedRef{ref, defaultNamespace}]) {
+			applied = append(applied, obj)
+		}
+	}
+
+	return applied
+}
+
+/*
+deleteObjects deletes the objects from the cluster, ignoring the objects that no longer exist.
+*/
+func deleteObjects(ctx context.Context, kubeconfigPath string, objs []*unstructured.Unstructured) error {
+	if len(objs) == 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, cleanupTimeout)
+	defer cancel()
+
+	manifestPath, err := writeManifests(objs)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(manifestPath)
+
+	return deleteFunc(ctx, kubeconfigPath, &deleteOptions{IgnoreNotFound: true}, manifestPath)
+}
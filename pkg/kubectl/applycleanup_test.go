@@ -0,0 +1,115 @@
+package kubectl
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Arneproductions/go-kube/internal/resources"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestAppliedObjects(t *testing.T) {
+	t.Run("appliedObjects_should_leave_out_pruned_failed_and_unreported_objects", func(t *testing.T) {
+		objs := mustDecodeManifests(t, configMapDataManifest("created", "a", "b")+"---\n"+
+			configMapDataManifest("failed", "a", "b")+"---\n"+
+			configMapDataManifest("unreported", "a", "b"))
+
+		result := &ApplyResult{}
+		result.add(parseApplyOutput("configmap/created created\nconfigmap/pruned pruned\n"))
+		result.Objects[0].Namespace = "default"
+		result.Objects = append(result.Objects, ObjectResult{
+			ObjectRef: ObjectRef{Kind: "configmap", Name: "failed"},
+			Namespace: "default",
+			Err:       assert.AnError,
+		})
+
+		applied := appliedObjects(objs, result, "default")
+
+		require.Len(t, applied, 1)
+		assert.Equal(t, "created", applied[0].GetName())
+	})
+
+	t.Run("appliedObjects_should_match_the_namespace", func(t *testing.T) {
+		objs := mustDecodeManifests(t, configMapDataManifest("config", "a", "b")+"---\n"+
+			namespacedConfigMapManifest("other", "config")+"---\n"+
+			namespacedConfigMapManifest("", "defaulted"))
+
+		result := &ApplyResult{Objects: []ObjectResult{
+			{ObjectRef: ObjectRef{Kind: "configmap", Name: "config"}, Namespace: "other", Operation: OperationCreated},
+			{ObjectRef: ObjectRef{Kind: "configmap", Name: "defaulted"}, Namespace: "team", Operation: OperationCreated},
+		}}
+
+		applied := appliedObjects(objs, result, "team")
+
+		require.Len(t, applied, 2)
+		assert.Equal(t, "other", applied[0].GetNamespace())
+		assert.Equal(t, "defaulted", applied[1].GetName())
+	})
+}
+
+func namespacedConfigMapManifest(namespace, name string) string {
+	return fmt.Sprintf(`
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: %s
+  namespace: %s
+`, name, namespace)
+}
+
+func TestApplyWithCleanup(t *testing.T) {
+	c := resources.NewEphemeralCluster()
+	require.NoError(t, c.Start())
+
+	t.Cleanup(func() {
+		require.NoError(t, c.Stop())
+	})
+
+	t.Run("ApplyWithCleanup_should_delete_the_applied_objects", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		first, second := uuid.New().String(), uuid.New().String()
+		manifest := writeTestManifest(t, configMapDataManifest(first, "foo", "bar")+"---\n"+configMapDataManifest(second, "foo", "bar"))
+
+		cleanup, err := ApplyWithCleanup(ctx, c.KubeConfigFilePath(), &ApplyManifestsOptions{}, manifest)
+		require.NoError(t, err)
+
+		for _, name := range []string{first, second} {
+			_, err := c.Client().CoreV1().ConfigMaps("default").Get(ctx, name, metav1.GetOptions{})
+			require.NoError(t, err)
+		}
+
+		require.NoError(t, cleanup())
+
+		for _, name := range []string{first, second} {
+			_, err := c.Client().CoreV1().ConfigMaps("default").Get(ctx, name, metav1.GetOptions{})
+			assert.True(t, apierrors.IsNotFound(err), "config map %s should be deleted", name)
+		}
+
+		assert.NoError(t, cleanup(), "the cleanup should be idempotent")
+	})
+
+	t.Run("ApplyWithCleanup_should_cleanup_after_the_context_is_done", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+
+		name := uuid.New().String()
+		cleanup, err := ApplyWithCleanup(ctx, c.KubeConfigFilePath(), &ApplyManifestsOptions{}, writeTestManifest(t, configMapDataManifest(name, "foo", "bar")))
+		cancel()
+		require.NoError(t, err)
+
+		require.NoError(t, cleanup())
+
+		ctx, cancel = context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		_, err = c.Client().CoreV1().ConfigMaps("default").Get(ctx, name, metav1.GetOptions{})
+		assert.True(t, apierrors.IsNotFound(err))
+	})
+}
@@ -0,0 +1,122 @@
+package kubectl
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+var (
+	// The magic bytes that every gzip stream starts with
+	gzipMagic = []byte{0x1f, 0x8b}
+)
+
+/*
+ApplyArchive applies the manifests in the given tar or tar.gz archive to the cluster that the kubeconfigPath points to
+with the given ApplyManifestsOptions. The manifests are applied in the order they appear in the archive.
+
+Example:
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	err := ApplyArchive(
+		ctx,
+		"/path/to/kubeconfig",
+		&ApplyManifestsOptions{
+		},
+		"/path/to/bundle.tar.gz",
+	)
+	if err != nil {
+		// Handle error
+	}
+*/
+func ApplyArchive(ctx context.Context, kubeconfigPath string, opts *ApplyManifestsOptions, archivePath string) error {
+	if opts == nil {
+		return fmt.Errorf("options cannot be nil")
+	}
+
+	archive, err := os.Open(archivePath)
+	if err != nil {
+		return fmt.Errorf("could not open archive %s: %w", archivePath, err)
+	}
+	defer archive.Close()
+
+	objs, err := readArchive(archive, archivePath)
+	if err != nil {
+		return err
+	}
+
+	if len(objs) == 0 {
+		return fmt.Errorf("no manifests found in archive %s", archivePath)
+	}
+
+	manifestPath, err := writeManifests(objs)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(manifestPath)
+
+	return ApplyManifests(ctx, kubeconfigPath, opts, manifestPath)
+}
+
+/*
+readArchive decodes the manifests of every manifest file in the tar stream, which may be gzip compressed.
+*/
+func readArchive(r io.Reader, source string) ([]*unstructured.Unstructured, error) {
+	reader := bufio.NewReader(r)
+
+	// We sniff the header to find out if the tar stream is compressed
+	header, err := reader.Peek(len(gzipMagic))
+	if err != nil && err != io.EOF {
+		return nil, fmt.Errorf("could not read archive %s: %w", source, err)
+	}
+
+	var tarStream io.Reader = reader
+	if bytes.Equal(header, gzipMagic) {
+		gzipReader, err := gzip.NewReader(reader)
+		if err != nil {
+			return nil, fmt.Errorf("could not decompress archive %s: %w", source, err)
+		}
+		defer gzipReader.Close()
+
+		tarStream = gzipReader
+	}
+
+	objs := []*unstructured.Unstructured{}
+	tarReader := tar.NewReader(tarStream)
+	for {
+		entry, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("could not read archive %s: %w", source, err)
+		}
+
+		if entry.Typeflag != tar.TypeReg || !isManifestFile(entry.Name) {
+			continue
+		}
+
+		data, err := io.ReadAll(tarReader)
+		if err != nil {
+			return nil, fmt.Errorf("could not read %s in archive %s: %w", entry.Name, source, err)
+		}
+
+		entryObjs, err := decodeManifests(data, fmt.Sprintf("%s:%s", source, entry.Name))
+		if err != nil {
+			return nil, err
+		}
+
+		objs = append(objs, entryObjs...)
+	}
+
+	return objs, nil
+}
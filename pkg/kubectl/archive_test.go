@@ -0,0 +1,147 @@
+package kubectl
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/Arneproductions/go-kube/internal/resources"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestApplyArchive(t *testing.T) {
+	c := resources.NewEphemeralCluster()
+	require.NoError(t, c.Start())
+
+	t.Cleanup(func() {
+		require.NoError(t, c.Stop())
+	})
+
+	t.Run("ApplyArchive_can_apply_tar_gz_archive", func(t *testing.T) {
+		namespaces := []string{
+			fmt.Sprintf("test-ns-%s", uuid.New().String()),
+			fmt.Sprintf("test-ns-%s", uuid.New().String()),
+		}
+
+		archive := genArchive(t, true, map[string]string{
+			"first.yaml":  namespaceManifest(namespaces[0]),
+			"second.yaml": namespaceManifest(namespaces[1]),
+		})
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		err := ApplyArchive(ctx, c.KubeConfigFilePath(), &ApplyManifestsOptions{}, archive)
+		require.NoError(t, err)
+
+		for _, ns := range namespaces {
+			_, err := c.Client().CoreV1().Namespaces().Get(ctx, ns, metav1.GetOptions{})
+			assert.NoError(t, err)
+		}
+
+		t.Cleanup(func() {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			defer cancel()
+
+			for _, ns := range namespaces {
+				_ = c.Client().CoreV1().Namespaces().Delete(ctx, ns, metav1.DeleteOptions{})
+			}
+		})
+	})
+}
+
+func TestReadArchive(t *testing.T) {
+	for _, compressed := range []bool{false, true} {
+		t.Run(fmt.Sprintf("readArchive_keeps_entry_order_compressed_%t", compressed), func(t *testing.T) {
+			archive := genArchive(t, compressed, map[string]string{
+				"a.yaml":    namespaceManifest("first"),
+				"b.yaml":    namespaceManifest("second"),
+				"README.md": "not a manifest",
+			})
+
+			f, err := os.Open(archive)
+			require.NoError(t, err)
+			defer f.Close()
+
+			objs, err := readArchive(f, archive)
+			require.NoError(t, err)
+			require.Len(t, objs, 2)
+
+			assert.Equal(t, "first", objs[0].GetName())
+			assert.Equal(t, "second", objs[1].GetName())
+		})
+	}
+}
+
+func namespaceManifest(name string) string {
+	return fmt.Sprintf(`
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: %s
+`, name)
+}
+
+// genArchive writes the files, sorted by name, into a temporary tar archive and returns the path to it
+func genArchive(t *testing.T, compressed bool, files map[string]string) string {
+	buf := &bytes.Buffer{}
+
+	var out io.Writer = buf
+	var gzipWriter *gzip.Writer
+	if compressed {
+		gzipWriter = gzip.NewWriter(buf)
+		out = gzipWriter
+	}
+
+	tarWriter := tar.NewWriter(out)
+
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		content := files[name]
+
+		err := tarWriter.WriteHeader(&tar.Header{
+			Name:     name,
+			Mode:     0644,
+			Size:     int64(len(content)),
+			Typeflag: tar.TypeReg,
+		})
+		require.NoError(t, err)
+
+		_, err = tarWriter.Write([]byte(content))
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, tarWriter.Close())
+	if gzipWriter != nil {
+		require.NoError(t, gzipWriter.Close())
+	}
+
+	tmpFile, err := os.CreateTemp("", "test-archive-*.tar")
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		os.Remove(tmpFile.Name())
+	})
+
+	_, err = tmpFile.Write(buf.Bytes())
+	require.NoError(t, err)
+	require.NoError(t, tmpFile.Close())
+
+	return tmpFile.Name()
+}
@@ -0,0 +1,353 @@
+package kubectl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/describe"
+	"sigs.k8s.io/kind/pkg/cluster"
+	"sigs.k8s.io/kind/pkg/log"
+)
+
+const (
+	// kind names the contexts of its clusters after the cluster, prefixed with kind-
+	kindContextPrefix = "kind-"
+)
+
+/*
+CollectSupportBundle writes everything needed to debug a failed test against the cluster that the kubeconfigPath
+points to into destDir, e.g. for CI to archive it. The bundle has the following layout:
+
+	nodes.txt                                   kubectl describe of every node
+	namespaces/NAMESPACE/events.txt             the events of the namespace, the most recent first
+	namespaces/NAMESPACE/logs/POD/CONTAINER.log the logs of every container, and CONTAINER.previous.log of restarted ones
+	namespaces/NAMESPACE/describe/KIND-NAME.txt kubectl describe of every pod and workload that is not ready
+	kind/                                       the logs of the node containers, when the cluster is a kind cluster
+
+The bundle is collected as far as possible, and the errors of the parts that could not be collected are returned
+together.
+
+Example:
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	err := CollectSupportBundle(ctx, "/path/to/kubeconfig", "/path/to/artifacts")
+	if err != nil {
+		// Handle error
+	}
+*/
+func CollectSupportBundle(ctx context.Context, kubeconfigPath string, destDir string) error {
+	if kubeconfigPath == "" {
+		return fmt.Errorf("kubeconfig path cannot be empty")
+	}
+
+	if destDir == "" {
+		return fmt.Errorf("destination directory cannot be empty")
+	}
+
+	err := os.MkdirAll(destDir, 0o755)
+	if err != nil {
+		return fmt.Errorf("could not create directory %s: %w", destDir, err)
+	}
+
+	f := newFactory(kubeconfigPath)
+
+	restConfig, err := f.ToRESTConfig()
+	if err != nil {
+		return fmt.Errorf("could not create rest config: %w", err)
+	}
+
+	clientset, err := f.KubernetesClientSet()
+	if err != nil {
+		return fmt.Errorf("could not create clientset: %w", err)
+	}
+
+	errs := []error{}
+
+	err = writeDescriptions(restConfig, schema.GroupKind{Kind: "Node"}, "", nodeNames(ctx, clientset), filepath.Join(destDir, "nodes.txt"))
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	namespaces, err := clientset.CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		errs = append(errs, fmt.Errorf("could not list namespaces: %w", err))
+		namespaces = &corev1.NamespaceList{}
+	}
+
+	for _, ns := range namespaces.Items {
+		errs = append(errs, collectNamespace(ctx, kubeconfigPath, f, restConfig, clientset, ns.Name, filepath.Join(destDir, "namespaces", ns.Name))...)
+	}
+
+	err = collectKindLogs(f, filepath.Join(destDir, "kind"))
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("could not collect the whole support bundle: %w", errors.Join(errs...))
+	}
+
+	return nil
+}
+
+/*
+collectNamespace writes the events, logs and descriptions of the objects that are not ready of the namespace into dir.
+*/
+func collectNamespace(ctx context.Context, kubeconfigPath string, f util.Factory, restConfig *rest.Config, clientset kubernetes.Interface, namespace string, dir string) []error {
+	errs := []error{}
+
+	err := os.MkdirAll(dir, 0o755)
+	if err != nil {
+		return []error{fmt.Errorf("could not create directory %s: %w", dir, err)}
+	}
+
+	events, err := NamespaceEvents(ctx, kubeconfigPath, namespace, &EventsOptions{})
+	if err != nil {
+		errs = append(errs, err)
+	} else {
+		err = writeEvents(events, filepath.Join(dir, "events.txt"))
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	pods, err := clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return append(errs, fmt.Errorf("could not list pods in namespace %s: %w", namespace, err))
+	}
+
+	for _, pod := range pods.Items {
+		errs = append(errs, collectPodLogs(ctx, clientset, pod, filepath.Join(dir, "logs", pod.Name))...)
+	}
+
+	notReady, err := notReadyObjects(ctx, f, namespace)
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	for gk, names := range notReady {
+		for _, name := range names {
+			path := filepath.Join(dir, "describe", fmt.Sprintf("%s-%s.txt", strings.ToLower(gk.Kind), name))
+
+			err := writeDescriptions(restConfig, gk, namespace, []string{name}, path)
+			if err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	return errs
+}
+
+/*
+collectPodLogs writes the logs of every container of the pod into dir, and the logs of the previous run of the
+containers that restarted. Containers that have not run have no logs to write.
+*/
+func collectPodLogs(ctx context.Context, clientset kubernetes.Interface, pod corev1.Pod, dir string) []error {
+	errs := []error{}
+
+	statuses := append(append([]corev1.ContainerStatus{}, pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...)
+
+	for _, status := range statuses {
+		if status.State.Running != nil || status.State.Terminated != nil {
+			err := writePodLogs(ctx, clientset, pod, status.Name, false, filepath.Join(dir, status.Name+".log"))
+			if err != nil {
+				errs = append(errs, err)
+			}
+		}
+
+		if status.LastTerminationState.Terminated != nil {
+			err := writePodLogs(ctx, clientset, pod, status.Name, true, filepath.Join(dir, status.Name+".previous.log"))
+			if err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	return errs
+}
+
+func writePodLogs(ctx context.Context, clientset kubernetes.Interface, pod corev1.Pod, container string, previous bool, path string) error {
+	stream, err := clientset.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, &corev1.PodLogOptions{
+		Container: container,
+		Previous:  previous,
+	}).Stream(ctx)
+	if err != nil {
+		return fmt.Errorf("could not stream logs of pod %s/%s container %s: %w", pod.Namespace, pod.Name, container, err)
+	}
+	defer stream.Close()
+
+	err = os.MkdirAll(filepath.Dir(path), 0o755)
+	if err != nil {
+		return fmt.Errorf("could not create directory %s: %w", filepath.Dir(path), err)
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("could not create file %s: %w", path, err)
+	}
+	defer file.Close()
+
+	_, err = io.Copy(file, stream)
+	if err != nil {
+		return fmt.Errorf("could not write logs of pod %s/%s container %s: %w", pod.Namespace, pod.Name, container, err)
+	}
+
+	return nil
+}
+
+/*
+notReadyObjects returns the names of the pods and workloads of the namespace that are not ready, by their kind. Pods
+that ran to completion are ready.
+*/
+func notReadyObjects(ctx context.Context, f util.Factory, namespace string) (map[schema.GroupKind][]string, error) {
+	dynamicClient, err := f.DynamicClient()
+	if err != nil {
+		return nil, fmt.Errorf("could not create dynamic client: %w", err)
+	}
+
+	mapper, err := f.ToRESTMapper()
+	if err != nil {
+		return nil, fmt.Errorf("could not create rest mapper: %w", err)
+	}
+
+	kinds := []schema.GroupKind{}
+	for gk := range builtinReadinessChecks {
+		kinds = append(kinds, gk)
+	}
+
+	notReady := map[schema.GroupKind][]string{}
+	errs := []error{}
+
+	for _, gk := range kinds {
+		mapping, err := mapper.RESTMapping(gk)
+		if err != nil || mapping.Scope.Name() != meta.RESTScopeNameNamespace {
+			continue
+		}
+
+		list, err := dynamicClient.Resource(mapping.Resource).Namespace(namespace).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("could not list %s in namespace %s: %w", mapping.Resource.Resource, namespace, err))
+			continue
+		}
+
+		check := readinessCheck(nil, mapping.GroupVersionKind, true)
+
+		for _, obj := range list.Items {
+			if !check(&obj) {
+				notReady[gk] = append(notReady[gk], obj.GetName())
+			}
+		}
+	}
+
+	return notReady, errors.Join(errs...)
+}
+
+/*
+writeDescriptions writes kubectl describe of the objects of the kind into the file at path.
+*/
+func writeDescriptions(restConfig *rest.Config, gk schema.GroupKind, namespace string, names []string, path string) error {
+	describer, ok := describe.DescriberFor(gk, restConfig)
+	if !ok {
+		return fmt.Errorf("could not describe %s", gk.String())
+	}
+
+	descriptions := []string{}
+	for _, name := range names {
+		description, err := describer.Describe(namespace, name, describe.DescriberSettings{ShowEvents: true})
+		if err != nil {
+			return fmt.Errorf("could not describe %s %s: %w", gk.String(), name, err)
+		}
+
+		descriptions = append(descriptions, description)
+	}
+
+	err := os.MkdirAll(filepath.Dir(path), 0o755)
+	if err != nil {
+		return fmt.Errorf("could not create directory %s: %w", filepath.Dir(path), err)
+	}
+
+	err = os.WriteFile(path, []byte(strings.Join(descriptions, "\n\n")), 0o644)
+	if err != nil {
+		return fmt.Errorf("could not write file %s: %w", path, err)
+	}
+
+	return nil
+}
+
+func nodeNames(ctx context.Context, clientset kubernetes.Interface) []string {
+	names := []string{}
+
+	nodes, err := clientset.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return names
+	}
+
+	for _, node := range nodes.Items {
+		names = append(names, node.Name)
+	}
+
+	return names
+}
+
+func writeEvents(events []Event, path string) error {
+	lines := []string{}
+	for _, event := range events {
+		lines = append(lines, fmt.Sprintf(
+			"%s\t%s\t%s\t%s/%s\t%s (x%d from %s)",
+			event.LastSeen.Format(time.RFC3339),
+			event.Type,
+			event.Reason,
+			event.Object.Kind,
+			event.Object.Name,
+			event.Message,
+			event.Count,
+			event.Source,
+		))
+	}
+
+	err := os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0o644)
+	if err != nil {
+		return fmt.Errorf("could not write file %s: %w", path, err)
+	}
+
+	return nil
+}
+
+/*
+collectKindLogs writes the logs of the node containers into dir when the current context of the kubeconfig is the
+context of a kind cluster. Other clusters have no kind logs to collect.
+*/
+func collectKindLogs(f util.Factory, dir string) error {
+	rawConfig, err := f.ToRawKubeConfigLoader().RawConfig()
+	if err != nil {
+		return fmt.Errorf("could not load kubeconfig: %w", err)
+	}
+
+	clusterName, ok := strings.CutPrefix(rawConfig.CurrentContext, kindContextPrefix)
+	if !ok {
+		return nil
+	}
+
+	err = cluster.NewProvider(cluster.ProviderWithLogger(log.NoopLogger{})).CollectLogs(clusterName, dir)
+	if err != nil {
+		return fmt.Errorf("could not collect logs of kind cluster %s: %w", clusterName, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,100 @@
+package kubectl
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Arneproductions/go-kube/internal/resources"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+func TestWriteEvents(t *testing.T) {
+	t.Run("writeEvents_should_write_a_line_per_event", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "events.txt")
+		lastSeen := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+		err := writeEvents([]Event{{
+			Object:   ObjectRef{Kind: "pod", Name: "web"},
+			Type:     corev1.EventTypeWarning,
+			Reason:   "BackOff",
+			Message:  "Back-off restarting failed container",
+			Source:   "kubelet",
+			Count:    3,
+			LastSeen: lastSeen,
+		}}, path)
+		require.NoError(t, err)
+
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, "2024-01-02T03:04:05Z\tWarning\tBackOff\tpod/web\tBack-off restarting failed container (x3 from kubelet)\n", string(data))
+	})
+}
+
+func TestCollectSupportBundle(t *testing.T) {
+	c := resources.NewEphemeralCluster()
+	require.NoError(t, c.Start())
+
+	t.Cleanup(func() {
+		require.NoError(t, c.Stop())
+	})
+
+	t.Run("CollectSupportBundle_should_collect_the_logs_and_events_of_failing_pods", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Minute)
+		defer cancel()
+
+		name := fmt.Sprintf("test-pod-%s", uuid.New().String())
+
+		err := ApplyManifests(ctx, c.KubeConfigFilePath(), &ApplyManifestsOptions{}, writeTestManifest(t, fmt.Sprintf(`apiVersion: v1
+kind: Pod
+metadata:
+  name: %s
+  namespace: default
+spec:
+  restartPolicy: Never
+  containers:
+  - name: failing
+    image: busybox:1.36
+    command: ["sh", "-c", "echo boom from $HOSTNAME; exit 1"]
+`, name)))
+		require.NoError(t, err)
+
+		err = wait.PollUntilContextCancel(ctx, time.Second, true, func(ctx context.Context) (bool, error) {
+			pod, err := c.Client().CoreV1().Pods("default").Get(ctx, name, metav1.GetOptions{})
+			if err != nil {
+				return false, err
+			}
+
+			return pod.Status.Phase == corev1.PodFailed, nil
+		})
+		require.NoError(t, err)
+
+		dir := t.TempDir()
+
+		err = CollectSupportBundle(ctx, c.KubeConfigFilePath(), dir)
+		require.NoError(t, err)
+
+		logs, err := os.ReadFile(filepath.Join(dir, "namespaces", "default", "logs", name, "failing.log"))
+		require.NoError(t, err)
+		assert.Contains(t, string(logs), "boom from "+name)
+
+		events, err := os.ReadFile(filepath.Join(dir, "namespaces", "default", "events.txt"))
+		require.NoError(t, err)
+		assert.Contains(t, string(events), "pod/"+name)
+
+		description, err := os.ReadFile(filepath.Join(dir, "namespaces", "default", "describe", "pod-"+name+".txt"))
+		require.NoError(t, err)
+		assert.Contains(t, string(description), "Failed")
+
+		assert.FileExists(t, filepath.Join(dir, "nodes.txt"))
+		assert.DirExists(t, filepath.Join(dir, "kind"))
+	})
+}
@@ -0,0 +1,206 @@
+package kubectl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/rest"
+	"k8s.io/kubectl/pkg/cmd/util"
+)
+
+var (
+	// The kinds that other objects depend on, which are applied before any other object
+	dependencyKinds = []schema.GroupKind{
+		{Kind: "Namespace"},
+		{Group: "apiextensions.k8s.io", Kind: "CustomResourceDefinition"},
+	}
+)
+
+/*
+applyConcurrently server-side applies the objects of the given files through the dynamic client, up to the concurrency
+of the options at once. Namespaces and CRDs are applied first, and the CRDs are established before the other objects
+are applied. Every object is attempted, even when some of them fail.
+*/
+func applyConcurrently(ctx context.Context, kubeconfigPath string, opts *ApplyManifestsOptions, filePaths ...string) (*ApplyResult, error) {
+	if err := operations.begin(); err != nil {
+		return nil, err
+	}
+	defer operations.end()
+
+	if kubeconfigPath == "" {
+		return nil, fmt.Errorf("kubeconfig path cannot be empty")
+	}
+
+	if opts.Prune {
+		return nil, fmt.Errorf("pruning is not supported when applying concurrently")
+	}
+
+	// The objects are applied through the dynamic client, which only dry-runs on the API server
+	if opts.DryRun == DryRunClient {
+		return nil, fmt.Errorf("client-side dry-run is not supported when applying concurrently")
+	}
+
+	objs, err := readManifests(filePaths, opts.Recursive)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ApplyResult{}
+	errs := []error{}
+
+	for i, wave := range dependencyWaves(objs) {
+		// The factory is created for every wave, such that the kinds of the CRDs of the previous wave are known
+		waveResults, waveErrs := applyWave(ctx, newConcurrentFactory(kubeconfigPath), opts, wave)
+		result.add(waveResults)
+		errs = append(errs, waveErrs...)
+
+		if i == 0 && opts.DryRun == DryRunNone {
+			err := waitForCRDsEstablished(ctx, kubeconfigPath, wave)
+			if err != nil {
+				errs = append(errs, err)
+			}
+		}
+
+		// The other objects are likely to depend on the namespaces and CRDs that failed
+		if len(errs) > 0 {
+			break
+		}
+	}
+
+	if len(errs) > 0 {
+		return result, fmt.Errorf("could not apply %d of %d objects: %w", len(errs), len(objs), errors.Join(errs...))
+	}
+
+	return result, nil
+}
+
+/*
+newConcurrentFactory creates a factory without the client-side rate limit, as the concurrency bounds the requests instead.
+*/
+func newConcurrentFactory(kubeconfigPath string) util.Factory {
+	config := newConfigFlags(kubeconfigPath, "")
+	config.WrapConfigFn = func(c *rest.Config) *rest.Config {
+		c.QPS = -1
+		return c
+	}
+
+	return util.NewFactory(config)
+}
+
+/*
+dependencyWaves splits the objects into the namespaces and CRDs, and every other object, keeping the order of the objects.
+*/
+func dependencyWaves(objs []*unstructured.Unstructured) [][]*unstructured.Unstructured {
+	dependencies := []*unstructured.Unstructured{}
+	others := []*unstructured.Unstructured{}
+
+	for _, obj := range objs {
+		if isDependencyKind(obj.GroupVersionKind().GroupKind()) {
+			dependencies = append(dependencies, obj)
+		} else {
+			others = append(others, obj)
+		}
+	}
+
+	return [][]*unstructured.Unstructured{dependencies, others}
+}
+
+func isDependencyKind(gk schema.GroupKind) bool {
+	for _, kind := range dependencyKinds {
+		if kind == gk {
+			return true
+		}
+	}
+
+	return false
+}
+
+/*
+applyWave server-side applies the objects up to the concurrency of the options at once, and returns the outcome of every
+object in the order of the objects, and the errors of the objects that failed. The OnObjectApplied callback of the
+options is called as soon as an object is done.
+*/
+func applyWave(ctx context.Context, f util.Factory, opts *ApplyManifestsOptions, objs []*unstructured.Unstructured) ([]ObjectResult, []error) {
+	if len(objs) == 0 {
+		return nil, nil
+	}
+
+	dynamicClient, err := f.DynamicClient()
+	if err != nil {
+		return nil, []error{fmt.Errorf("could not create dynamic client: %w", err)}
+	}
+
+	mapper, err := f.ToRESTMapper()
+	if err != nil {
+		return nil, []error{fmt.Errorf("could not create rest mapper: %w", err)}
+	}
+
+	namespace, _, err := f.ToRawKubeConfigLoader().Namespace()
+	if err != nil {
+		return nil, []error{fmt.Errorf("could not determine default namespace: %w", err)}
+	}
+
+	applyOpts := metav1.ApplyOptions{
+		FieldManager: fieldManagerOf(opts),
+		Force:        opts.ConflictPolicy == ConflictPolicyForce,
+	}
+	if opts.DryRun != DryRunNone {
+		applyOpts.DryRun = []string{metav1.DryRunAll}
+	}
+
+	results := make([]ObjectResult, len(objs))
+	wg := &sync.WaitGroup{}
+	sem := make(chan struct{}, opts.Concurrency)
+
+	for i, obj := range objs {
+		wg.Add(1)
+		sem <- struct{}{}
+
+		go func(i int, obj *unstructured.Unstructured) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			results[i] = ObjectResult{
+				ObjectRef: objectRefOf(obj),
+				Operation: OperationServerSideApplied,
+				DryRun:    opts.DryRun != DryRunNone,
+			}
+
+			start := time.Now()
+			defer func() {
+				results[i].Duration = time.Since(start)
+
+				if opts.OnObjectApplied != nil {
+					opts.OnObjectApplied(results[i].ObjectRef, results[i].Duration, results[i].Err)
+				}
+			}()
+
+			client, err := objectClient(dynamicClient, mapper, namespace, obj)
+			if err != nil {
+				results[i].Err = err
+				return
+			}
+
+			_, err = client.Apply(ctx, obj.GetName(), obj, applyOpts)
+			if err != nil {
+				results[i].Err = fmt.Errorf("could not apply %s %s: %w", obj.GetKind(), obj.GetName(), err)
+			}
+		}(i, obj)
+	}
+	wg.Wait()
+
+	errs := []error{}
+	for _, objResult := range results {
+		if objResult.Err != nil {
+			errs = append(errs, objResult.Err)
+		}
+	}
+
+	return results, errs
+}
@@ -0,0 +1,132 @@
+package kubectl
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Arneproductions/go-kube/internal/resources"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// labelledConfigMapsManifest returns count config maps with the label, named after the prefix
+func labelledConfigMapsManifest(prefix, label string, count int) string {
+	manifests := []string{}
+	for i := 0; i < count; i++ {
+		manifests = append(manifests, labelledConfigMapManifest(fmt.Sprintf("%s-%d", prefix, i), label))
+	}
+
+	return strings.Join(manifests, "---")
+}
+
+func TestDependencyWaves(t *testing.T) {
+	t.Run("dependencyWaves_should_put_namespaces_and_CRDs_first", func(t *testing.T) {
+		objs := mustDecodeManifests(t, configMapDataManifest("foo", "foo", "bar")+"---"+namespaceManifest("ns")+"---"+widgetCRDManifest)
+
+		waves := dependencyWaves(objs)
+		require.Len(t, waves, 2)
+
+		require.Len(t, waves[0], 2)
+		assert.Equal(t, "Namespace", waves[0][0].GetKind())
+		assert.Equal(t, "CustomResourceDefinition", waves[0][1].GetKind())
+
+		require.Len(t, waves[1], 1)
+		assert.Equal(t, "ConfigMap", waves[1][0].GetKind())
+	})
+
+	t.Run("applyConcurrently_should_reject_client_side_dry_runs", func(t *testing.T) {
+		_, err := applyConcurrently(context.Background(), "/path/to/kubeconfig", &ApplyManifestsOptions{DryRun: DryRunClient, Concurrency: 2}, "manifest.yaml")
+		assert.ErrorContains(t, err, "client-side dry-run is not supported")
+	})
+}
+
+func TestConcurrency(t *testing.T) {
+	c := resources.NewEphemeralCluster()
+	require.NoError(t, c.Start())
+
+	t.Cleanup(func() {
+		require.NoError(t, c.Stop())
+	})
+
+	t.Run("ApplyManifests_should_apply_objects_concurrently", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+		defer cancel()
+
+		timeApply := func(concurrency int) time.Duration {
+			label := uuid.New().String()
+			manifest := writeTestManifest(t, labelledConfigMapsManifest(fmt.Sprintf("test-cm-%s", label), label, 50))
+
+			t.Cleanup(func() {
+				ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+				defer cancel()
+
+				_ = c.Client().CoreV1().ConfigMaps("default").DeleteCollection(ctx, metav1.DeleteOptions{}, metav1.ListOptions{LabelSelector: fmt.Sprintf("test=%s", label)})
+			})
+
+			start := time.Now()
+			result, err := ApplyManifestsWithResult(ctx, c.KubeConfigFilePath(), &ApplyManifestsOptions{Concurrency: concurrency}, manifest)
+			elapsed := time.Since(start)
+			require.NoError(t, err)
+			assert.Len(t, result.Objects, 50)
+
+			cms, err := c.Client().CoreV1().ConfigMaps("default").List(ctx, metav1.ListOptions{LabelSelector: fmt.Sprintf("test=%s", label)})
+			require.NoError(t, err)
+			assert.Len(t, cms.Items, 50)
+
+			return elapsed
+		}
+
+		serial := timeApply(1)
+		concurrent := timeApply(10)
+
+		t.Logf("serial apply took %s, concurrent apply took %s", serial, concurrent)
+		assert.Less(t, concurrent, serial)
+	})
+
+	t.Run("ApplyManifests_should_report_every_object_to_OnObjectApplied", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		label := uuid.New().String()
+		prefix := fmt.Sprintf("test-cm-%s", label)
+		manifest := writeTestManifest(t, labelledConfigMapsManifest(prefix, label, 10))
+
+		t.Cleanup(func() {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			defer cancel()
+
+			_ = c.Client().CoreV1().ConfigMaps("default").DeleteCollection(ctx, metav1.DeleteOptions{}, metav1.ListOptions{LabelSelector: fmt.Sprintf("test=%s", label)})
+		})
+
+		mu := sync.Mutex{}
+		applied := map[string]int{}
+
+		start := time.Now()
+		_, err := ApplyManifestsWithResult(ctx, c.KubeConfigFilePath(), &ApplyManifestsOptions{
+			Concurrency: 4,
+			OnObjectApplied: func(ref ObjectRef, d time.Duration, err error) {
+				mu.Lock()
+				defer mu.Unlock()
+
+				assert.NoError(t, err, ref.Name)
+				assert.Greater(t, d, time.Duration(0), ref.Name)
+				assert.LessOrEqual(t, d, time.Since(start), ref.Name)
+
+				applied[ref.Name]++
+			},
+		}, manifest)
+		require.NoError(t, err)
+
+		require.Len(t, applied, 10)
+		for i := 0; i < 10; i++ {
+			assert.Equal(t, 1, applied[fmt.Sprintf("%s-%d", prefix, i)])
+		}
+	})
+}
@@ -0,0 +1,118 @@
+package kubectl
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/util/jsonpath"
+)
+
+/*
+WaitForCondition waits until the JSONPath evaluates to the expected value on the object in the cluster that the
+kubeconfigPath points to, or the context is done. The JSONPath is written like for kubectl wait --for=jsonpath,
+e.g. {.status.phase} or .status.phase. The namespace is left empty for cluster scoped resources. An object that does
+not exist yet is waited for like a value that does not match.
+
+Example:
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	err := WaitForCondition(
+		ctx,
+		"/path/to/kubeconfig",
+		schema.GroupVersionResource{Group: "cert-manager.io", Version: "v1", Resource: "certificates"},
+		"default",
+		"my-certificate",
+		`{.status.conditions[?(@.type=="Ready")].status}`,
+		"True",
+	)
+	if err != nil {
+		// Handle error
+	}
+*/
+func WaitForCondition(ctx context.Context, kubeconfigPath string, gvr schema.GroupVersionResource, namespace, name, jsonPath, expected string) error {
+	if kubeconfigPath == "" {
+		return fmt.Errorf("kubeconfig path cannot be empty")
+	}
+
+	if name == "" {
+		return fmt.Errorf("name cannot be empty")
+	}
+
+	parser, err := parseJSONPath(jsonPath)
+	if err != nil {
+		return err
+	}
+
+	dynamicClient, err := newFactory(kubeconfigPath).DynamicClient()
+	if err != nil {
+		return fmt.Errorf("could not create dynamic client: %w", err)
+	}
+
+	client := dynamicClient.Resource(gvr).Namespace(namespace)
+	actual, found := "", false
+
+	err = wait.PollUntilContextCancel(ctx, time.Second, true, func(ctx context.Context) (bool, error) {
+		obj, err := client.Get(ctx, name, metav1.GetOptions{})
+		found = !apierrors.IsNotFound(err)
+		if !found {
+			// The object may not have been created yet
+			return false, nil
+		}
+		if err != nil {
+			return false, fmt.Errorf("could not get %s %s: %w", gvr.Resource, name, err)
+		}
+
+		results, err := parser.FindResults(obj.Object)
+		if err != nil {
+			return false, fmt.Errorf("could not evaluate %s on %s %s: %w", jsonPath, gvr.Resource, name, err)
+		}
+
+		// Missing fields have no results, and are waited on like any other value
+		if len(results) == 0 || len(results[0]) == 0 {
+			actual = ""
+			return false, nil
+		}
+
+		actual = fmt.Sprint(results[0][0].Interface())
+
+		return actual == expected, nil
+	})
+	if err != nil && !found {
+		return fmt.Errorf("%s %s was not found, want %s to be %q: %w", gvr.Resource, name, jsonPath, expected, err)
+	}
+	if err != nil {
+		return fmt.Errorf("%s of %s %s is %q, want %q: %w", jsonPath, gvr.Resource, name, actual, expected, err)
+	}
+
+	return nil
+}
+
+/*
+parseJSONPath parses the JSONPath, adding the surrounding braces when they are left out.
+*/
+func parseJSONPath(jsonPath string) (*jsonpath.JSONPath, error) {
+	if jsonPath == "" {
+		return nil, fmt.Errorf("jsonpath cannot be empty")
+	}
+
+	if !strings.HasPrefix(jsonPath, "{") {
+		jsonPath = "{" + jsonPath + "}"
+	}
+
+	parser := jsonpath.New("condition").AllowMissingKeys(true)
+
+	err := parser.Parse(jsonPath)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse jsonpath %s: %w", jsonPath, err)
+	}
+
+	return parser, nil
+}
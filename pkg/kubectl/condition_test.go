@@ -0,0 +1,151 @@
+package kubectl
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Arneproductions/go-kube/internal/resources"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestWaitForCondition(t *testing.T) {
+	c := resources.NewEphemeralCluster()
+	require.NoError(t, c.Start())
+
+	t.Cleanup(func() {
+		require.NoError(t, c.Stop())
+	})
+
+	configMaps := schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+
+	t.Run("WaitForCondition_should_return_when_jsonpath_matches", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		name := fmt.Sprintf("test-cm-%s", uuid.New().String())
+
+		_, err := c.Client().CoreV1().ConfigMaps("default").Create(ctx, &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Data:       map[string]string{"status": "pending"},
+		}, metav1.CreateOptions{})
+		require.NoError(t, err)
+
+		t.Cleanup(func() {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			defer cancel()
+
+			_ = c.Client().CoreV1().ConfigMaps("default").Delete(ctx, name, metav1.DeleteOptions{})
+		})
+
+		// The status is patched a while after we start waiting
+		patchErr := make(chan error, 1)
+		go func() {
+			time.Sleep(3 * time.Second)
+
+			_, err := c.Client().CoreV1().ConfigMaps("default").Patch(ctx, name, types.MergePatchType, []byte(`{"data":{"status":"done"}}`), metav1.PatchOptions{})
+			patchErr <- err
+		}()
+
+		start := time.Now()
+		err = WaitForCondition(ctx, c.KubeConfigFilePath(), configMaps, "default", name, ".data.status", "done")
+		require.NoError(t, err)
+		require.NoError(t, <-patchErr)
+
+		assert.GreaterOrEqual(t, time.Since(start), 3*time.Second)
+	})
+
+	t.Run("WaitForCondition_should_wait_for_objects_that_do_not_exist_yet", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		name := fmt.Sprintf("test-cm-%s", uuid.New().String())
+
+		t.Cleanup(func() {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			defer cancel()
+
+			_ = c.Client().CoreV1().ConfigMaps("default").Delete(ctx, name, metav1.DeleteOptions{})
+		})
+
+		// The config map is created a while after we start waiting
+		createErr := make(chan error, 1)
+		go func() {
+			time.Sleep(3 * time.Second)
+
+			_, err := c.Client().CoreV1().ConfigMaps("default").Create(ctx, &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: name},
+				Data:       map[string]string{"status": "done"},
+			}, metav1.CreateOptions{})
+			createErr <- err
+		}()
+
+		err := WaitForCondition(ctx, c.KubeConfigFilePath(), configMaps, "default", name, ".data.status", "done")
+		require.NoError(t, err)
+		require.NoError(t, <-createErr)
+	})
+
+	t.Run("WaitForCondition_should_report_missing_objects_on_timeout", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+		defer cancel()
+
+		err := WaitForCondition(ctx, c.KubeConfigFilePath(), configMaps, "default", "missing", ".data.status", "done")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "was not found")
+	})
+
+	t.Run("WaitForCondition_should_report_the_actual_value_on_timeout", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		name := fmt.Sprintf("test-cm-%s", uuid.New().String())
+
+		_, err := c.Client().CoreV1().ConfigMaps("default").Create(ctx, &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Data:       map[string]string{"status": "pending"},
+		}, metav1.CreateOptions{})
+		require.NoError(t, err)
+
+		t.Cleanup(func() {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			defer cancel()
+
+			_ = c.Client().CoreV1().ConfigMaps("default").Delete(ctx, name, metav1.DeleteOptions{})
+		})
+
+		waitCtx, waitCancel := context.WithTimeout(ctx, 3*time.Second)
+		defer waitCancel()
+
+		err = WaitForCondition(waitCtx, c.KubeConfigFilePath(), configMaps, "default", name, "{.data.status}", "done")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `is "pending", want "done"`)
+	})
+}
+
+func TestParseJSONPath(t *testing.T) {
+	t.Run("parseJSONPath_should_accept_paths_with_and_without_braces", func(t *testing.T) {
+		obj := map[string]interface{}{"status": map[string]interface{}{"phase": "Running"}}
+
+		for _, path := range []string{".status.phase", "{.status.phase}"} {
+			parser, err := parseJSONPath(path)
+			require.NoError(t, err)
+
+			results, err := parser.FindResults(obj)
+			require.NoError(t, err)
+			assert.Equal(t, "Running", results[0][0].Interface())
+		}
+	})
+
+	t.Run("parseJSONPath_should_reject_invalid_paths", func(t *testing.T) {
+		_, err := parseJSONPath("{.status[}")
+		assert.Error(t, err)
+	})
+}
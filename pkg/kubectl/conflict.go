@@ -0,0 +1,187 @@
+package kubectl
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/structured-merge-diff/v4/fieldpath"
+	"sigs.k8s.io/structured-merge-diff/v4/value"
+)
+
+/*
+isConflictError reports whether the error is caused by a server-side apply conflicting with other field managers.
+*/
+func isConflictError(err error) bool {
+	return err != nil && strings.Contains(err.Error(), "Apply failed with") && strings.Contains(err.Error(), "conflict")
+}
+
+/*
+withoutForeignFields writes the objects of the given manifests to a temporary manifest file, leaving out every field
+that is owned by another field manager than the given one in the live objects. The caller is responsible for removing the file.
+*/
+func withoutForeignFields(ctx context.Context, kubeconfigPath string, fieldManager string, recursive bool, filePaths ...string) (string, error) {
+	objs, err := readManifests(filePaths, recursive)
+	if err != nil {
+		return "", err
+	}
+
+	f := newFactory(kubeconfigPath)
+
+	dynamicClient, err := f.DynamicClient()
+	if err != nil {
+		return "", fmt.Errorf("could not create dynamic client: %w", err)
+	}
+
+	mapper, err := f.ToRESTMapper()
+	if err != nil {
+		return "", fmt.Errorf("could not create rest mapper: %w", err)
+	}
+
+	namespace, _, err := f.ToRawKubeConfigLoader().Namespace()
+	if err != nil {
+		return "", fmt.Errorf("could not determine default namespace: %w", err)
+	}
+
+	for _, obj := range objs {
+		client, err := objectClient(dynamicClient, mapper, namespace, obj)
+		if err != nil {
+			return "", err
+		}
+
+		live, err := client.Get(ctx, obj.GetName(), metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return "", fmt.Errorf("could not get %s %s: %w", obj.GetKind(), obj.GetName(), err)
+		}
+
+		foreignFields, err := foreignOwnedFields(live, fieldManager)
+		if err != nil {
+			return "", err
+		}
+
+		foreignFields.Leaves().Iterate(func(path fieldpath.Path) {
+			removeFieldPath(obj.Object, path)
+		})
+	}
+
+	return writeManifests(objs)
+}
+
+/*
+foreignOwnedFields returns the fields of the live object that are owned by other field managers than the given one.
+*/
+func foreignOwnedFields(live *unstructured.Unstructured, fieldManager string) (*fieldpath.Set, error) {
+	fields := fieldpath.NewSet()
+
+	for _, entry := range live.GetManagedFields() {
+		if entry.Manager == fieldManager || entry.FieldsV1 == nil {
+			continue
+		}
+
+		entryFields := fieldpath.NewSet()
+		err := entryFields.FromJSON(bytes.NewReader(entry.FieldsV1.Raw))
+		if err != nil {
+			return nil, fmt.Errorf("could not parse managed fields of %s for %s %s: %w", entry.Manager, live.GetKind(), live.GetName(), err)
+		}
+
+		fields = fields.Union(entryFields)
+	}
+
+	return fields, nil
+}
+
+/*
+removeFieldPath removes the field at the path from the object. Paths into atomic lists are ignored, as a single element
+of an atomic list cannot be owned on its own.
+*/
+func removeFieldPath(node interface{}, path fieldpath.Path) {
+	if len(path) == 0 {
+		return
+	}
+
+	pe := path[0]
+
+	switch {
+	case pe.FieldName != nil:
+		m, ok := node.(map[string]interface{})
+		if !ok {
+			return
+		}
+
+		if len(path) == 1 {
+			delete(m, *pe.FieldName)
+			return
+		}
+
+		child := m[*pe.FieldName]
+		removeFieldPath(child, path[1:])
+
+		// The list elements are removed in place, so we have to store the shortened list
+		if l, ok := child.([]interface{}); ok {
+			m[*pe.FieldName] = removeListElement(l, pe, path)
+		}
+
+	case pe.Key != nil || pe.Value != nil:
+		// List elements are handled by the map containing the list, see above
+	}
+}
+
+/*
+removeListElement handles the part of the path that selects an element of the list, either by key or by value.
+The element is removed when the path ends at the element, otherwise the rest of the path is removed from the element.
+*/
+func removeListElement(l []interface{}, parent fieldpath.PathElement, path fieldpath.Path) []interface{} {
+	if len(path) < 2 {
+		return l
+	}
+
+	pe := path[1]
+	if pe.Key == nil && pe.Value == nil {
+		return l
+	}
+
+	kept := []interface{}{}
+	for _, item := range l {
+		if !listElementMatches(item, pe) {
+			kept = append(kept, item)
+			continue
+		}
+
+		if len(path) == 2 {
+			// The path ends at the element, so the whole element is owned by someone else
+			continue
+		}
+
+		removeFieldPath(item, path[2:])
+		kept = append(kept, item)
+	}
+
+	return kept
+}
+
+func listElementMatches(item interface{}, pe fieldpath.PathElement) bool {
+	if pe.Value != nil {
+		return value.Equals(value.NewValueInterface(item), *pe.Value)
+	}
+
+	m, ok := item.(map[string]interface{})
+	if !ok {
+		return false
+	}
+
+	for _, field := range *pe.Key {
+		itemValue, ok := m[field.Name]
+		if !ok || !value.Equals(value.NewValueInterface(itemValue), field.Value) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,168 @@
+package kubectl
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Arneproductions/go-kube/internal/resources"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/structured-merge-diff/v4/fieldpath"
+	"sigs.k8s.io/structured-merge-diff/v4/value"
+)
+
+func TestConflictPolicy(t *testing.T) {
+	c := resources.NewEphemeralCluster()
+	require.NoError(t, c.Start())
+
+	t.Cleanup(func() {
+		err := c.Stop()
+		require.NoError(t, err)
+	})
+
+	// createForeignConfigMap creates a config map where data.foo is owned by another field manager
+	createForeignConfigMap := func(t *testing.T, ctx context.Context) string {
+		name := fmt.Sprintf("test-cm-%s", uuid.New().String())
+		patch := fmt.Sprintf(`{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"%s","namespace":"default"},"data":{"foo":"other"}}`, name)
+
+		_, err := c.Client().CoreV1().ConfigMaps("default").Patch(
+			ctx,
+			name,
+			types.ApplyPatchType,
+			[]byte(patch),
+			metav1.PatchOptions{FieldManager: "other-manager"},
+		)
+		require.NoError(t, err)
+
+		t.Cleanup(func() {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			defer cancel()
+
+			_ = c.Client().CoreV1().ConfigMaps("default").Delete(ctx, name, metav1.DeleteOptions{})
+		})
+
+		return name
+	}
+
+	conflictingManifest := func(name string) string {
+		return fmt.Sprintf(`
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: %s
+  namespace: default
+data:
+  foo: ours
+  bar: ours
+`, name)
+	}
+
+	t.Run("ApplyManifests_should_fail_on_conflicts_with_ConflictPolicyFail", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		name := createForeignConfigMap(t, ctx)
+		manifest := writeTestManifest(t, conflictingManifest(name))
+
+		err := ApplyManifests(ctx, c.KubeConfigFilePath(), &ApplyManifestsOptions{ServerSide: true, ConflictPolicy: ConflictPolicyFail}, manifest)
+		require.Error(t, err)
+
+		cm, err := c.Client().CoreV1().ConfigMaps("default").Get(ctx, name, metav1.GetOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, "other", cm.Data["foo"])
+	})
+
+	t.Run("ApplyManifests_should_take_over_fields_with_ConflictPolicyForce", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		name := createForeignConfigMap(t, ctx)
+		manifest := writeTestManifest(t, conflictingManifest(name))
+
+		err := ApplyManifests(ctx, c.KubeConfigFilePath(), &ApplyManifestsOptions{ServerSide: true, ConflictPolicy: ConflictPolicyForce}, manifest)
+		require.NoError(t, err)
+
+		cm, err := c.Client().CoreV1().ConfigMaps("default").Get(ctx, name, metav1.GetOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, "ours", cm.Data["foo"])
+		assert.Equal(t, "ours", cm.Data["bar"])
+	})
+
+	t.Run("ApplyManifests_should_leave_foreign_fields_with_ConflictPolicyRetryMerge", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		name := createForeignConfigMap(t, ctx)
+		manifest := writeTestManifest(t, conflictingManifest(name))
+
+		err := ApplyManifests(ctx, c.KubeConfigFilePath(), &ApplyManifestsOptions{ServerSide: true, ConflictPolicy: ConflictPolicyRetryMerge}, manifest)
+		require.NoError(t, err)
+
+		cm, err := c.Client().CoreV1().ConfigMaps("default").Get(ctx, name, metav1.GetOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, "other", cm.Data["foo"])
+		assert.Equal(t, "ours", cm.Data["bar"])
+	})
+}
+
+func TestRemoveFieldPath(t *testing.T) {
+	t.Run("removeFieldPath_should_remove_nested_fields", func(t *testing.T) {
+		obj := map[string]interface{}{
+			"data": map[string]interface{}{
+				"foo": "bar",
+				"baz": "qux",
+			},
+		}
+
+		removeFieldPath(obj, fieldpath.MakePathOrDie("data", "foo"))
+
+		assert.Equal(t, map[string]interface{}{"data": map[string]interface{}{"baz": "qux"}}, obj)
+	})
+
+	t.Run("removeFieldPath_should_remove_fields_of_keyed_list_elements", func(t *testing.T) {
+		obj := map[string]interface{}{
+			"spec": map[string]interface{}{
+				"containers": []interface{}{
+					map[string]interface{}{"name": "app", "image": "app:1"},
+					map[string]interface{}{"name": "sidecar", "image": "sidecar:1"},
+				},
+			},
+		}
+
+		removeFieldPath(obj, fieldpath.MakePathOrDie(
+			"spec",
+			"containers",
+			&value.FieldList{{Name: "name", Value: value.NewValueInterface("sidecar")}},
+			"image",
+		))
+
+		assert.Equal(t, []interface{}{
+			map[string]interface{}{"name": "app", "image": "app:1"},
+			map[string]interface{}{"name": "sidecar"},
+		}, obj["spec"].(map[string]interface{})["containers"])
+	})
+
+	t.Run("removeFieldPath_should_remove_set_elements", func(t *testing.T) {
+		obj := map[string]interface{}{
+			"metadata": map[string]interface{}{
+				"finalizers": []interface{}{"a", "b"},
+			},
+		}
+
+		removeFieldPath(obj, fieldpath.MakePathOrDie("metadata", "finalizers", value.NewValueInterface("a")))
+
+		assert.Equal(t, []interface{}{"b"}, obj["metadata"].(map[string]interface{})["finalizers"])
+	})
+}
@@ -0,0 +1,99 @@
+package kubectl
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+/*
+WaitForConversionWebhook waits until the CRD with the given name is established in the cluster that the kubeconfigPath
+points to, and the service backing its conversion webhook has ready endpoints. Conversion webhooks that are called
+through a URL instead of a service are only waited for by the CRD being established.
+
+Custom resources cannot be read or written in other versions than the stored one before the conversion webhook is
+serving, which makes applying a CRD together with its custom resources flaky while the webhook is starting.
+
+Example:
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	err := WaitForConversionWebhook(ctx, "/path/to/kubeconfig", "widgets.example.com")
+	if err != nil {
+		// Handle error
+	}
+*/
+func WaitForConversionWebhook(ctx context.Context, kubeconfigPath string, crdName string) error {
+	if kubeconfigPath == "" {
+		return fmt.Errorf("kubeconfig path cannot be empty")
+	}
+
+	if crdName == "" {
+		return fmt.Errorf("CRD name cannot be empty")
+	}
+
+	f := newFactory(kubeconfigPath)
+
+	dynamicClient, err := f.DynamicClient()
+	if err != nil {
+		return fmt.Errorf("could not create dynamic client: %w", err)
+	}
+
+	clientset, err := f.KubernetesClientSet()
+	if err != nil {
+		return fmt.Errorf("could not create clientset: %w", err)
+	}
+
+	err = wait.PollUntilContextCancel(ctx, time.Second, true, func(ctx context.Context) (bool, error) {
+		crd, err := dynamicClient.Resource(crdResource).Get(ctx, crdName, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		if err != nil {
+			return false, fmt.Errorf("could not get CRD %s: %w", crdName, err)
+		}
+
+		strategy, _, _ := unstructured.NestedString(crd.Object, "spec", "conversion", "strategy")
+		if strategy != "Webhook" {
+			return false, fmt.Errorf("CRD %s has no conversion webhook", crdName)
+		}
+
+		if !hasCondition(crd, "Established") {
+			return false, nil
+		}
+
+		servicePath := []string{"spec", "conversion", "webhook", "clientConfig", "service"}
+		name, found, _ := unstructured.NestedString(crd.Object, append(servicePath, "name")...)
+		if !found {
+			return true, nil
+		}
+		namespace, _, _ := unstructured.NestedString(crd.Object, append(servicePath, "namespace")...)
+
+		endpoints, err := clientset.CoreV1().Endpoints(namespace).Get(ctx, name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		if err != nil {
+			return false, fmt.Errorf("could not get endpoints of service %s/%s: %w", namespace, name, err)
+		}
+
+		for _, subset := range endpoints.Subsets {
+			if len(subset.Addresses) > 0 {
+				return true, nil
+			}
+		}
+
+		return false, nil
+	})
+	if err != nil {
+		return fmt.Errorf("conversion webhook of CRD %s is not ready: %w", crdName, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,99 @@
+package kubectl
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Arneproductions/go-kube/internal/resources"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// conversionWebhookCRDManifest is a CRD converting between v1 and v2 through a webhook behind the named service
+func conversionWebhookCRDManifest(service string) string {
+	return fmt.Sprintf(`apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: sprockets.go-kube.io
+spec:
+  group: go-kube.io
+  scope: Namespaced
+  names:
+    kind: Sprocket
+    plural: sprockets
+    singular: sprocket
+  conversion:
+    strategy: Webhook
+    webhook:
+      conversionReviewVersions: ["v1"]
+      clientConfig:
+        service:
+          name: %s
+          namespace: default
+          port: 80
+          path: /convert
+  versions:
+  - name: v1
+    served: true
+    storage: true
+    schema:
+      openAPIV3Schema:
+        type: object
+        x-kubernetes-preserve-unknown-fields: true
+  - name: v2
+    served: true
+    storage: false
+    schema:
+      openAPIV3Schema:
+        type: object
+        x-kubernetes-preserve-unknown-fields: true
+`, service)
+}
+
+func TestWaitForConversionWebhook(t *testing.T) {
+	c := resources.NewEphemeralCluster()
+	require.NoError(t, c.Start())
+
+	t.Cleanup(func() {
+		require.NoError(t, c.Stop())
+	})
+
+	t.Run("WaitForConversionWebhook_should_return_once_the_webhook_is_reachable", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Minute)
+		defer cancel()
+
+		name := fmt.Sprintf("test-convert-%s", uuid.New().String())
+
+		err := ApplyManifests(ctx, c.KubeConfigFilePath(), &ApplyManifestsOptions{}, writeTestManifest(t, conversionWebhookCRDManifest(name)))
+		require.NoError(t, err)
+
+		// The service of the webhook does not exist yet
+		waitCtx, waitCancel := context.WithTimeout(ctx, 5*time.Second)
+		defer waitCancel()
+
+		err = WaitForConversionWebhook(waitCtx, c.KubeConfigFilePath(), "sprockets.go-kube.io")
+		assert.Error(t, err)
+
+		// The stub webhook only has to be serving for its endpoints to be ready
+		err = ApplyManifests(ctx, c.KubeConfigFilePath(), &ApplyManifestsOptions{}, writeTestManifest(t, httpServiceManifest(name, "stub webhook")))
+		require.NoError(t, err)
+
+		err = WaitForConversionWebhook(ctx, c.KubeConfigFilePath(), "sprockets.go-kube.io")
+		assert.NoError(t, err)
+	})
+
+	t.Run("WaitForConversionWebhook_should_fail_for_CRDs_without_conversion_webhook", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		err := ApplyManifests(ctx, c.KubeConfigFilePath(), &ApplyManifestsOptions{}, writeTestManifest(t, gadgetCRDManifest))
+		require.NoError(t, err)
+
+		err = WaitForConversionWebhook(ctx, c.KubeConfigFilePath(), "gadgets.test.go-kube.io")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "has no conversion webhook")
+	})
+}
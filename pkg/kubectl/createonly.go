@@ -0,0 +1,133 @@
+package kubectl
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+/*
+splitCreateOnly splits the objects of the files into the objects of the create-only kinds, and the other objects.
+The other objects are written to a temporary manifest file when there are create-only objects, which is returned
+instead of the files, or no files when every object is create-only. The returned cleanup function removes the file.
+*/
+func splitCreateOnly(kinds []string, recursive bool, filePaths ...string) ([]string, []*unstructured.Unstructured, func(), error) {
+	cleanup := func() {}
+
+	objs, err := readManifests(filePaths, recursive)
+	if err != nil {
+		return nil, nil, cleanup, err
+	}
+
+	createOnly := []*unstructured.Unstructured{}
+	others := []*unstructured.Unstructured{}
+
+	for _, obj := range objs {
+		if isCreateOnlyKind(kinds, obj) {
+			createOnly = append(createOnly, obj)
+		} else {
+			others = append(others, obj)
+		}
+	}
+
+	if len(createOnly) == 0 {
+		return filePaths, nil, cleanup, nil
+	}
+
+	if len(others) == 0 {
+		return nil, createOnly, cleanup, nil
+	}
+
+	manifestPath, err := writeManifests(others)
+	if err != nil {
+		return nil, nil, cleanup, err
+	}
+
+	return []string{manifestPath}, createOnly, func() { os.Remove(manifestPath) }, nil
+}
+
+/*
+isCreateOnlyKind reports whether the kind of the object is among the kinds, given as Kind or Kind.group, e.g. Job or
+Job.batch.
+*/
+func isCreateOnlyKind(kinds []string, obj *unstructured.Unstructured) bool {
+	gvk := obj.GroupVersionKind()
+
+	for _, kind := range kinds {
+		name, group, _ := strings.Cut(kind, ".")
+		if name == gvk.Kind && (group == "" || group == gvk.Group) {
+			return true
+		}
+	}
+
+	return false
+}
+
+/*
+createObjects creates the objects in the cluster with the field manager, leaving the objects that already exist as they
+are. It returns the outcome of every object, where the objects that already existed are unchanged.
+*/
+func createObjects(ctx context.Context, kubeconfigPath string, fieldManager string, dryRun DryRunType, objs []*unstructured.Unstructured) ([]ObjectResult, error) {
+	results := []ObjectResult{}
+
+	if dryRun == DryRunClient {
+		for _, obj := range objs {
+			results = append(results, ObjectResult{ObjectRef: objectRefOf(obj), Operation: OperationCreated, DryRun: true})
+		}
+
+		return results, nil
+	}
+
+	f := newFactory(kubeconfigPath)
+
+	dynamicClient, err := f.DynamicClient()
+	if err != nil {
+		return nil, fmt.Errorf("could not create dynamic client: %w", err)
+	}
+
+	mapper, err := f.ToRESTMapper()
+	if err != nil {
+		return nil, fmt.Errorf("could not create rest mapper: %w", err)
+	}
+
+	namespace, _, err := f.ToRawKubeConfigLoader().Namespace()
+	if err != nil {
+		return nil, fmt.Errorf("could not determine default namespace: %w", err)
+	}
+
+	createOpts := metav1.CreateOptions{FieldManager: fieldManager}
+	if dryRun == DryRunServer {
+		createOpts.DryRun = []string{metav1.DryRunAll}
+	}
+
+	for _, obj := range objs {
+		client, err := objectClient(dynamicClient, mapper, namespace, obj)
+		if err != nil {
+			return results, err
+		}
+
+		objResult := ObjectResult{
+			ObjectRef: objectRefOf(obj),
+			Operation: OperationCreated,
+			DryRun:    dryRun == DryRunServer,
+		}
+
+		_, err = client.Create(ctx, obj, createOpts)
+		if apierrors.IsAlreadyExists(err) {
+			objResult.Operation = OperationUnchanged
+			err = nil
+		}
+		if err != nil {
+			return results, fmt.Errorf("could not create %s %s: %w", obj.GetKind(), obj.GetName(), err)
+		}
+
+		results = append(results, objResult)
+	}
+
+	return results, nil
+}
@@ -0,0 +1,102 @@
+package kubectl
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Arneproductions/go-kube/internal/resources"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// createOnlyBundleManifest is a config map to apply, and a job to create that echoes the message
+func createOnlyBundleManifest(name, message string) string {
+	return fmt.Sprintf(`apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: %[1]s
+  namespace: default
+data:
+  message: %[2]s
+---
+apiVersion: batch/v1
+kind: Job
+metadata:
+  name: %[1]s
+  namespace: default
+spec:
+  template:
+    spec:
+      restartPolicy: Never
+      containers:
+      - name: echo
+        image: busybox:1.36
+        command: ["echo", "%[2]s"]
+`, name, message)
+}
+
+func TestIsCreateOnlyKind(t *testing.T) {
+	job := &unstructured.Unstructured{}
+	job.SetAPIVersion("batch/v1")
+	job.SetKind("Job")
+
+	t.Run("isCreateOnlyKind_should_match_kinds_with_and_without_group", func(t *testing.T) {
+		assert.True(t, isCreateOnlyKind([]string{"Job"}, job))
+		assert.True(t, isCreateOnlyKind([]string{"ConfigMap", "Job.batch"}, job))
+	})
+
+	t.Run("isCreateOnlyKind_should_not_match_other_kinds_or_groups", func(t *testing.T) {
+		assert.False(t, isCreateOnlyKind([]string{"CronJob"}, job))
+		assert.False(t, isCreateOnlyKind([]string{"Job.example.com"}, job))
+		assert.False(t, isCreateOnlyKind(nil, job))
+	})
+}
+
+func TestCreateOnlyKinds(t *testing.T) {
+	c := resources.NewEphemeralCluster()
+	require.NoError(t, c.Start())
+
+	t.Cleanup(func() {
+		require.NoError(t, c.Stop())
+	})
+
+	t.Run("ApplyManifests_should_leave_existing_create_only_objects_as_they_are", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		name := fmt.Sprintf("test-bundle-%s", uuid.New().String())
+		opts := &ApplyManifestsOptions{CreateOnlyKinds: []string{"Job.batch"}}
+
+		result, err := ApplyManifestsWithResult(ctx, c.KubeConfigFilePath(), opts, writeTestManifest(t, createOnlyBundleManifest(name, "first")))
+		require.NoError(t, err)
+		assert.Contains(t, result.Objects, ObjectResult{ObjectRef: ObjectRef{Group: "batch", Kind: "job", Name: name}, Namespace: "default", Operation: OperationCreated})
+
+		// The pod template of a job is immutable, such that applying the changed job would fail
+		result, err = ApplyManifestsWithResult(ctx, c.KubeConfigFilePath(), opts, writeTestManifest(t, createOnlyBundleManifest(name, "second")))
+		require.NoError(t, err)
+		assert.Contains(t, result.Objects, ObjectResult{ObjectRef: ObjectRef{Group: "batch", Kind: "job", Name: name}, Namespace: "default", Operation: OperationUnchanged})
+
+		cm, err := c.Client().CoreV1().ConfigMaps("default").Get(ctx, name, metav1.GetOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, "second", cm.Data["message"])
+
+		job, err := c.Client().BatchV1().Jobs("default").Get(ctx, name, metav1.GetOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"echo", "first"}, job.Spec.Template.Spec.Containers[0].Command)
+	})
+
+	t.Run("ApplyManifests_should_reject_pruning_create_only_kinds", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		opts := &ApplyManifestsOptions{CreateOnlyKinds: []string{"Job"}, Prune: true, Selector: "app=test"}
+
+		err := ApplyManifests(ctx, c.KubeConfigFilePath(), opts, writeTestManifest(t, createOnlyBundleManifest("test-prune", "message")))
+		assert.Error(t, err)
+	})
+}
@@ -3,23 +3,74 @@ package kubectl
 import (
 	"context"
 	"fmt"
+	"os"
 	"strings"
-	"sync"
 	"time"
 
-	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"github.com/spf13/cobra"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/kubectl/pkg/cmd/apply"
 	"k8s.io/kubectl/pkg/cmd/create"
 	"k8s.io/kubectl/pkg/cmd/delete"
 	"k8s.io/kubectl/pkg/cmd/util"
 )
 
-var (
-	deleteLock = &sync.Mutex{}
-)
+type DeleteManifestsOptions struct {
+	/*
+		Skips objects that are not owned by the FieldManager or by client-side applies, i.e. objects that another
+		field manager or a controller has taken over since they were applied
+	*/
+	SkipForeignOwned bool
+	/*
+		Leaves the objects in the cluster, and only releases the ownership of the FieldManager of their fields by
+		server-side applying the objects without any fields. Fields that no other manager owns are removed by the
+		API server, so another owner has to apply the fields it wants to keep first. Ownership from client-side
+		applies is not released
+	*/
+	ReleaseOwnershipOnly bool
+	/*
+		Waits up to the given time for the deleted objects to be gone, instead of waiting until the context is
+		done. Objects that are still held back by their finalizers afterwards fail the delete, unless
+		ForceRemoveFinalizers is set
+	*/
+	FinalizerTimeout time.Duration
+	/*
+		Removes the finalizers of the objects that are still terminating after the FinalizerTimeout, such that they
+		are deleted even when the controllers of their finalizers are broken. Without a FinalizerTimeout, the
+		finalizers are removed right away. The finalizers do not get to clean up, so this is meant for test clusters
+		that are torn down anyway
+	*/
+	ForceRemoveFinalizers bool
+	/*
+		Runs a dry-run of the delete i.e. kubectl delete --dry-run=server. The objects are left in the cluster
+	*/
+	DryRun DryRunType
+	/*
+		Treats objects that do not exist as already deleted i.e. kubectl delete --ignore-not-found. The result of
+		DeleteManifestsWithResult lists them as not found
+	*/
+	IgnoreNotFound bool
+	/*
+		Reads the manifests of directories recursively i.e. kubectl delete --recursive
+	*/
+	Recursive bool
+}
 
 type deleteOptions struct {
-	IsKustomization bool `default:"false"`
+	IsKustomization  bool `default:"false"`
+	SkipForeignOwned bool `default:"false"`
+	// Returns once the objects are marked for deletion, instead of waiting for them to be gone
+	NoWait         bool       `default:"false"`
+	DryRun         DryRunType `default:"none"`
+	IgnoreNotFound bool       `default:"false"`
+	Recursive      bool       `default:"false"`
+	/*
+		When set, the outcome of the delete is parsed into the result
+	*/
+	Result *DeleteResult
 }
 
 /*
@@ -41,10 +92,83 @@ Example:
 	}
 */
 func DeleteManifests(ctx context.Context, kubeconfigPath string, filePaths ...string) error {
+	return DeleteManifestsWithOptions(ctx, kubeconfigPath, &DeleteManifestsOptions{}, filePaths...)
+}
 
-	opts := &deleteOptions{}
+/*
+DeleteManifestsWithOptions deletes the given files like DeleteManifests, with the given DeleteManifestsOptions.
 
-	return deleteFunc(ctx, kubeconfigPath, opts, filePaths...)
+Example:
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	err := DeleteManifestsWithOptions(
+		ctx,
+		"/path/to/kubeconfig",
+		&DeleteManifestsOptions{SkipForeignOwned: true},
+		[]string{"path/to/file1", "path/to/file2"}...
+	)
+
+	if err != nil {
+		// Handle error
+	}
+*/
+func DeleteManifestsWithOptions(ctx context.Context, kubeconfigPath string, deleteOpts *DeleteManifestsOptions, filePaths ...string) error {
+	_, err := DeleteManifestsWithResult(ctx, kubeconfigPath, deleteOpts, filePaths...)
+
+	return err
+}
+
+/*
+DeleteManifestsWithResult deletes the given files like DeleteManifestsWithOptions, and returns which objects kubectl deleted.
+
+Example:
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	result, err := DeleteManifestsWithResult(
+		ctx,
+		"/path/to/kubeconfig",
+		&DeleteManifestsOptions{IgnoreNotFound: true},
+		[]string{"path/to/file1", "path/to/file2"}...
+	)
+
+	if err != nil {
+		// Handle error
+	}
+
+	for _, ref := range result.Deleted {
+		log.Printf("deleted %s/%s", ref.Kind, ref.Name)
+	}
+*/
+func DeleteManifestsWithResult(ctx context.Context, kubeconfigPath string, deleteOpts *DeleteManifestsOptions, filePaths ...string) (*DeleteResult, error) {
+	if deleteOpts == nil {
+		return nil, fmt.Errorf("options cannot be nil")
+	}
+
+	result := &DeleteResult{}
+
+	if deleteOpts.ReleaseOwnershipOnly {
+		return result, releaseOwnership(ctx, kubeconfigPath, deleteOpts.Recursive, filePaths...)
+	}
+
+	opts := &deleteOptions{
+		SkipForeignOwned: deleteOpts.SkipForeignOwned,
+		NoWait:           deleteOpts.FinalizerTimeout > 0 || deleteOpts.ForceRemoveFinalizers,
+		DryRun:           deleteOpts.DryRun,
+		IgnoreNotFound:   deleteOpts.IgnoreNotFound,
+		Recursive:        deleteOpts.Recursive,
+		Result:           result,
+	}
+
+	err := deleteFunc(ctx, kubeconfigPath, opts, filePaths...)
+	if err != nil || !opts.NoWait || opts.DryRun != DryRunNone {
+		return result, err
+	}
+
+	return result, awaitFinalizers(ctx, kubeconfigPath, deleteOpts.FinalizerTimeout, deleteOpts.ForceRemoveFinalizers, deleteOpts.Recursive, filePaths...)
 }
 
 /*
@@ -74,8 +198,13 @@ func DeleteKustomization(ctx context.Context, kubeconfigPath string, filePaths .
 }
 
 func deleteFunc(ctx context.Context, kubeconfigPath string, opts *deleteOptions, filePaths ...string) error {
-	deleteLock.Lock()
-	defer deleteLock.Unlock()
+	// Like the apply command, the delete command may encounter a fatal error which
+	// changes global behaviour
+	lock, err := beginKubectl(ctx, "kubectl delete")
+	if err != nil {
+		return err
+	}
+	defer lock.unlock()
 
 	if kubeconfigPath == "" {
 		return fmt.Errorf("kubeconfig path cannot be empty")
@@ -89,45 +218,75 @@ func deleteFunc(ctx context.Context, kubeconfigPath string, opts *deleteOptions,
 		return fmt.Errorf("no files to delete")
 	}
 
-	ioStreams, streamOut, _, streamErr := genericiooptions.NewTestIOStreams()
+	ioStreams, streamOut, streamErr := newIOStreams()
+
+	errChan := newErrChan()
 
-	config := genericclioptions.
-		NewConfigFlags(true).
-		WithDeprecatedPasswordFlag().
-		WithDiscoveryBurst(300).
-		WithDiscoveryQPS(50.0)
+	// Send an error to the error channel when the context is cancelled or its deadline is reached
+	operation := fmt.Sprintf("kubectl delete of %d files", len(filePaths))
+	timeLeft, stopWatching := watchContext(ctx, operation, errChan, streamOut, streamErr)
+	defer stopWatching()
 
-	config.KubeConfig = &kubeconfigPath
-	f := util.NewFactory(config)
+	// The deadline may have passed while we waited for the kubectl lock
+	if err := expiredError(operation, timeLeft, streamOut, streamErr); err != nil {
+		return err
+	}
+
+	f := newCommandFactory(kubeconfigPath, "", timeLeft)
 
-	errChan := make(chan error)
+	if opts.SkipForeignOwned {
+		ownedFile, err := ownedManifests(ctx, f, filePaths, opts.Recursive)
+		if err != nil {
+			return err
+		}
 
-	// We find out if the context have a deadline, from there we derive amount of time left
-	deadline, ok := ctx.Deadline()
-	if !ok {
-		deadline = time.Now().Add(15 * time.Second) // This deadline is arbitary
+		// Every object is owned by someone else, so there is nothing for us to delete
+		if ownedFile == "" {
+			return nil
+		}
+		defer os.Remove(ownedFile)
+
+		filePaths = []string{ownedFile}
 	}
-	timeLeft := deadline.Sub(time.Now())
 
-	// Send a timeout error to the error channel when the deadline is reached
-	time.AfterFunc(timeLeft, func() {
-		errChan <- context.DeadlineExceeded
-	})
+	deleteCmd, createCmd := newDeleteCommand(f, ioStreams, opts, timeLeft, filePaths...)
 
-	util.BehaviorOnFatal(func(msg string, errCode int) {
-		err := fmt.Errorf(
-			"fatal error: %s\nerror code: %d\nout stream: %s\nerror stream: %s\n",
-			msg,
-			errCode,
-			streamOut.String(),
-			streamErr.String(),
-		)
-		errChan <- err
+	lock.run(errChan, streamOut, streamErr, func() error {
+		// deleteCmd is blocking. Should it fail it should have called the fatal error handler which
+		// run overrides to send an error to errChan
+		deleteCmd.Run(createCmd, []string{})
+		return nil
 	})
 
-	// We restore the default behavior for fatal errors when we are done
-	defer util.DefaultBehaviorOnFatal()
+	// We return the first item in the error channel
+	err = <-errChan
+	if err == nil && opts.Result != nil {
+		result := parseDeleteOutput(streamOut.String())
+
+		// kubectl does not print the objects it did not find, so we find them in the manifests
+		if opts.IgnoreNotFound && !opts.IsKustomization {
+			objs, err := readManifests(filePaths, opts.Recursive)
+			if err != nil {
+				return err
+			}
 
+			result.NotFound = notFoundObjects(objs, result)
+		}
+
+		opts.Result.Deleted = append(opts.Result.Deleted, result.Deleted...)
+		opts.Result.WouldDelete = append(opts.Result.WouldDelete, result.WouldDelete...)
+		opts.Result.NotFound = append(opts.Result.NotFound, result.NotFound...)
+	}
+
+	return err
+}
+
+/*
+newDeleteCommand returns the kubectl delete command of the options, along with the "parent" command that it is run
+with.
+*/
+func newDeleteCommand(f util.Factory, ioStreams genericiooptions.IOStreams, opts *deleteOptions, timeLeft time.Duration, filePaths ...string) (*cobra.Command, *cobra.Command) {
+	// We create a "parent" command for the delete command, which it reads the dry-run strategy from
 	createCmd := create.NewCmdCreate(f, ioStreams)
 	deleteCmd := delete.NewCmdDelete(f, ioStreams)
 
@@ -137,12 +296,104 @@ func deleteFunc(ctx context.Context, kubeconfigPath string, opts *deleteOptions,
 		deleteCmd.Flags().Set("filename", strings.Join(filePaths, ","))
 	}
 
-	go func() {
-		// deleteCmd is blocking. Should it fail it should have called the fatal error handler which
-		// we override earlier to send an error to errChan
-		deleteCmd.Run(createCmd, []string{})
-		errChan <- nil
-	}()
+	// The delete command waits for the objects to be gone, for up to a week when the timeout is not set, e.g. on a
+	// finalizer that is never removed
+	deleteCmd.Flags().Set("timeout", timeLeft.String())
+
+	if opts.NoWait {
+		deleteCmd.Flags().Set("wait", "false")
+	}
+
+	if opts.Recursive {
+		deleteCmd.Flags().Set("recursive", "true")
+	}
+
+	if opts.IgnoreNotFound {
+		deleteCmd.Flags().Set("ignore-not-found", "true")
+	}
+
+	// Like the apply command, the delete command reads the dry-run strategy from the command it is run with, so it
+	// has to be set on the "parent" command
+	createCmd.Flags().Set("dry-run", opts.DryRun.String())
+
+	return deleteCmd, createCmd
+}
+
+/*
+ownedManifests writes the objects from the given manifests that are not owned by another field manager or controller
+to a temporary manifest file, and returns the path to it. An empty path is returned if no objects are left.
+*/
+func ownedManifests(ctx context.Context, f util.Factory, filePaths []string, recursive bool) (string, error) {
+	objs, err := readManifests(filePaths, recursive)
+	if err != nil {
+		return "", err
+	}
+
+	dynamicClient, err := f.DynamicClient()
+	if err != nil {
+		return "", fmt.Errorf("could not create dynamic client: %w", err)
+	}
+
+	mapper, err := f.ToRESTMapper()
+	if err != nil {
+		return "", fmt.Errorf("could not create rest mapper: %w", err)
+	}
+
+	namespace, _, err := f.ToRawKubeConfigLoader().Namespace()
+	if err != nil {
+		return "", fmt.Errorf("could not determine default namespace: %w", err)
+	}
+
+	owned := []*unstructured.Unstructured{}
+	for _, obj := range objs {
+		client, err := objectClient(dynamicClient, mapper, namespace, obj)
+		if err != nil {
+			return "", err
+		}
+
+		live, err := client.Get(ctx, obj.GetName(), metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			// We leave it to kubectl to report objects that do not exist
+			owned = append(owned, obj)
+			continue
+		}
+		if err != nil {
+			return "", fmt.Errorf("could not get %s %s: %w", obj.GetKind(), obj.GetName(), err)
+		}
+
+		if !isForeignOwned(live) {
+			owned = append(owned, obj)
+		}
+	}
+
+	if len(owned) == 0 {
+		return "", nil
+	}
+
+	return writeManifests(owned)
+}
+
+/*
+isForeignOwned reports whether the live object is controlled by another object, or is only managed by other field managers
+than the FieldManager and the field manager of client-side applies. Objects without managed fields are not considered
+foreign owned, as we cannot tell who owns them. Client-side applies keep the field manager of kubectl, so objects
+applied client-side by kubectl itself are not told apart from objects applied client-side by us.
+*/
+func isForeignOwned(live *unstructured.Unstructured) bool {
+	if metav1.GetControllerOf(live) != nil {
+		return true
+	}
+
+	managedFields := live.GetManagedFields()
+	if len(managedFields) == 0 {
+		return false
+	}
+
+	for _, entry := range managedFields {
+		if entry.Manager == FieldManager || entry.Manager == apply.FieldManagerClientSideApply {
+			return false
+		}
+	}
 
-	return <-errChan
+	return true
 }
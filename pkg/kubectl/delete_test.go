@@ -12,8 +12,12 @@ import (
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/kubectl/pkg/cmd/util"
 )
 
 func TestDeleteFunc(t *testing.T) {
@@ -134,6 +138,185 @@ func TestDeleteFunc(t *testing.T) {
 		assert.Error(t, err)
 	})
 
+	t.Run("deleteFunc_should_skip_foreign_owned_objects", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		ownedName := uuid.New().String()
+		foreignName := uuid.New().String()
+
+		manifest := strings.ReplaceAll(strings.ReplaceAll(`
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: %owned%
+  namespace: default
+data:
+  foo: bar
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: %foreign%
+  namespace: default
+data:
+  foo: bar
+`, "%owned%", ownedName), "%foreign%", foreignName)
+
+		tmpFile, err := os.CreateTemp("", "delete-foreign-*.yaml")
+		require.NoError(t, err)
+
+		t.Cleanup(func() {
+			assert.NoError(t, os.Remove(tmpFile.Name()))
+		})
+
+		_, err = tmpFile.WriteString(manifest)
+		require.NoError(t, err)
+		require.NoError(t, tmpFile.Close())
+
+		// Another field manager has created the foreign config map before we apply
+		_, err = c.Client().CoreV1().ConfigMaps("default").Create(ctx, &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: foreignName,
+			},
+			Data: map[string]string{"foo": "bar"},
+		}, metav1.CreateOptions{FieldManager: "other-manager"})
+		require.NoError(t, err)
+
+		t.Cleanup(func() {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			defer cancel()
+
+			_ = c.Client().CoreV1().ConfigMaps("default").Delete(ctx, foreignName, metav1.DeleteOptions{})
+		})
+
+		// Apply only the owned config map, so the foreign one is solely managed by the other manager
+		ownedFile, err := writeManifests(mustDecodeManifests(t, manifest)[:1])
+		require.NoError(t, err)
+
+		t.Cleanup(func() {
+			assert.NoError(t, os.Remove(ownedFile))
+		})
+
+		err = applyFunc(ctx, c.KubeConfigFilePath(), &applyOptions{}, ownedFile)
+		require.NoError(t, err)
+
+		err = deleteFunc(ctx, c.KubeConfigFilePath(), &deleteOptions{SkipForeignOwned: true}, tmpFile.Name())
+		assert.NoError(t, err)
+
+		_, err = c.Client().CoreV1().ConfigMaps("default").Get(ctx, ownedName, metav1.GetOptions{})
+		assert.Error(t, err)
+
+		_, err = c.Client().CoreV1().ConfigMaps("default").Get(ctx, foreignName, metav1.GetOptions{})
+		assert.NoError(t, err, "foreign owned config map should survive the delete")
+	})
+
+	t.Run("DeleteManifests_should_only_release_ownership_with_ReleaseOwnershipOnly", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		name := uuid.New().String()
+		manifest := writeTestManifest(t, configMapDataManifest(name, "foo", "bar"))
+
+		err := ApplyManifests(ctx, c.KubeConfigFilePath(), &ApplyManifestsOptions{ServerSide: true}, manifest)
+		require.NoError(t, err)
+
+		t.Cleanup(func() {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			defer cancel()
+
+			_ = c.Client().CoreV1().ConfigMaps("default").Delete(ctx, name, metav1.DeleteOptions{})
+		})
+
+		err = DeleteManifestsWithOptions(ctx, c.KubeConfigFilePath(), &DeleteManifestsOptions{ReleaseOwnershipOnly: true}, manifest)
+		require.NoError(t, err)
+
+		cm, err := c.Client().CoreV1().ConfigMaps("default").Get(ctx, name, metav1.GetOptions{})
+		require.NoError(t, err, "the config map should be left in the cluster")
+
+		for _, entry := range cm.ManagedFields {
+			assert.NotEqual(t, FieldManager, entry.Manager, "the config map should no longer be owned by us")
+		}
+	})
+
+	t.Run("DeleteManifests_should_not_create_missing_objects_with_ReleaseOwnershipOnly", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		dir := t.TempDir()
+		name := uuid.New().String()
+		require.NoError(t, os.MkdirAll(path.Join(dir, "nested"), 0o755))
+		require.NoError(t, os.WriteFile(path.Join(dir, "nested", "cm.yaml"), []byte(configMapDataManifest(name, "foo", "bar")), 0o644))
+
+		err := DeleteManifestsWithOptions(ctx, c.KubeConfigFilePath(), &DeleteManifestsOptions{ReleaseOwnershipOnly: true, Recursive: true}, dir)
+		require.NoError(t, err)
+
+		_, err = c.Client().CoreV1().ConfigMaps("default").Get(ctx, name, metav1.GetOptions{})
+		assert.True(t, apierrors.IsNotFound(err), "the config map should not have been created")
+	})
+
+	t.Run("DeleteManifestsWithResult_should_return_the_deleted_objects", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		first, second := uuid.New().String(), uuid.New().String()
+		manifest := writeTestManifest(t, configMapDataManifest(first, "foo", "bar")+"---\n"+configMapDataManifest(second, "foo", "bar"))
+
+		require.NoError(t, ApplyManifests(ctx, c.KubeConfigFilePath(), &ApplyManifestsOptions{}, manifest))
+
+		result, err := DeleteManifestsWithResult(ctx, c.KubeConfigFilePath(), &DeleteManifestsOptions{}, manifest)
+		require.NoError(t, err)
+
+		assert.ElementsMatch(t, []ObjectRef{
+			{Kind: "configmap", Name: first},
+			{Kind: "configmap", Name: second},
+		}, result.Deleted)
+		assert.Empty(t, result.WouldDelete)
+		assert.Empty(t, result.NotFound)
+	})
+
+	t.Run("DeleteManifestsWithResult_should_return_the_objects_that_were_not_found", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		existing, missing := uuid.New().String(), uuid.New().String()
+		require.NoError(t, ApplyManifests(ctx, c.KubeConfigFilePath(), &ApplyManifestsOptions{}, writeTestManifest(t, configMapDataManifest(existing, "foo", "bar"))))
+
+		manifest := writeTestManifest(t, configMapDataManifest(existing, "foo", "bar")+"---\n"+configMapDataManifest(missing, "foo", "bar"))
+
+		result, err := DeleteManifestsWithResult(ctx, c.KubeConfigFilePath(), &DeleteManifestsOptions{IgnoreNotFound: true}, manifest)
+		require.NoError(t, err)
+
+		assert.Equal(t, []ObjectRef{{Kind: "configmap", Name: existing}}, result.Deleted)
+		assert.Equal(t, []ObjectRef{{Kind: "configmap", Name: missing}}, result.NotFound)
+	})
+
+	t.Run("DeleteManifestsWithResult_should_leave_the_objects_on_a_dry_run", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		name := uuid.New().String()
+		manifest := writeTestManifest(t, configMapDataManifest(name, "foo", "bar"))
+
+		require.NoError(t, ApplyManifests(ctx, c.KubeConfigFilePath(), &ApplyManifestsOptions{}, manifest))
+
+		t.Cleanup(func() {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			defer cancel()
+
+			_ = c.Client().CoreV1().ConfigMaps("default").Delete(ctx, name, metav1.DeleteOptions{})
+		})
+
+		result, err := DeleteManifestsWithResult(ctx, c.KubeConfigFilePath(), &DeleteManifestsOptions{DryRun: DryRunServer}, manifest)
+		require.NoError(t, err)
+
+		assert.Empty(t, result.Deleted)
+		assert.Equal(t, []ObjectRef{{Kind: "configmap", Name: name}}, result.WouldDelete)
+
+		_, err = c.Client().CoreV1().ConfigMaps("default").Get(ctx, name, metav1.GetOptions{})
+		assert.NoError(t, err, "the config map should be left in the cluster")
+	})
+
 	t.Run("deleteFunc_should_delete_kustomization", func(t *testing.T) {
 		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
 		defer cancel()
@@ -270,3 +453,49 @@ spec:
 
 	return tmpFile.Name(), deplName, nil
 }
+
+func mustDecodeManifests(t *testing.T, manifest string) []*unstructured.Unstructured {
+	objs, err := decodeManifests([]byte(manifest), "test manifest")
+	require.NoError(t, err)
+
+	return objs
+}
+
+func TestNewDeleteCommand(t *testing.T) {
+	t.Run("newDeleteCommand_should_set_the_dry_run_on_the_command_it_is_run_with", func(t *testing.T) {
+		// The commands read the fatal error handler when they are created, which the kubectl lock guards
+		mu := kubectlLock()
+		mu.Lock()
+		defer mu.Unlock()
+
+		ioStreams, _, _ := newIOStreams()
+		f := newCommandFactory("/path/to/kubeconfig", "", time.Minute)
+
+		for _, dryRun := range []DryRunType{DryRunNone, DryRunClient, DryRunServer} {
+			_, runCmd := newDeleteCommand(f, ioStreams, &deleteOptions{DryRun: dryRun}, time.Minute, "/path/to/manifest.yaml")
+
+			strategy, err := util.GetDryRunStrategy(runCmd)
+			require.NoError(t, err)
+			assert.Equal(t, map[DryRunType]util.DryRunStrategy{
+				DryRunNone:   util.DryRunNone,
+				DryRunClient: util.DryRunClient,
+				DryRunServer: util.DryRunServer,
+			}[dryRun], strategy, dryRun.String())
+		}
+	})
+}
+
+func TestIsForeignOwned(t *testing.T) {
+	t.Run("isForeignOwned_should_accept_our_server_side_and_client_side_applies", func(t *testing.T) {
+		for manager, foreign := range map[string]bool{
+			FieldManager:                false,
+			"kubectl-client-side-apply": false,
+			"other-manager":             true,
+		} {
+			live := &unstructured.Unstructured{}
+			live.SetManagedFields([]metav1.ManagedFieldsEntry{{Manager: manager}})
+
+			assert.Equal(t, foreign, isForeignOwned(live), manager)
+		}
+	})
+}
@@ -0,0 +1,91 @@
+package kubectl
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+/*
+DiffManifests returns the unified diff between the objects of two sets of manifest files, e.g. two versions of a
+manifest directory, without a cluster. The objects are matched by their group, kind, namespace and name, and are
+compared as YAML with sorted keys, so formatting does not show up in the diff. Objects that are only in one of the
+sets are diffed against an empty file. The diff is empty when the objects do not differ. Directories are read like
+kubectl apply reads them with --recursive.
+
+Example:
+
+	diff, err := DiffManifests([]string{"/path/to/old/manifests"}, []string{"/path/to/new/manifests"})
+	if err != nil {
+		// Handle error
+	}
+
+	fmt.Print(diff)
+*/
+func DiffManifests(oldFiles, newFiles []string) (string, error) {
+	oldObjs, err := readManifests(oldFiles, true)
+	if err != nil {
+		return "", err
+	}
+
+	newObjs, err := readManifests(newFiles, true)
+	if err != nil {
+		return "", err
+	}
+
+	// The objects are diffed in the order they first appear, the old objects first
+	keys := []objectKey{}
+	oldIndex := map[objectKey]*unstructured.Unstructured{}
+	newIndex := map[objectKey]*unstructured.Unstructured{}
+
+	for _, obj := range oldObjs {
+		key := objectKeyOf(obj)
+		if _, ok := oldIndex[key]; !ok {
+			keys = append(keys, key)
+		}
+		oldIndex[key] = obj
+	}
+
+	for _, obj := range newObjs {
+		key := objectKeyOf(obj)
+		_, inOld := oldIndex[key]
+		_, inNew := newIndex[key]
+		if !inOld && !inNew {
+			keys = append(keys, key)
+		}
+		newIndex[key] = obj
+	}
+
+	diffs := []string{}
+	for _, key := range keys {
+		name := diffName(key)
+
+		diff, err := unifiedObjectDiff(fmt.Sprintf("old/%s", name), fmt.Sprintf("new/%s", name), oldIndex[key], newIndex[key])
+		if err != nil {
+			return "", err
+		}
+
+		if diff != "" {
+			diffs = append(diffs, diff)
+		}
+	}
+
+	return strings.Join(diffs, ""), nil
+}
+
+/*
+diffName names the object in the headers of the diff, e.g. apps/Deployment/default/nginx.
+*/
+func diffName(key objectKey) string {
+	parts := []string{key.Kind}
+	if key.Group != "" {
+		parts = []string{key.Group, key.Kind}
+	}
+
+	if key.Namespace != "" {
+		parts = append(parts, key.Namespace)
+	}
+
+	return strings.Join(append(parts, key.Name), "/")
+}
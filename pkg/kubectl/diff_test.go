@@ -0,0 +1,43 @@
+package kubectl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffManifests(t *testing.T) {
+	t.Run("DiffManifests_should_show_the_changed_data", func(t *testing.T) {
+		oldManifest := writeTestManifest(t, configMapDataManifest("foo", "key", "old-value"))
+		newManifest := writeTestManifest(t, configMapDataManifest("foo", "key", "new-value"))
+
+		diff, err := DiffManifests([]string{oldManifest}, []string{newManifest})
+		require.NoError(t, err)
+
+		assert.Contains(t, diff, "--- old/ConfigMap/default/foo")
+		assert.Contains(t, diff, "+++ new/ConfigMap/default/foo")
+		assert.Contains(t, diff, "-  key: old-value")
+		assert.Contains(t, diff, "+  key: new-value")
+	})
+
+	t.Run("DiffManifests_should_show_added_and_removed_objects", func(t *testing.T) {
+		oldManifest := writeTestManifest(t, configMapDataManifest("removed", "key", "value"))
+		newManifest := writeTestManifest(t, namespaceManifest("added"))
+
+		diff, err := DiffManifests([]string{oldManifest}, []string{newManifest})
+		require.NoError(t, err)
+
+		assert.Contains(t, diff, "-  name: removed")
+		assert.Contains(t, diff, "+  name: added")
+	})
+
+	t.Run("DiffManifests_should_ignore_formatting", func(t *testing.T) {
+		oldManifest := writeTestManifest(t, configMapDataManifest("foo", "key", "value"))
+		newManifest := writeTestManifest(t, `{"kind": "ConfigMap", "apiVersion": "v1", "metadata": {"namespace": "default", "name": "foo"}, "data": {"key": "value"}}`)
+
+		diff, err := DiffManifests([]string{oldManifest}, []string{newManifest})
+		require.NoError(t, err)
+		assert.Empty(t, diff)
+	})
+}
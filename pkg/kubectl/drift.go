@@ -0,0 +1,192 @@
+package kubectl
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	sigsyaml "sigs.k8s.io/yaml"
+)
+
+var (
+	// The metadata fields that change with every write, and therefore do not tell whether an object drifted
+	driftIgnoredMetadataFields = []string{"managedFields", "resourceVersion", "generation"}
+)
+
+// DriftError is returned when the objects in the cluster differ from their manifests
+type DriftError struct {
+	// The objects that differ from their manifests
+	Objects []ObjectRef
+	// The unified diff between the objects in the cluster and the objects as they would be after an apply
+	Diff string
+}
+
+func (e *DriftError) Error() string {
+	refs := []string{}
+	for _, ref := range e.Objects {
+		refs = append(refs, fmt.Sprintf("%s/%s", ref.Kind, ref.Name))
+	}
+
+	return fmt.Sprintf("%d objects drifted from their manifests: %s\n%s", len(e.Objects), strings.Join(refs, ", "), e.Diff)
+}
+
+/*
+checkDrift server-side dry-run applies the objects in the given files, and compares the outcome to the objects in the
+cluster. A DriftError is returned when any object would be created or changed by the apply. Nothing is applied.
+*/
+func checkDrift(ctx context.Context, kubeconfigPath string, opts *ApplyManifestsOptions, filePaths ...string) (*ApplyResult, error) {
+	if err := operations.begin(); err != nil {
+		return nil, err
+	}
+	defer operations.end()
+
+	if kubeconfigPath == "" {
+		return nil, fmt.Errorf("kubeconfig path cannot be empty")
+	}
+
+	objs, err := readManifests(filePaths, opts.Recursive)
+	if err != nil {
+		return nil, err
+	}
+
+	f := newFactory(kubeconfigPath)
+
+	dynamicClient, err := f.DynamicClient()
+	if err != nil {
+		return nil, fmt.Errorf("could not create dynamic client: %w", err)
+	}
+
+	mapper, err := f.ToRESTMapper()
+	if err != nil {
+		return nil, fmt.Errorf("could not create rest mapper: %w", err)
+	}
+
+	namespace, _, err := f.ToRawKubeConfigLoader().Namespace()
+	if err != nil {
+		return nil, fmt.Errorf("could not determine default namespace: %w", err)
+	}
+
+	// The apply is forced, such that fields changed by other managers are reported as drift instead of conflicts
+	applyOpts := metav1.ApplyOptions{
+		FieldManager: fieldManagerOf(opts),
+		Force:        true,
+		DryRun:       []string{metav1.DryRunAll},
+	}
+
+	result := &ApplyResult{}
+	driftErr := &DriftError{}
+	diffs := []string{}
+
+	for _, obj := range objs {
+		client, err := objectClient(dynamicClient, mapper, namespace, obj)
+		if err != nil {
+			return nil, err
+		}
+
+		live, err := client.Get(ctx, obj.GetName(), metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			live = nil
+		} else if err != nil {
+			return nil, fmt.Errorf("could not get %s %s: %w", obj.GetKind(), obj.GetName(), err)
+		}
+
+		applied, err := client.Apply(ctx, obj.GetName(), obj, applyOpts)
+		if err != nil {
+			return nil, fmt.Errorf("could not dry-run apply %s %s: %w", obj.GetKind(), obj.GetName(), err)
+		}
+
+		ref := objectRefOf(obj)
+		name := fmt.Sprintf("%s/%s", ref.Kind, ref.Name)
+
+		diff, err := objectDiff(name, live, applied)
+		if err != nil {
+			return nil, err
+		}
+
+		operation := OperationUnchanged
+		switch {
+		case live == nil:
+			operation = OperationCreated
+		case diff != "":
+			operation = OperationConfigured
+		}
+
+		result.add([]ObjectResult{{ObjectRef: ref, Operation: operation, DryRun: true}})
+
+		if diff != "" {
+			driftErr.Objects = append(driftErr.Objects, ref)
+			diffs = append(diffs, diff)
+		}
+	}
+
+	if len(driftErr.Objects) > 0 {
+		driftErr.Diff = strings.Join(diffs, "")
+		return result, driftErr
+	}
+
+	return result, nil
+}
+
+/*
+objectDiff returns the unified diff between the live object and the object as it would be after an apply, ignoring the
+fields that change with every write. The diff is empty when the objects do not differ.
+*/
+func objectDiff(name string, live, applied *unstructured.Unstructured) (string, error) {
+	return unifiedObjectDiff(fmt.Sprintf("live/%s", name), fmt.Sprintf("manifest/%s", name), live, applied)
+}
+
+/*
+unifiedObjectDiff returns the unified diff between the objects as YAML, ignoring the fields that change with every
+write. A nil object is diffed as an empty file.
+*/
+func unifiedObjectDiff(fromFile, toFile string, from, to *unstructured.Unstructured) (string, error) {
+	fromYAML, err := driftYAML(from)
+	if err != nil {
+		return "", err
+	}
+
+	toYAML, err := driftYAML(to)
+	if err != nil {
+		return "", err
+	}
+
+	if fromYAML == toYAML {
+		return "", nil
+	}
+
+	return difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(fromYAML),
+		B:        difflib.SplitLines(toYAML),
+		FromFile: fromFile,
+		ToFile:   toFile,
+		Context:  3,
+	})
+}
+
+func driftYAML(obj *unstructured.Unstructured) (string, error) {
+	if obj == nil {
+		return "", nil
+	}
+
+	obj = obj.DeepCopy()
+	for _, field := range driftIgnoredMetadataFields {
+		unstructured.RemoveNestedField(obj.Object, "metadata", field)
+	}
+	unstructured.RemoveNestedField(obj.Object, "metadata", "annotations", corev1.LastAppliedConfigAnnotation)
+
+	if len(obj.GetAnnotations()) == 0 {
+		unstructured.RemoveNestedField(obj.Object, "metadata", "annotations")
+	}
+
+	data, err := sigsyaml.Marshal(obj.Object)
+	if err != nil {
+		return "", fmt.Errorf("could not encode %s %s: %w", obj.GetKind(), obj.GetName(), err)
+	}
+
+	return string(data), nil
+}
@@ -0,0 +1,114 @@
+package kubectl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Arneproductions/go-kube/internal/resources"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestObjectDiff(t *testing.T) {
+	t.Run("objectDiff_should_ignore_fields_that_change_with_every_write", func(t *testing.T) {
+		live := mustDecodeManifests(t, configMapDataManifest("foo", "key", "value"))[0]
+		live.SetResourceVersion("1")
+		live.SetAnnotations(map[string]string{"kubectl.kubernetes.io/last-applied-configuration": "{}"})
+
+		applied := mustDecodeManifests(t, configMapDataManifest("foo", "key", "value"))[0]
+		applied.SetResourceVersion("2")
+
+		diff, err := objectDiff("configmap/foo", live, applied)
+		require.NoError(t, err)
+		assert.Empty(t, diff)
+	})
+
+	t.Run("objectDiff_should_return_the_changed_fields", func(t *testing.T) {
+		live := mustDecodeManifests(t, configMapDataManifest("foo", "key", "changed"))[0]
+		applied := mustDecodeManifests(t, configMapDataManifest("foo", "key", "value"))[0]
+
+		diff, err := objectDiff("configmap/foo", live, applied)
+		require.NoError(t, err)
+		assert.Contains(t, diff, "-  key: changed")
+		assert.Contains(t, diff, "+  key: value")
+	})
+}
+
+func TestFailOnDrift(t *testing.T) {
+	c := resources.NewEphemeralCluster()
+	require.NoError(t, c.Start())
+
+	t.Cleanup(func() {
+		require.NoError(t, c.Stop())
+	})
+
+	t.Run("ApplyManifests_should_return_DriftError_when_the_object_was_changed_live", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		name := fmt.Sprintf("test-cm-%s", uuid.New().String())
+		manifest := writeTestManifest(t, configMapDataManifest(name, "foo", "bar"))
+
+		err := ApplyManifests(ctx, c.KubeConfigFilePath(), &ApplyManifestsOptions{}, manifest)
+		require.NoError(t, err)
+
+		t.Cleanup(func() {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			defer cancel()
+
+			_ = c.Client().CoreV1().ConfigMaps("default").Delete(ctx, name, metav1.DeleteOptions{})
+		})
+
+		cm, err := c.Client().CoreV1().ConfigMaps("default").Get(ctx, name, metav1.GetOptions{})
+		require.NoError(t, err)
+
+		cm.Data["foo"] = "drifted"
+		_, err = c.Client().CoreV1().ConfigMaps("default").Update(ctx, cm, metav1.UpdateOptions{})
+		require.NoError(t, err)
+
+		err = ApplyManifests(ctx, c.KubeConfigFilePath(), &ApplyManifestsOptions{FailOnDrift: true}, manifest)
+		require.Error(t, err)
+
+		driftErr := &DriftError{}
+		require.True(t, errors.As(err, &driftErr))
+		assert.Equal(t, []ObjectRef{{Kind: "configmap", Name: name}}, driftErr.Objects)
+		assert.Contains(t, driftErr.Diff, "foo: drifted")
+		assert.Contains(t, driftErr.Diff, "foo: bar")
+
+		cm, err = c.Client().CoreV1().ConfigMaps("default").Get(ctx, name, metav1.GetOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, "drifted", cm.Data["foo"], "the drift check should not apply the manifest")
+	})
+
+	t.Run("ApplyManifests_should_not_return_DriftError_when_the_cluster_matches", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		name := fmt.Sprintf("test-cm-%s", uuid.New().String())
+		manifest := writeTestManifest(t, configMapDataManifest(name, "foo", "bar"))
+
+		err := ApplyManifests(ctx, c.KubeConfigFilePath(), &ApplyManifestsOptions{}, manifest)
+		require.NoError(t, err)
+
+		t.Cleanup(func() {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			defer cancel()
+
+			_ = c.Client().CoreV1().ConfigMaps("default").Delete(ctx, name, metav1.DeleteOptions{})
+		})
+
+		result, err := ApplyManifestsWithResult(ctx, c.KubeConfigFilePath(), &ApplyManifestsOptions{FailOnDrift: true}, manifest)
+		require.NoError(t, err)
+		assert.False(t, result.Changed())
+	})
+}
@@ -0,0 +1,123 @@
+package kubectl
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// objectKey identifies an object as it is defined in a manifest
+type objectKey struct {
+	Group     string
+	Kind      string
+	Namespace string
+	Name      string
+}
+
+func objectKeyOf(obj *unstructured.Unstructured) objectKey {
+	return objectKey{
+		Group:     obj.GroupVersionKind().Group,
+		Kind:      obj.GetKind(),
+		Namespace: obj.GetNamespace(),
+		Name:      obj.GetName(),
+	}
+}
+
+func (k objectKey) String() string {
+	ref := k.Kind
+	if k.Group != "" {
+		ref += "." + k.Group
+	}
+
+	if k.Namespace != "" {
+		return fmt.Sprintf("%s %s/%s", ref, k.Namespace, k.Name)
+	}
+
+	return fmt.Sprintf("%s %s", ref, k.Name)
+}
+
+/*
+dedupeManifests resolves the objects that are defined more than once in the given files with the strategy. When there
+are duplicates to resolve, the objects are written to a temporary manifest file, which is returned instead of the files.
+The returned cleanup function removes the temporary file.
+*/
+func dedupeManifests(strategy DuplicateStrategy, recursive bool, filePaths ...string) ([]string, func(), error) {
+	cleanup := func() {}
+
+	objs, err := readManifests(filePaths, recursive)
+	if err != nil {
+		return nil, cleanup, err
+	}
+
+	deduped, duplicates := dedupeObjects(objs, strategy)
+	if len(duplicates) == 0 {
+		return filePaths, cleanup, nil
+	}
+
+	if strategy == DuplicateStrategyError {
+		keys := []string{}
+		for _, key := range duplicates {
+			keys = append(keys, key.String())
+		}
+
+		return nil, cleanup, fmt.Errorf("objects are defined more than once: %s", strings.Join(keys, "; "))
+	}
+
+	manifestPath, err := writeManifests(deduped)
+	if err != nil {
+		return nil, cleanup, err
+	}
+
+	return []string{manifestPath}, func() { os.Remove(manifestPath) }, nil
+}
+
+/*
+dedupeObjects resolves the objects that are defined more than once with the strategy, keeping every object at the
+position of its first definition. It returns the resolved objects, and the objects that were defined more than once.
+*/
+func dedupeObjects(objs []*unstructured.Unstructured, strategy DuplicateStrategy) ([]*unstructured.Unstructured, []objectKey) {
+	index := map[objectKey]int{}
+	deduped := []*unstructured.Unstructured{}
+	duplicates := []objectKey{}
+
+	for _, obj := range objs {
+		key := objectKeyOf(obj)
+
+		i, ok := index[key]
+		if !ok {
+			index[key] = len(deduped)
+			deduped = append(deduped, obj)
+			continue
+		}
+
+		duplicates = append(duplicates, key)
+
+		switch strategy {
+		case DuplicateStrategyLastWins:
+			deduped[i] = obj
+		case DuplicateStrategyMerge:
+			mergeObject(deduped[i].Object, obj.Object)
+		}
+	}
+
+	return deduped, duplicates
+}
+
+/*
+mergeObject merges src into dst. Nested maps are merged, while any other value of src replaces the value of dst.
+*/
+func mergeObject(dst, src map[string]interface{}) {
+	for key, srcValue := range src {
+		srcMap, srcIsMap := srcValue.(map[string]interface{})
+		dstMap, dstIsMap := dst[key].(map[string]interface{})
+
+		if srcIsMap && dstIsMap {
+			mergeObject(dstMap, srcMap)
+			continue
+		}
+
+		dst[key] = srcValue
+	}
+}
@@ -0,0 +1,103 @@
+package kubectl
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Arneproductions/go-kube/internal/resources"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func configMapDataManifest(name, key, value string) string {
+	return fmt.Sprintf(`
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: %s
+  namespace: default
+data:
+  %s: %s
+`, name, key, value)
+}
+
+func TestDuplicateStrategy(t *testing.T) {
+	c := resources.NewEphemeralCluster()
+	require.NoError(t, c.Start())
+
+	t.Cleanup(func() {
+		require.NoError(t, c.Stop())
+	})
+
+	t.Run("ApplyManifests_should_apply_last_definition_with_DuplicateStrategyLastWins", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		name := fmt.Sprintf("test-cm-%s", uuid.New().String())
+		first := writeTestManifest(t, configMapDataManifest(name, "foo", "first"))
+		second := writeTestManifest(t, configMapDataManifest(name, "bar", "second"))
+
+		err := ApplyManifests(ctx, c.KubeConfigFilePath(), &ApplyManifestsOptions{DuplicateStrategy: DuplicateStrategyLastWins}, first, second)
+		require.NoError(t, err)
+
+		t.Cleanup(func() {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			defer cancel()
+
+			_ = c.Client().CoreV1().ConfigMaps("default").Delete(ctx, name, metav1.DeleteOptions{})
+		})
+
+		cm, err := c.Client().CoreV1().ConfigMaps("default").Get(ctx, name, metav1.GetOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{"bar": "second"}, cm.Data)
+	})
+
+	t.Run("ApplyManifests_should_reject_duplicates_by_default", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		name := fmt.Sprintf("test-cm-%s", uuid.New().String())
+		first := writeTestManifest(t, configMapDataManifest(name, "foo", "first"))
+		second := writeTestManifest(t, configMapDataManifest(name, "bar", "second"))
+
+		err := ApplyManifests(ctx, c.KubeConfigFilePath(), &ApplyManifestsOptions{}, first, second)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "ConfigMap default/"+name)
+	})
+}
+
+func TestDedupeObjects(t *testing.T) {
+	t.Run("dedupeObjects_should_merge_definitions_with_DuplicateStrategyMerge", func(t *testing.T) {
+		objs := mustDecodeManifests(t, configMapDataManifest("merged", "foo", "first")+"---"+
+			configMapDataManifest("other", "baz", "other")+"---"+
+			configMapDataManifest("merged", "bar", "second"))
+
+		deduped, duplicates := dedupeObjects(objs, DuplicateStrategyMerge)
+		require.Len(t, deduped, 2)
+		require.Len(t, duplicates, 1)
+
+		assert.Equal(t, "merged", deduped[0].GetName())
+		assert.Equal(t, map[string]interface{}{"foo": "first", "bar": "second"}, deduped[0].Object["data"])
+		assert.Equal(t, "other", deduped[1].GetName())
+	})
+
+	t.Run("dedupeObjects_should_tell_namespaces_apart", func(t *testing.T) {
+		objs := mustDecodeManifests(t, configMapDataManifest("same", "foo", "bar")+`
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: same
+  namespace: other
+`)
+
+		deduped, duplicates := dedupeObjects(objs, DuplicateStrategyError)
+		assert.Len(t, deduped, 2)
+		assert.Empty(t, duplicates)
+	})
+}
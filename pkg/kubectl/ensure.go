@@ -0,0 +1,92 @@
+package kubectl
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+/*
+EnsureExists applies the object in the manifest to the cluster that the kubeconfigPath points to, only if the object does
+not exist already. An existing object is left as it is. It reports whether the object was created.
+
+Example:
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	created, err := EnsureExists(ctx, "/path/to/kubeconfig", &ApplyManifestsOptions{}, []byte(`
+	apiVersion: v1
+	kind: Namespace
+	metadata:
+	  name: my-namespace
+	`))
+	if err != nil {
+		// Handle error
+	}
+*/
+func EnsureExists(ctx context.Context, kubeconfigPath string, opts *ApplyManifestsOptions, manifest []byte) (bool, error) {
+	if kubeconfigPath == "" {
+		return false, fmt.Errorf("kubeconfig path cannot be empty")
+	}
+
+	if opts == nil {
+		return false, fmt.Errorf("options cannot be nil")
+	}
+
+	objs, err := decodeManifests(manifest, "manifest")
+	if err != nil {
+		return false, err
+	}
+
+	if len(objs) != 1 {
+		return false, fmt.Errorf("manifest must contain exactly one object, found %d", len(objs))
+	}
+	obj := objs[0]
+
+	f := newFactory(kubeconfigPath)
+
+	dynamicClient, err := f.DynamicClient()
+	if err != nil {
+		return false, fmt.Errorf("could not create dynamic client: %w", err)
+	}
+
+	mapper, err := f.ToRESTMapper()
+	if err != nil {
+		return false, fmt.Errorf("could not create rest mapper: %w", err)
+	}
+
+	namespace, _, err := f.ToRawKubeConfigLoader().Namespace()
+	if err != nil {
+		return false, fmt.Errorf("could not determine default namespace: %w", err)
+	}
+
+	client, err := objectClient(dynamicClient, mapper, namespace, obj)
+	if err != nil {
+		return false, err
+	}
+
+	_, err = client.Get(ctx, obj.GetName(), metav1.GetOptions{})
+	if err == nil {
+		return false, nil
+	}
+	if !apierrors.IsNotFound(err) {
+		return false, fmt.Errorf("could not get %s %s: %w", obj.GetKind(), obj.GetName(), err)
+	}
+
+	manifestPath, err := writeManifests(objs)
+	if err != nil {
+		return false, err
+	}
+	defer os.Remove(manifestPath)
+
+	err = ApplyManifests(ctx, kubeconfigPath, opts, manifestPath)
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
@@ -0,0 +1,61 @@
+package kubectl
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Arneproductions/go-kube/internal/resources"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestEnsureExists(t *testing.T) {
+	c := resources.NewEphemeralCluster()
+	require.NoError(t, c.Start())
+
+	t.Cleanup(func() {
+		require.NoError(t, c.Stop())
+	})
+
+	t.Run("EnsureExists_should_only_create_missing_object", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		name := fmt.Sprintf("test-cm-%s", uuid.New().String())
+
+		created, err := EnsureExists(ctx, c.KubeConfigFilePath(), &ApplyManifestsOptions{}, []byte(labelledConfigMapManifest(name, "first")))
+		require.NoError(t, err)
+		assert.True(t, created)
+
+		t.Cleanup(func() {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			defer cancel()
+
+			_ = c.Client().CoreV1().ConfigMaps("default").Delete(ctx, name, metav1.DeleteOptions{})
+		})
+
+		created, err = EnsureExists(ctx, c.KubeConfigFilePath(), &ApplyManifestsOptions{}, []byte(labelledConfigMapManifest(name, "second")))
+		require.NoError(t, err)
+		assert.False(t, created)
+
+		// The existing object is left alone
+		cm, err := c.Client().CoreV1().ConfigMaps("default").Get(ctx, name, metav1.GetOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, "first", cm.Labels["test"])
+	})
+
+	t.Run("EnsureExists_should_reject_manifests_with_several_objects", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		manifest := labelledConfigMapManifest("first", "first") + "---" + labelledConfigMapManifest("second", "second")
+
+		_, err := EnsureExists(ctx, c.KubeConfigFilePath(), &ApplyManifestsOptions{}, []byte(manifest))
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,142 @@
+package kubectl
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+type EventsOptions struct {
+	/*
+		Only return events of the given type, i.e. corev1.EventTypeWarning or corev1.EventTypeNormal. Events of every
+		type are returned when empty
+	*/
+	TypeFilter string
+}
+
+// Event is an event that was recorded about an object in the cluster
+type Event struct {
+	// The object that the event is about
+	Object ObjectRef
+	// The type of the event, i.e. Normal or Warning
+	Type    string
+	Reason  string
+	Message string
+	// The component that recorded the event, e.g. default-scheduler
+	Source string
+	// How many times the event occurred
+	Count     int32
+	FirstSeen time.Time
+	LastSeen  time.Time
+}
+
+/*
+NamespaceEvents returns the events of every object in the namespace of the cluster that the kubeconfigPath points to,
+sorted by when they were last seen, the most recent first.
+
+Example:
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	events, err := NamespaceEvents(ctx, "/path/to/kubeconfig", "default", &EventsOptions{TypeFilter: corev1.EventTypeWarning})
+	if err != nil {
+		// Handle error
+	}
+*/
+func NamespaceEvents(ctx context.Context, kubeconfigPath string, namespace string, opts *EventsOptions) ([]Event, error) {
+	if kubeconfigPath == "" {
+		return nil, fmt.Errorf("kubeconfig path cannot be empty")
+	}
+
+	if opts == nil {
+		return nil, fmt.Errorf("options cannot be nil")
+	}
+
+	if opts.TypeFilter != "" && opts.TypeFilter != corev1.EventTypeNormal && opts.TypeFilter != corev1.EventTypeWarning {
+		return nil, fmt.Errorf("unsupported event type %s", opts.TypeFilter)
+	}
+
+	clientset, err := newFactory(kubeconfigPath).KubernetesClientSet()
+	if err != nil {
+		return nil, fmt.Errorf("could not create clientset: %w", err)
+	}
+
+	listOpts := metav1.ListOptions{}
+	if opts.TypeFilter != "" {
+		listOpts.FieldSelector = fields.OneTermEqualSelector("type", opts.TypeFilter).String()
+	}
+
+	list, err := clientset.CoreV1().Events(namespace).List(ctx, listOpts)
+	if err != nil {
+		return nil, fmt.Errorf("could not list events in namespace %s: %w", namespace, err)
+	}
+
+	events := []Event{}
+	for _, event := range list.Items {
+		events = append(events, eventOf(event))
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].LastSeen.After(events[j].LastSeen)
+	})
+
+	return events, nil
+}
+
+/*
+eventOf converts the core event, falling back to the timestamps that are set when the event was recorded through the
+events.k8s.io API.
+*/
+func eventOf(event corev1.Event) Event {
+	firstSeen := event.FirstTimestamp.Time
+	if firstSeen.IsZero() {
+		firstSeen = event.EventTime.Time
+	}
+	if firstSeen.IsZero() {
+		firstSeen = event.CreationTimestamp.Time
+	}
+
+	lastSeen := event.LastTimestamp.Time
+	if event.Series != nil {
+		lastSeen = event.Series.LastObservedTime.Time
+	}
+	if lastSeen.IsZero() {
+		lastSeen = firstSeen
+	}
+
+	count := event.Count
+	if event.Series != nil {
+		count = event.Series.Count
+	}
+	if count == 0 {
+		count = 1
+	}
+
+	source := event.Source.Component
+	if source == "" {
+		source = event.ReportingController
+	}
+
+	return Event{
+		Object: ObjectRef{
+			Group: schema.FromAPIVersionAndKind(event.InvolvedObject.APIVersion, event.InvolvedObject.Kind).Group,
+			Kind:  strings.ToLower(event.InvolvedObject.Kind),
+			Name:  event.InvolvedObject.Name,
+		},
+		Type:      event.Type,
+		Reason:    event.Reason,
+		Message:   event.Message,
+		Source:    source,
+		Count:     count,
+		FirstSeen: firstSeen,
+		LastSeen:  lastSeen,
+	}
+}
@@ -0,0 +1,112 @@
+package kubectl
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Arneproductions/go-kube/internal/resources"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// unschedulablePodManifest returns a pod that no node can run, as it selects a node label that no node has
+func unschedulablePodManifest(name string) string {
+	return fmt.Sprintf(`
+apiVersion: v1
+kind: Pod
+metadata:
+  name: %s
+  namespace: default
+spec:
+  nodeSelector:
+    test.go-kube.io/nonexistent: "true"
+  containers:
+  - name: pause
+    image: registry.k8s.io/pause:3.9
+`, name)
+}
+
+func TestEventOf(t *testing.T) {
+	t.Run("eventOf_should_fall_back_to_the_event_series", func(t *testing.T) {
+		first := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		last := first.Add(time.Minute)
+
+		event := eventOf(corev1.Event{
+			InvolvedObject: corev1.ObjectReference{APIVersion: "apps/v1", Kind: "Deployment", Name: "nginx"},
+			Type:           corev1.EventTypeNormal,
+			EventTime:      metav1.NewMicroTime(first),
+			Series:         &corev1.EventSeries{Count: 3, LastObservedTime: metav1.NewMicroTime(last)},
+		})
+
+		assert.Equal(t, ObjectRef{Group: "apps", Kind: "deployment", Name: "nginx"}, event.Object)
+		assert.Equal(t, first, event.FirstSeen)
+		assert.Equal(t, last, event.LastSeen)
+		assert.Equal(t, int32(3), event.Count)
+	})
+}
+
+func TestNamespaceEvents(t *testing.T) {
+	c := resources.NewEphemeralCluster()
+	require.NoError(t, c.Start())
+
+	t.Cleanup(func() {
+		require.NoError(t, c.Stop())
+	})
+
+	t.Run("NamespaceEvents_should_return_the_warnings_of_an_unschedulable_pod", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+		defer cancel()
+
+		name := fmt.Sprintf("test-pod-%s", uuid.New().String())
+
+		err := ApplyManifests(ctx, c.KubeConfigFilePath(), &ApplyManifestsOptions{}, writeTestManifest(t, unschedulablePodManifest(name)))
+		require.NoError(t, err)
+
+		t.Cleanup(func() {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			defer cancel()
+
+			_ = c.Client().CoreV1().Pods("default").Delete(ctx, name, metav1.DeleteOptions{})
+		})
+
+		var events []Event
+		err = wait.PollUntilContextCancel(ctx, time.Second, true, func(ctx context.Context) (bool, error) {
+			events, err = NamespaceEvents(ctx, c.KubeConfigFilePath(), "default", &EventsOptions{TypeFilter: corev1.EventTypeWarning})
+			if err != nil {
+				return false, err
+			}
+
+			for _, event := range events {
+				if event.Object.Kind == "pod" && event.Object.Name == name && event.Reason == "FailedScheduling" {
+					return true, nil
+				}
+			}
+
+			return false, nil
+		})
+		require.NoError(t, err)
+
+		for i, event := range events {
+			assert.Equal(t, corev1.EventTypeWarning, event.Type)
+
+			if i > 0 {
+				assert.False(t, event.LastSeen.After(events[i-1].LastSeen), "events should be sorted by last seen, most recent first")
+			}
+		}
+	})
+
+	t.Run("NamespaceEvents_should_reject_unsupported_event_types", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		_, err := NamespaceEvents(ctx, c.KubeConfigFilePath(), "default", &EventsOptions{TypeFilter: "Critical"})
+		assert.Error(t, err)
+	})
+}
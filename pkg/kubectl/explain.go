@@ -0,0 +1,96 @@
+package kubectl
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/kubectl/pkg/cmd/explain"
+)
+
+type ExplainOptions struct {
+	/*
+		Lists the names of every nested field instead of the documentation of the fields i.e. kubectl explain --recursive
+	*/
+	Recursive bool
+	/*
+		The group/version of the resource to explain, the preferred version of the cluster is used when empty
+		i.e. kubectl explain --api-version
+	*/
+	APIVersion string
+}
+
+/*
+Explain returns the documentation of the resource or field of the cluster that the kubeconfigPath points to
+i.e. kubectl explain RESOURCE[.FIELD].
+
+Example:
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	doc, err := Explain(ctx, "/path/to/kubeconfig", "deployment.spec", &ExplainOptions{})
+	if err != nil {
+		// Handle error
+	}
+*/
+func Explain(ctx context.Context, kubeconfigPath string, resourceType string, opts *ExplainOptions) (string, error) {
+	// Like the apply command, the explain command may encounter a fatal error which
+	// changes global behaviour
+	lock, err := lockKubectl(ctx, "kubectl explain")
+	if err != nil {
+		return "", err
+	}
+	defer lock.unlock()
+
+	if kubeconfigPath == "" {
+		return "", fmt.Errorf("kubeconfig path cannot be empty")
+	}
+
+	if opts == nil {
+		return "", fmt.Errorf("options cannot be nil")
+	}
+
+	if resourceType == "" {
+		return "", fmt.Errorf("resource type cannot be empty")
+	}
+
+	ioStreams, streamOut, streamErr := newIOStreams()
+
+	errChan := newErrChan()
+
+	// Send an error to the error channel when the context is cancelled or its deadline is reached
+	operation := fmt.Sprintf("kubectl explain of %s", resourceType)
+	timeLeft, stopWatching := watchContext(ctx, operation, errChan, streamOut, streamErr)
+	defer stopWatching()
+
+	// The deadline may have passed while we waited for the kubectl lock
+	if err := expiredError(operation, timeLeft, streamOut, streamErr); err != nil {
+		return "", err
+	}
+
+	f := newCommandFactory(kubeconfigPath, "", timeLeft)
+
+	explainCmd := explain.NewCmdExplain("kubectl", f, ioStreams)
+
+	if opts.Recursive {
+		explainCmd.Flags().Set("recursive", "true")
+	}
+
+	if opts.APIVersion != "" {
+		explainCmd.Flags().Set("api-version", opts.APIVersion)
+	}
+
+	lock.run(errChan, streamOut, streamErr, func() error {
+		// explainCmd is blocking. Should it fail it should have called the fatal error handler which
+		// run overrides to send an error to errChan
+		explainCmd.Run(explainCmd, []string{resourceType})
+		return nil
+	})
+
+	err = <-errChan
+	if err != nil {
+		return "", err
+	}
+
+	return streamOut.String(), nil
+}
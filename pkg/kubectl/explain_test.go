@@ -0,0 +1,48 @@
+package kubectl
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Arneproductions/go-kube/internal/resources"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExplain(t *testing.T) {
+	c := resources.NewEphemeralCluster()
+	require.NoError(t, c.Start())
+
+	t.Cleanup(func() {
+		require.NoError(t, c.Stop())
+	})
+
+	t.Run("Explain_should_return_field_documentation", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		doc, err := Explain(ctx, c.KubeConfigFilePath(), "deployment.spec", &ExplainOptions{})
+		require.NoError(t, err)
+
+		assert.Contains(t, doc, "replicas")
+	})
+
+	t.Run("Explain_should_list_nested_fields_recursively", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		doc, err := Explain(ctx, c.KubeConfigFilePath(), "deployment.spec", &ExplainOptions{Recursive: true, APIVersion: "apps/v1"})
+		require.NoError(t, err)
+
+		assert.Contains(t, doc, "maxSurge")
+	})
+
+	t.Run("Explain_should_fail_on_unknown_resources", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		_, err := Explain(ctx, c.KubeConfigFilePath(), "doesnotexist", &ExplainOptions{})
+		assert.Error(t, err)
+	})
+}
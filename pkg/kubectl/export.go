@@ -0,0 +1,145 @@
+package kubectl
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+var (
+	// The namespaced resources that are exported, in the order they can be re-applied in
+	exportResources = []schema.GroupVersionResource{
+		{Group: "", Version: "v1", Resource: "serviceaccounts"},
+		{Group: "", Version: "v1", Resource: "secrets"},
+		{Group: "", Version: "v1", Resource: "configmaps"},
+		{Group: "", Version: "v1", Resource: "persistentvolumeclaims"},
+		{Group: "rbac.authorization.k8s.io", Version: "v1", Resource: "roles"},
+		{Group: "rbac.authorization.k8s.io", Version: "v1", Resource: "rolebindings"},
+		{Group: "", Version: "v1", Resource: "services"},
+		{Group: "apps", Version: "v1", Resource: "deployments"},
+		{Group: "apps", Version: "v1", Resource: "statefulsets"},
+		{Group: "apps", Version: "v1", Resource: "daemonsets"},
+		{Group: "batch", Version: "v1", Resource: "jobs"},
+		{Group: "batch", Version: "v1", Resource: "cronjobs"},
+		{Group: "networking.k8s.io", Version: "v1", Resource: "ingresses"},
+		{Group: "networking.k8s.io", Version: "v1", Resource: "networkpolicies"},
+	}
+
+	// The metadata fields that are set by the API server, and cannot be re-applied
+	serverManagedMetadataFields = []string{
+		"resourceVersion",
+		"uid",
+		"managedFields",
+		"creationTimestamp",
+		"generation",
+		"selfLink",
+		"ownerReferences",
+	}
+)
+
+/*
+ExportNamespace writes the resources in the namespace of the cluster that the kubeconfigPath points to as multi-document
+YAML to w. The fields managed by the API server are stripped, such that the output can be re-applied. Objects created
+by a controller, e.g. the ReplicaSets of a Deployment, are left out as the controller recreates them.
+
+Example:
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	f, err := os.Create("/path/to/backup.yaml")
+	if err != nil {
+		// Handle error
+	}
+	defer f.Close()
+
+	err = ExportNamespace(ctx, "/path/to/kubeconfig", "my-namespace", f)
+	if err != nil {
+		// Handle error
+	}
+*/
+func ExportNamespace(ctx context.Context, kubeconfigPath string, namespace string, w io.Writer) error {
+	if kubeconfigPath == "" {
+		return fmt.Errorf("kubeconfig path cannot be empty")
+	}
+
+	if namespace == "" {
+		return fmt.Errorf("namespace cannot be empty")
+	}
+
+	dynamicClient, err := newFactory(kubeconfigPath).DynamicClient()
+	if err != nil {
+		return fmt.Errorf("could not create dynamic client: %w", err)
+	}
+
+	objs, err := exportNamespaceObjects(ctx, dynamicClient, namespace)
+	if err != nil {
+		return err
+	}
+
+	data, err := encodeManifests(objs)
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(data)
+	if err != nil {
+		return fmt.Errorf("could not write export of namespace %s: %w", namespace, err)
+	}
+
+	return nil
+}
+
+/*
+exportNamespaceObjects lists the exported resources in the namespace, stripped of their server-managed fields.
+*/
+func exportNamespaceObjects(ctx context.Context, dynamicClient dynamic.Interface, namespace string) ([]*unstructured.Unstructured, error) {
+	objs := []*unstructured.Unstructured{}
+
+	for _, gvr := range exportResources {
+		list, err := dynamicClient.Resource(gvr).Namespace(namespace).List(ctx, metav1.ListOptions{})
+		if apierrors.IsNotFound(err) {
+			// The resource is not served by the cluster
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("could not list %s in namespace %s: %w", gvr.Resource, namespace, err)
+		}
+
+		for i := range list.Items {
+			obj := &list.Items[i]
+
+			if metav1.GetControllerOf(obj) != nil {
+				continue
+			}
+
+			stripServerFields(obj)
+			objs = append(objs, obj)
+		}
+	}
+
+	return objs, nil
+}
+
+/*
+stripServerFields removes the fields of the object that are set by the API server.
+*/
+func stripServerFields(obj *unstructured.Unstructured) {
+	for _, field := range serverManagedMetadataFields {
+		unstructured.RemoveNestedField(obj.Object, "metadata", field)
+	}
+
+	unstructured.RemoveNestedField(obj.Object, "status")
+
+	// The cluster IPs of services are allocated by the API server, and may be taken when re-applied
+	if obj.GetKind() == "Service" && obj.GetAPIVersion() == "v1" {
+		unstructured.RemoveNestedField(obj.Object, "spec", "clusterIP")
+		unstructured.RemoveNestedField(obj.Object, "spec", "clusterIPs")
+	}
+}
@@ -0,0 +1,95 @@
+package kubectl
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Arneproductions/go-kube/internal/resources"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestExportNamespace(t *testing.T) {
+	c := resources.NewEphemeralCluster()
+	require.NoError(t, c.Start())
+
+	t.Cleanup(func() {
+		require.NoError(t, c.Stop())
+	})
+
+	t.Run("ExportNamespace_should_export_resources_without_server_fields", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+		defer cancel()
+
+		namespace := fmt.Sprintf("test-ns-%s", uuid.New().String())
+		manifest := writeTestManifest(t, namespaceManifest(namespace)+fmt.Sprintf(`
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: exported-config
+  namespace: %[1]s
+data:
+  foo: bar
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: exported-deployment
+  namespace: %[1]s
+spec:
+  replicas: 1
+  selector:
+    matchLabels:
+      app: exported-deployment
+  template:
+    metadata:
+      labels:
+        app: exported-deployment
+    spec:
+      containers:
+      - name: app
+        image: busybox:1.36
+        command: ["sleep", "3600"]
+`, namespace))
+
+		err := ApplyManifests(ctx, c.KubeConfigFilePath(), &ApplyManifestsOptions{}, manifest)
+		require.NoError(t, err)
+
+		t.Cleanup(func() {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			defer cancel()
+
+			_ = c.Client().CoreV1().Namespaces().Delete(ctx, namespace, metav1.DeleteOptions{})
+		})
+
+		out := &bytes.Buffer{}
+		err = ExportNamespace(ctx, c.KubeConfigFilePath(), namespace, out)
+		require.NoError(t, err)
+
+		objs, err := decodeManifests(out.Bytes(), "export")
+		require.NoError(t, err)
+
+		exported := map[string]*unstructured.Unstructured{}
+		for _, obj := range objs {
+			exported[obj.GetKind()+"/"+obj.GetName()] = obj
+		}
+
+		require.Contains(t, exported, "ConfigMap/exported-config")
+		require.Contains(t, exported, "Deployment/exported-deployment")
+
+		for _, obj := range objs {
+			assert.Empty(t, obj.GetResourceVersion())
+			assert.Empty(t, obj.GetUID())
+			assert.Empty(t, obj.GetManagedFields())
+			assert.NotContains(t, obj.Object, "status")
+		}
+	})
+}
@@ -0,0 +1,66 @@
+package kubectl
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/kubectl/pkg/cmd/util"
+)
+
+/*
+newFactory creates a kubectl factory for the cluster that the kubeconfigPath points to.
+*/
+func newFactory(kubeconfigPath string) util.Factory {
+	return newNamespacedFactory(kubeconfigPath, "")
+}
+
+/*
+newNamespacedFactory creates a kubectl factory like newFactory, where the namespace is used instead of the
+namespace of the current context i.e. kubectl --namespace. An empty namespace keeps the namespace of the context.
+*/
+func newNamespacedFactory(kubeconfigPath string, namespace string) util.Factory {
+	return util.NewFactory(newConfigFlags(kubeconfigPath, namespace))
+}
+
+/*
+newCommandFactory creates a kubectl factory like newNamespacedFactory for a kubectl command with the given time left,
+whose requests to the server give up once the time is up, see withRequestTimeout.
+*/
+func newCommandFactory(kubeconfigPath string, namespace string, timeLeft time.Duration) util.Factory {
+	return util.NewFactory(withRequestTimeout(newConfigFlags(kubeconfigPath, namespace), timeLeft))
+}
+
+/*
+withRequestTimeout sets the request timeout of the configuration flags to the time left of a kubectl command i.e.
+kubectl --request-timeout. A command that timed out keeps running until kubectl gives up on it, and holds the kubectl
+lock until then, see commandLock.run. The timeout is rounded up to whole seconds and is at least one second, as a
+timeout of 0 disables it. Commands check that time is left before they create a factory, see expiredError.
+*/
+func withRequestTimeout(config *genericclioptions.ConfigFlags, timeLeft time.Duration) *genericclioptions.ConfigFlags {
+	timeout := fmt.Sprintf("%ds", max(1, int(math.Ceil(timeLeft.Seconds()))))
+	config.Timeout = &timeout
+
+	return config
+}
+
+/*
+newConfigFlags creates the kubectl configuration flags that the factories are created from, for callers that
+need to change the client configuration before creating a factory.
+*/
+func newConfigFlags(kubeconfigPath string, namespace string) *genericclioptions.ConfigFlags {
+	config := genericclioptions.
+		NewConfigFlags(true).
+		WithDeprecatedPasswordFlag().
+		WithDiscoveryBurst(300).
+		WithDiscoveryQPS(50.0)
+
+	config.KubeConfig = &kubeconfigPath
+
+	if namespace != "" {
+		config.Namespace = &namespace
+	}
+
+	return config
+}
@@ -0,0 +1,147 @@
+package kubectl
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic"
+)
+
+// terminatingObject is a deleted object that is still held back by its finalizers
+type terminatingObject struct {
+	client dynamic.ResourceInterface
+	obj    *unstructured.Unstructured
+}
+
+/*
+awaitFinalizers waits up to the timeout for the deleted objects of the given files to be gone. The finalizers of the
+objects that are still terminating afterwards are removed when force is set, otherwise an error describes them. A
+timeout of 0 does not wait at all.
+*/
+func awaitFinalizers(ctx context.Context, kubeconfigPath string, timeout time.Duration, force bool, recursive bool, filePaths ...string) error {
+	objs, err := readManifests(filePaths, recursive)
+	if err != nil {
+		return err
+	}
+
+	f := newFactory(kubeconfigPath)
+
+	dynamicClient, err := f.DynamicClient()
+	if err != nil {
+		return fmt.Errorf("could not create dynamic client: %w", err)
+	}
+
+	mapper, err := f.ToRESTMapper()
+	if err != nil {
+		return fmt.Errorf("could not create rest mapper: %w", err)
+	}
+
+	namespace, _, err := f.ToRawKubeConfigLoader().Namespace()
+	if err != nil {
+		return fmt.Errorf("could not determine default namespace: %w", err)
+	}
+
+	clients := []dynamic.ResourceInterface{}
+	for _, obj := range objs {
+		client, err := objectClient(dynamicClient, mapper, namespace, obj)
+		if err != nil {
+			return err
+		}
+
+		clients = append(clients, client)
+	}
+
+	var terminating []terminatingObject
+
+	if timeout > 0 {
+		// The finalizers get the timeout to finish, the wait is cut short when the context is done before then
+		waitCtx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+
+		err = wait.PollUntilContextCancel(waitCtx, time.Second, true, func(ctx context.Context) (bool, error) {
+			terminating, err = terminatingObjects(ctx, clients, objs)
+			if err != nil {
+				return false, err
+			}
+
+			return len(terminating) == 0, nil
+		})
+		if err == nil {
+			return nil
+		}
+		if ctx.Err() != nil || terminating == nil {
+			return fmt.Errorf("could not wait for the deleted objects to be gone: %w", err)
+		}
+	} else {
+		// Without a timeout, the finalizers of the objects that are terminating are removed right away
+		terminating, err = terminatingObjects(ctx, clients, objs)
+		if err != nil {
+			return err
+		}
+		if len(terminating) == 0 {
+			return nil
+		}
+	}
+
+	stuck := []string{}
+	for _, t := range terminating {
+		stuck = append(stuck, fmt.Sprintf("%s %s [%s]", t.obj.GetKind(), t.obj.GetName(), strings.Join(t.obj.GetFinalizers(), ", ")))
+	}
+
+	if !force {
+		return fmt.Errorf("objects are still terminating after %s, held back by their finalizers: %s", timeout, strings.Join(stuck, "; "))
+	}
+
+	patch := []byte(`{"metadata":{"finalizers":null}}`)
+	for _, t := range terminating {
+		_, err := t.client.Patch(ctx, t.obj.GetName(), types.MergePatchType, patch, metav1.PatchOptions{FieldManager: FieldManager})
+		if err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("could not remove finalizers of %s %s: %w", t.obj.GetKind(), t.obj.GetName(), err)
+		}
+	}
+
+	err = wait.PollUntilContextCancel(ctx, time.Second, true, func(ctx context.Context) (bool, error) {
+		terminating, err = terminatingObjects(ctx, clients, objs)
+		if err != nil {
+			return false, err
+		}
+
+		return len(terminating) == 0, nil
+	})
+	if err != nil {
+		return fmt.Errorf("objects are still terminating after removing their finalizers: %s: %w", strings.Join(stuck, "; "), err)
+	}
+
+	return nil
+}
+
+/*
+terminatingObjects returns the live objects that are marked for deletion but still exist. Objects that are not marked
+for deletion, e.g. when they were skipped by the delete, are left out.
+*/
+func terminatingObjects(ctx context.Context, clients []dynamic.ResourceInterface, objs []*unstructured.Unstructured) ([]terminatingObject, error) {
+	terminating := []terminatingObject{}
+
+	for i, obj := range objs {
+		live, err := clients[i].Get(ctx, obj.GetName(), metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("could not get %s %s: %w", obj.GetKind(), obj.GetName(), err)
+		}
+
+		if live.GetDeletionTimestamp() != nil {
+			terminating = append(terminating, terminatingObject{client: clients[i], obj: live})
+		}
+	}
+
+	return terminating, nil
+}
@@ -0,0 +1,99 @@
+package kubectl
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Arneproductions/go-kube/internal/resources"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestFinalizers(t *testing.T) {
+	c := resources.NewEphemeralCluster()
+	require.NoError(t, c.Start())
+
+	t.Cleanup(func() {
+		require.NoError(t, c.Stop())
+	})
+
+	t.Run("DeleteManifests_should_force_remove_pending_finalizers", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		name := uuid.New().String()
+		manifest := writeTestManifest(t, finalizedConfigMapManifest(name))
+
+		require.NoError(t, ApplyManifests(ctx, c.KubeConfigFilePath(), nil, manifest))
+
+		err := DeleteManifestsWithOptions(ctx, c.KubeConfigFilePath(), &DeleteManifestsOptions{
+			FinalizerTimeout:      2 * time.Second,
+			ForceRemoveFinalizers: true,
+		}, manifest)
+		require.NoError(t, err)
+
+		_, err = c.Client().CoreV1().ConfigMaps("default").Get(ctx, name, metav1.GetOptions{})
+		assert.True(t, apierrors.IsNotFound(err), "the config map should be deleted")
+	})
+
+	t.Run("DeleteManifests_should_force_remove_finalizers_right_away_without_a_timeout", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		name := uuid.New().String()
+		manifest := writeTestManifest(t, finalizedConfigMapManifest(name))
+
+		require.NoError(t, ApplyManifests(ctx, c.KubeConfigFilePath(), nil, manifest))
+
+		err := DeleteManifestsWithOptions(ctx, c.KubeConfigFilePath(), &DeleteManifestsOptions{ForceRemoveFinalizers: true}, manifest)
+		require.NoError(t, err)
+
+		_, err = c.Client().CoreV1().ConfigMaps("default").Get(ctx, name, metav1.GetOptions{})
+		assert.True(t, apierrors.IsNotFound(err), "the config map should be deleted")
+	})
+
+	t.Run("DeleteManifests_should_error_on_pending_finalizers_without_ForceRemoveFinalizers", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		name := uuid.New().String()
+		manifest := writeTestManifest(t, finalizedConfigMapManifest(name))
+
+		require.NoError(t, ApplyManifests(ctx, c.KubeConfigFilePath(), nil, manifest))
+
+		t.Cleanup(func() {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			defer cancel()
+
+			patch := []byte(`{"metadata":{"finalizers":null}}`)
+			_, _ = c.Client().CoreV1().ConfigMaps("default").Patch(ctx, name, types.MergePatchType, patch, metav1.PatchOptions{})
+		})
+
+		err := DeleteManifestsWithOptions(ctx, c.KubeConfigFilePath(), &DeleteManifestsOptions{FinalizerTimeout: 2 * time.Second}, manifest)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "test.go-kube.io/stuck")
+
+		cm, err := c.Client().CoreV1().ConfigMaps("default").Get(ctx, name, metav1.GetOptions{})
+		require.NoError(t, err, "the config map should still be terminating")
+		assert.NotNil(t, cm.DeletionTimestamp)
+	})
+}
+
+func finalizedConfigMapManifest(name string) string {
+	return fmt.Sprintf(`apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: %s
+  namespace: default
+  finalizers:
+  - test.go-kube.io/stuck
+data:
+  foo: bar
+`, name)
+}
@@ -0,0 +1,77 @@
+package kubectl
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+/*
+WaitForObservedGeneration waits until the controller of the object in the namespace of the cluster that the kubeconfigPath
+points to has observed its latest generation, i.e. status.observedGeneration is at least metadata.generation, or the
+context is done. Until then, the status of the object may still describe the previous generation. The resourceType is in
+any of the forms kubectl accepts, e.g. deployment, deploy or deployments.apps.
+
+Example:
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	err := WaitForObservedGeneration(ctx, "/path/to/kubeconfig", "deployment", "nginx", "default")
+	if err != nil {
+		// Handle error
+	}
+*/
+func WaitForObservedGeneration(ctx context.Context, kubeconfigPath string, resourceType, name, namespace string) error {
+	if kubeconfigPath == "" {
+		return fmt.Errorf("kubeconfig path cannot be empty")
+	}
+
+	if resourceType == "" || name == "" {
+		return fmt.Errorf("resource type and name cannot be empty")
+	}
+
+	f := newFactory(kubeconfigPath)
+
+	dynamicClient, err := f.DynamicClient()
+	if err != nil {
+		return fmt.Errorf("could not create dynamic client: %w", err)
+	}
+
+	mapper, err := f.ToRESTMapper()
+	if err != nil {
+		return fmt.Errorf("could not create rest mapper: %w", err)
+	}
+
+	gvr, err := resolveResource(mapper, resourceType, "")
+	if err != nil {
+		return err
+	}
+
+	client := dynamicClient.Resource(gvr).Namespace(namespace)
+	generation, observedGeneration := int64(0), int64(0)
+
+	err = wait.PollUntilContextCancel(ctx, time.Second, true, func(ctx context.Context) (bool, error) {
+		obj, err := client.Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return false, fmt.Errorf("could not get %s %s: %w", resourceType, name, err)
+		}
+
+		generation = obj.GetGeneration()
+		observedGeneration, _, err = unstructured.NestedInt64(obj.Object, "status", "observedGeneration")
+		if err != nil {
+			return false, fmt.Errorf("could not read observed generation of %s %s: %w", resourceType, name, err)
+		}
+
+		return generationObserved(obj), nil
+	})
+	if err != nil {
+		return fmt.Errorf("%s %s has observed generation %d, want %d: %w", resourceType, name, observedGeneration, generation, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,61 @@
+package kubectl
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Arneproductions/go-kube/internal/resources"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestWaitForObservedGeneration(t *testing.T) {
+	c := resources.NewEphemeralCluster()
+	require.NoError(t, c.Start())
+
+	t.Cleanup(func() {
+		require.NoError(t, c.Stop())
+	})
+
+	t.Run("WaitForObservedGeneration_should_return_after_the_update_is_observed", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		name := fmt.Sprintf("test-deploy-%s", uuid.New().String())
+
+		err := ApplyManifests(ctx, c.KubeConfigFilePath(), &ApplyManifestsOptions{}, writeTestManifest(t, loggingDeploymentManifest(name, 1)))
+		require.NoError(t, err)
+
+		t.Cleanup(func() {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			defer cancel()
+
+			_ = c.Client().AppsV1().Deployments("default").Delete(ctx, name, metav1.DeleteOptions{})
+		})
+
+		// Changing the replicas starts a new generation of the deployment
+		err = ApplyManifests(ctx, c.KubeConfigFilePath(), &ApplyManifestsOptions{}, writeTestManifest(t, loggingDeploymentManifest(name, 2)))
+		require.NoError(t, err)
+
+		err = WaitForObservedGeneration(ctx, c.KubeConfigFilePath(), "deployment", name, "default")
+		require.NoError(t, err)
+
+		deployment, err := c.Client().AppsV1().Deployments("default").Get(ctx, name, metav1.GetOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), deployment.Generation)
+		assert.GreaterOrEqual(t, deployment.Status.ObservedGeneration, deployment.Generation)
+	})
+
+	t.Run("WaitForObservedGeneration_should_fail_for_missing_objects", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		err := WaitForObservedGeneration(ctx, c.KubeConfigFilePath(), "deployment", "does-not-exist", "default")
+		assert.Error(t, err)
+	})
+}
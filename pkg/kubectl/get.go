@@ -0,0 +1,195 @@
+package kubectl
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+type GetOptions struct {
+	/*
+		The namespace of the resources, the namespace of the current context is used when empty. Ignored for
+		cluster-scoped resources
+	*/
+	Namespace string
+	/*
+		Lists the resources across all namespaces i.e. kubectl get --all-namespaces. A single resource cannot be
+		read by name across all namespaces
+	*/
+	AllNamespaces bool
+	/*
+		Reads the resources as the given API version, e.g. v1 or apps/v1, instead of the preferred version of the
+		resource i.e. kubectl get RESOURCE.VERSION.GROUP
+	*/
+	APIVersion string
+}
+
+/*
+Get reads the resource from the cluster that the kubeconfigPath points to, like kubectl get RESOURCE NAME. The
+resourceType is in any of the forms kubectl accepts, e.g. deployment, deploy or deployments.apps.
+
+Example:
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	obj, err := Get(ctx, "/path/to/kubeconfig", &GetOptions{Namespace: "default"}, "deployment", "nginx")
+	if err != nil {
+		// Handle error
+	}
+
+	replicas, _, _ := unstructured.NestedInt64(obj.Object, "status", "readyReplicas")
+*/
+func Get(ctx context.Context, kubeconfigPath string, opts *GetOptions, resourceType, name string) (*unstructured.Unstructured, error) {
+	if opts == nil {
+		return nil, fmt.Errorf("options cannot be nil")
+	}
+
+	if resourceType == "" || name == "" {
+		return nil, fmt.Errorf("resource type and name cannot be empty")
+	}
+
+	if opts.AllNamespaces {
+		return nil, fmt.Errorf("%s %s cannot be read by name across all namespaces", resourceType, name)
+	}
+
+	client, err := getClient(kubeconfigPath, opts, resourceType)
+	if err != nil {
+		return nil, err
+	}
+
+	obj, err := client.Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("could not get %s %s: %w", resourceType, name, err)
+	}
+
+	return obj, nil
+}
+
+/*
+List reads every resource of the type from the cluster that the kubeconfigPath points to, like kubectl get RESOURCE.
+The resourceType is in any of the forms kubectl accepts, e.g. deployment, deploy or deployments.apps.
+
+Example:
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	list, err := List(ctx, "/path/to/kubeconfig", &GetOptions{AllNamespaces: true}, "pods")
+	if err != nil {
+		// Handle error
+	}
+
+	for _, pod := range list.Items {
+		fmt.Println(pod.GetNamespace(), pod.GetName())
+	}
+*/
+func List(ctx context.Context, kubeconfigPath string, opts *GetOptions, resourceType string) (*unstructured.UnstructuredList, error) {
+	if opts == nil {
+		return nil, fmt.Errorf("options cannot be nil")
+	}
+
+	if resourceType == "" {
+		return nil, fmt.Errorf("resource type cannot be empty")
+	}
+
+	client, err := getClient(kubeconfigPath, opts, resourceType)
+	if err != nil {
+		return nil, err
+	}
+
+	list, err := client.List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("could not list %s: %w", resourceType, err)
+	}
+
+	return list, nil
+}
+
+/*
+getClient returns the dynamic client of the resource type, in the namespace of the options unless the resource is
+cluster-scoped or listed across all namespaces.
+*/
+func getClient(kubeconfigPath string, opts *GetOptions, resourceType string) (dynamic.ResourceInterface, error) {
+	if kubeconfigPath == "" {
+		return nil, fmt.Errorf("kubeconfig path cannot be empty")
+	}
+
+	f := newNamespacedFactory(kubeconfigPath, opts.Namespace)
+
+	dynamicClient, err := f.DynamicClient()
+	if err != nil {
+		return nil, fmt.Errorf("could not create dynamic client: %w", err)
+	}
+
+	mapper, err := f.ToRESTMapper()
+	if err != nil {
+		return nil, fmt.Errorf("could not create rest mapper: %w", err)
+	}
+
+	gvr, err := resolveResource(mapper, resourceType, opts.APIVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	gvk, err := mapper.KindFor(gvr)
+	if err != nil {
+		return nil, fmt.Errorf("could not find kind for %s: %w", resourceType, err)
+	}
+
+	mapping, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		return nil, fmt.Errorf("could not find resource for %s: %w", gvk.String(), err)
+	}
+
+	if mapping.Scope.Name() != meta.RESTScopeNameNamespace || opts.AllNamespaces {
+		return dynamicClient.Resource(gvr), nil
+	}
+
+	namespace, _, err := f.ToRawKubeConfigLoader().Namespace()
+	if err != nil {
+		return nil, fmt.Errorf("could not determine default namespace: %w", err)
+	}
+
+	return dynamicClient.Resource(gvr).Namespace(namespace), nil
+}
+
+/*
+resolveResource resolves the resource type to its resource, in the given API version when set. The resource type is in
+any of the forms kubectl accepts, including the fully qualified RESOURCE.VERSION.GROUP form.
+*/
+func resolveResource(mapper meta.RESTMapper, resourceType, apiVersion string) (schema.GroupVersionResource, error) {
+	if apiVersion != "" {
+		versioned, err := versionedResource(resourceType, apiVersion)
+		if err != nil {
+			return schema.GroupVersionResource{}, err
+		}
+
+		resourceType = versioned
+	}
+
+	// A group with dots, e.g. widgets.test.go-kube.io, also parses as a fully qualified resource
+	fullySpecified, groupResource := schema.ParseResourceArg(resourceType)
+	if fullySpecified != nil {
+		gvr, err := mapper.ResourceFor(*fullySpecified)
+		if err == nil {
+			return gvr, nil
+		}
+
+		if apiVersion != "" {
+			return schema.GroupVersionResource{}, fmt.Errorf("could not find resource for %s: %w", resourceType, err)
+		}
+	}
+
+	gvr, err := mapper.ResourceFor(groupResource.WithVersion(""))
+	if err != nil {
+		return schema.GroupVersionResource{}, fmt.Errorf("could not find resource for %s: %w", resourceType, err)
+	}
+
+	return gvr, nil
+}
@@ -0,0 +1,144 @@
+package kubectl
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Arneproductions/go-kube/internal/resources"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestGetOptions(t *testing.T) {
+	t.Run("Get_should_reject_names_across_all_namespaces", func(t *testing.T) {
+		_, err := Get(context.Background(), "/path/to/kubeconfig", &GetOptions{AllNamespaces: true}, "configmap", "foo")
+		assert.Error(t, err)
+	})
+
+	t.Run("Get_should_reject_empty_names", func(t *testing.T) {
+		_, err := Get(context.Background(), "/path/to/kubeconfig", &GetOptions{}, "configmap", "")
+		assert.Error(t, err)
+	})
+
+	t.Run("List_should_reject_nil_options", func(t *testing.T) {
+		_, err := List(context.Background(), "/path/to/kubeconfig", nil, "configmaps")
+		assert.Error(t, err)
+	})
+}
+
+func TestGet(t *testing.T) {
+	c := resources.NewEphemeralCluster()
+	require.NoError(t, c.Start())
+
+	t.Cleanup(func() {
+		require.NoError(t, c.Stop())
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	namespace := fmt.Sprintf("test-ns-%s", uuid.New().String())
+	name := fmt.Sprintf("test-cm-%s", uuid.New().String())
+
+	_, err := c.Client().CoreV1().Namespaces().Create(ctx, &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{Name: namespace},
+	}, metav1.CreateOptions{})
+	require.NoError(t, err)
+
+	for _, ns := range []string{"default", namespace} {
+		_, err = c.Client().CoreV1().ConfigMaps(ns).Create(ctx, &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Data:       map[string]string{"namespace": ns},
+		}, metav1.CreateOptions{})
+		require.NoError(t, err)
+	}
+
+	t.Run("Get_should_read_the_resource_in_the_namespace", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		obj, err := Get(ctx, c.KubeConfigFilePath(), &GetOptions{Namespace: namespace}, "cm", name)
+		require.NoError(t, err)
+
+		data, _, _ := unstructured.NestedString(obj.Object, "data", "namespace")
+		assert.Equal(t, namespace, data)
+	})
+
+	t.Run("Get_should_default_to_the_namespace_of_the_current_context", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		obj, err := Get(ctx, c.KubeConfigFilePath(), &GetOptions{}, "configmaps", name)
+		require.NoError(t, err)
+
+		assert.Equal(t, "default", obj.GetNamespace())
+	})
+
+	t.Run("Get_should_read_cluster_scoped_resources", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		obj, err := Get(ctx, c.KubeConfigFilePath(), &GetOptions{Namespace: "ignored", APIVersion: "v1"}, "namespace", namespace)
+		require.NoError(t, err)
+
+		assert.Equal(t, "v1", obj.GetAPIVersion())
+		assert.Equal(t, namespace, obj.GetName())
+	})
+
+	t.Run("Get_should_wrap_not_found_errors", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		_, err := Get(ctx, c.KubeConfigFilePath(), &GetOptions{}, "configmap", "does-not-exist")
+		assert.True(t, apierrors.IsNotFound(err))
+	})
+
+	t.Run("List_should_list_the_resources_of_the_namespace", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		list, err := List(ctx, c.KubeConfigFilePath(), &GetOptions{Namespace: namespace}, "configmaps")
+		require.NoError(t, err)
+
+		for _, item := range list.Items {
+			assert.Equal(t, namespace, item.GetNamespace())
+		}
+		assert.Contains(t, listedNames(list), namespace+"/"+name)
+	})
+
+	t.Run("List_should_list_the_resources_of_all_namespaces", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		list, err := List(ctx, c.KubeConfigFilePath(), &GetOptions{AllNamespaces: true}, "configmaps")
+		require.NoError(t, err)
+
+		names := listedNames(list)
+		assert.Contains(t, names, "default/"+name)
+		assert.Contains(t, names, namespace+"/"+name)
+	})
+
+	t.Run("List_should_reject_unknown_resource_types", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		_, err := List(ctx, c.KubeConfigFilePath(), &GetOptions{}, "doesnotexist")
+		assert.Error(t, err)
+	})
+}
+
+func listedNames(list *unstructured.UnstructuredList) []string {
+	names := []string{}
+	for _, item := range list.Items {
+		names = append(names, item.GetNamespace()+"/"+item.GetName())
+	}
+
+	return names
+}
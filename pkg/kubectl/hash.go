@@ -0,0 +1,71 @@
+package kubectl
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+/*
+ManifestsHash returns a digest of the objects in the given manifest files, e.g. to skip an apply when the manifests
+have not changed since the last apply. The digest does not depend on the formatting of the files, such as the order of
+keys, whitespace, comments, YAML or JSON, or the order of the objects and files. Directories are read like kubectl
+apply reads them without --recursive.
+
+Example:
+
+	hash, err := ManifestsHash("/path/to/manifest1.yaml", "/path/to/manifest2.yaml")
+	if err != nil {
+		// Handle error
+	}
+
+	if hash != lastAppliedHash {
+		// Apply the manifests
+	}
+*/
+func ManifestsHash(filePaths ...string) (string, error) {
+	objs, err := readManifests(filePaths, false)
+	if err != nil {
+		return "", err
+	}
+
+	return objectsHash(objs)
+}
+
+/*
+ManifestsHashBytes returns a digest of the objects in the manifest data like ManifestsHash, where the data is one or
+more YAML or JSON documents.
+*/
+func ManifestsHashBytes(data []byte) (string, error) {
+	objs, err := decodeManifests(data, "bytes")
+	if err != nil {
+		return "", err
+	}
+
+	return objectsHash(objs)
+}
+
+/*
+objectsHash encodes every object as JSON, which sorts the keys of the objects, and hashes the sorted encodings.
+*/
+func objectsHash(objs []*unstructured.Unstructured) (string, error) {
+	encoded := []string{}
+	for _, obj := range objs {
+		data, err := json.Marshal(obj.Object)
+		if err != nil {
+			return "", fmt.Errorf("could not encode %s %s: %w", obj.GetKind(), obj.GetName(), err)
+		}
+
+		encoded = append(encoded, string(data))
+	}
+	sort.Strings(encoded)
+
+	sum := sha256.Sum256([]byte(strings.Join(encoded, "\n")))
+
+	return "sha256:" + hex.EncodeToString(sum[:]), nil
+}
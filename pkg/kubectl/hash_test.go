@@ -0,0 +1,68 @@
+package kubectl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestManifestsHash(t *testing.T) {
+	t.Run("ManifestsHash_should_ignore_the_formatting_of_the_manifests", func(t *testing.T) {
+		first := writeTestManifest(t, `
+# The config of foo
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: foo
+  namespace: default
+data:
+  key: value
+---
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: bar
+`)
+		second := writeTestManifest(t, `
+kind: Namespace
+apiVersion: v1
+metadata: {name: bar}
+---
+{"kind": "ConfigMap", "apiVersion": "v1",
+ "data": {"key": "value"},
+ "metadata": {"namespace": "default", "name": "foo"}}
+`)
+
+		firstHash, err := ManifestsHash(first)
+		require.NoError(t, err)
+
+		secondHash, err := ManifestsHash(second)
+		require.NoError(t, err)
+
+		assert.Equal(t, firstHash, secondHash)
+		assert.Regexp(t, `^sha256:[0-9a-f]{64}$`, firstHash)
+	})
+
+	t.Run("ManifestsHash_should_change_with_the_objects", func(t *testing.T) {
+		hash, err := ManifestsHash(writeTestManifest(t, configMapDataManifest("foo", "key", "value")))
+		require.NoError(t, err)
+
+		changedHash, err := ManifestsHash(writeTestManifest(t, configMapDataManifest("foo", "key", "changed")))
+		require.NoError(t, err)
+
+		assert.NotEqual(t, hash, changedHash)
+	})
+
+	t.Run("ManifestsHashBytes_should_match_ManifestsHash", func(t *testing.T) {
+		manifest := configMapDataManifest("foo", "key", "value")
+
+		hash, err := ManifestsHash(writeTestManifest(t, manifest))
+		require.NoError(t, err)
+
+		bytesHash, err := ManifestsHashBytes([]byte(manifest))
+		require.NoError(t, err)
+
+		assert.Equal(t, hash, bytesHash)
+	})
+}
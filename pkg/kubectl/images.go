@@ -0,0 +1,394 @@
+package kubectl
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os/exec"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+var (
+	// The client that asks the registries whether images exist
+	imageRegistryClient = &http.Client{Timeout: 30 * time.Second}
+
+	// The media types of the manifests an image may have, such that registries do not reject the request
+	imageManifestMediaTypes = []string{
+		"application/vnd.oci.image.index.v1+json",
+		"application/vnd.oci.image.manifest.v1+json",
+		"application/vnd.docker.distribution.manifest.list.v2+json",
+		"application/vnd.docker.distribution.manifest.v2+json",
+	}
+
+	bearerChallengeParam = regexp.MustCompile(`(\w+)="([^"]*)"`)
+)
+
+// imageRef is a parsed image reference, e.g. docker.io/library/nginx:1.25
+type imageRef struct {
+	Registry   string
+	Repository string
+	// The tag or digest of the image
+	Reference string
+}
+
+/*
+verifyImages checks that every container image of the objects in the given files exists, either in its registry or in
+the local docker daemon, e.g. images loaded into a kind cluster. The missing images of all objects are returned as one
+error.
+*/
+func verifyImages(ctx context.Context, recursive bool, filePaths ...string) error {
+	objs, err := readManifests(filePaths, recursive)
+	if err != nil {
+		return err
+	}
+
+	// Every image is only checked once, however many objects use it
+	users := map[string][]string{}
+	for _, obj := range objs {
+		images, err := containerImages(obj)
+		if err != nil {
+			return err
+		}
+
+		for _, image := range images {
+			users[image] = append(users[image], fmt.Sprintf("%s %s", obj.GetKind(), obj.GetName()))
+		}
+	}
+
+	images := []string{}
+	for image := range users {
+		images = append(images, image)
+	}
+	sort.Strings(images)
+
+	errs := []error{}
+	for _, image := range images {
+		exists, err := imageExists(ctx, image)
+		switch {
+		case err != nil:
+			errs = append(errs, fmt.Errorf("could not verify image %s of %s: %w", image, strings.Join(users[image], ", "), err))
+		case !exists:
+			errs = append(errs, fmt.Errorf("image %s of %s does not exist", image, strings.Join(users[image], ", ")))
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("manifests failed image verification: %w", errors.Join(errs...))
+	}
+
+	return nil
+}
+
+/*
+containerImages returns the images of the containers and init containers of the object, when it runs pods.
+*/
+func containerImages(obj *unstructured.Unstructured) ([]string, error) {
+	path, ok := podSpecPaths[obj.GetKind()]
+	if !ok {
+		return nil, nil
+	}
+
+	images := []string{}
+	for _, field := range []string{"initContainers", "containers"} {
+		containers, _, err := unstructured.NestedSlice(obj.Object, append(path, field)...)
+		if err != nil {
+			return nil, fmt.Errorf("could not read %s of %s %s: %w", field, obj.GetKind(), obj.GetName(), err)
+		}
+
+		for _, container := range containers {
+			containerMap, ok := container.(map[string]interface{})
+			if !ok {
+				continue
+			}
+
+			if image, ok := containerMap["image"].(string); ok && image != "" {
+				images = append(images, image)
+			}
+		}
+	}
+
+	return images, nil
+}
+
+/*
+parseImageRef parses the image like docker does, defaulting to the latest tag of Docker Hub.
+*/
+func parseImageRef(image string) imageRef {
+	ref := imageRef{Registry: "registry-1.docker.io", Reference: "latest"}
+
+	name := image
+	if i := strings.Index(name, "@"); i >= 0 {
+		name, ref.Reference = name[:i], name[i+1:]
+	} else if i := strings.LastIndex(name, ":"); i > strings.LastIndex(name, "/") {
+		name, ref.Reference = name[:i], name[i+1:]
+	}
+
+	// The first part of the name is a registry when it looks like a host
+	if i := strings.Index(name, "/"); i >= 0 {
+		host := name[:i]
+		if strings.ContainsAny(host, ".:") || host == "localhost" {
+			ref.Registry = host
+			name = name[i+1:]
+		}
+	}
+
+	if ref.Registry == "docker.io" || ref.Registry == "index.docker.io" {
+		ref.Registry = "registry-1.docker.io"
+	}
+
+	if ref.Registry == "registry-1.docker.io" && !strings.Contains(name, "/") {
+		name = "library/" + name
+	}
+	ref.Repository = name
+
+	return ref
+}
+
+/*
+imageExists reports whether the image exists in its registry, or in the local docker daemon.
+*/
+func imageExists(ctx context.Context, image string) (bool, error) {
+	exists, err := registryHasImage(ctx, parseImageRef(image))
+	if exists {
+		return true, nil
+	}
+
+	// Images that are only loaded into the cluster, e.g. with kind load, exist in the local daemon
+	if localImageExists(ctx, image) {
+		return true, nil
+	}
+
+	return false, err
+}
+
+/*
+registryHasImage asks the registry whether it has the manifest of the image.
+*/
+func registryHasImage(ctx context.Context, ref imageRef) (bool, error) {
+	resp, err := registryManifest(ctx, ref)
+	if err != nil {
+		return false, err
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return true, nil
+	case http.StatusNotFound:
+		return false, nil
+	default:
+		return false, fmt.Errorf("registry %s responded with %s", ref.Registry, resp.Status)
+	}
+}
+
+/*
+registryManifest asks the registry for the manifest of the image, authenticating anonymously when the registry
+asks for a bearer token, like Docker Hub does. The body of the response is closed.
+*/
+func registryManifest(ctx context.Context, ref imageRef) (*http.Response, error) {
+	manifestURL := fmt.Sprintf("https://%s/v2/%s/manifests/%s", ref.Registry, ref.Repository, ref.Reference)
+
+	resp, err := headManifest(ctx, manifestURL, "")
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		token, err := anonymousToken(ctx, resp.Header.Get("Www-Authenticate"))
+		if err != nil {
+			return nil, err
+		}
+
+		return headManifest(ctx, manifestURL, token)
+	}
+
+	return resp, nil
+}
+
+func headManifest(ctx context.Context, manifestURL, token string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, manifestURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Accept", strings.Join(imageManifestMediaTypes, ", "))
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := imageRegistryClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("could not reach registry: %w", err)
+	}
+	resp.Body.Close()
+
+	return resp, nil
+}
+
+/*
+anonymousToken fetches a token from the realm of the bearer challenge, i.e.
+
+	Bearer realm="https://auth.docker.io/token",service="registry.docker.io",scope="repository:library/nginx:pull"
+*/
+func anonymousToken(ctx context.Context, challenge string) (string, error) {
+	if !strings.HasPrefix(challenge, "Bearer ") {
+		return "", fmt.Errorf("registry requires unsupported authentication %q", challenge)
+	}
+
+	params := map[string]string{}
+	for _, match := range bearerChallengeParam.FindAllStringSubmatch(challenge, -1) {
+		params[match[1]] = match[2]
+	}
+
+	realm, err := url.Parse(params["realm"])
+	if err != nil || params["realm"] == "" {
+		return "", fmt.Errorf("registry sent an invalid authentication realm %q", params["realm"])
+	}
+
+	query := realm.Query()
+	for _, key := range []string{"service", "scope"} {
+		if params[key] != "" {
+			query.Set(key, params[key])
+		}
+	}
+	realm.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, realm.String(), nil)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := imageRegistryClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("could not get registry token: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("could not get registry token: %s", resp.Status)
+	}
+
+	body := struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}{}
+	err = json.NewDecoder(resp.Body).Decode(&body)
+	if err != nil {
+		return "", fmt.Errorf("could not decode registry token: %w", err)
+	}
+
+	if body.Token != "" {
+		return body.Token, nil
+	}
+
+	return body.AccessToken, nil
+}
+
+/*
+localImageExists reports whether the local docker daemon has the image, false when docker is not available.
+*/
+func localImageExists(ctx context.Context, image string) bool {
+	if _, err := exec.LookPath("docker"); err != nil {
+		return false
+	}
+
+	return exec.CommandContext(ctx, "docker", "image", "inspect", image).Run() == nil
+}
+
+/*
+pinImages returns a Transform that replaces the tag of every container image of the objects that run pods with the
+digest the tag resolves to in its registry. Images that are already pinned to a digest are left as they are, and
+every image is only resolved once.
+*/
+func pinImages(ctx context.Context) Transform {
+	pinned := map[string]string{}
+
+	return func(obj *unstructured.Unstructured) error {
+		path, ok := podSpecPaths[obj.GetKind()]
+		if !ok {
+			return nil
+		}
+
+		for _, field := range []string{"initContainers", "containers"} {
+			containers, found, err := unstructured.NestedSlice(obj.Object, append(path, field)...)
+			if err != nil {
+				return err
+			}
+			if !found {
+				continue
+			}
+
+			for _, container := range containers {
+				containerMap, ok := container.(map[string]interface{})
+				if !ok {
+					continue
+				}
+
+				image, ok := containerMap["image"].(string)
+				if !ok || image == "" {
+					continue
+				}
+
+				if _, ok := pinned[image]; !ok {
+					pinnedImage, err := pinImage(ctx, image)
+					if err != nil {
+						return fmt.Errorf("could not pin image %s of %s %s: %w", image, obj.GetKind(), obj.GetName(), err)
+					}
+
+					pinned[image] = pinnedImage
+				}
+
+				containerMap["image"] = pinned[image]
+			}
+
+			err = unstructured.SetNestedSlice(obj.Object, containers, append(path, field)...)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+}
+
+/*
+pinImage resolves the tag of the image to its digest in the registry, and returns the image referenced by the digest,
+e.g. nginx@sha256:... for nginx:1.25.
+*/
+func pinImage(ctx context.Context, image string) (string, error) {
+	if strings.Contains(image, "@") {
+		return image, nil
+	}
+
+	resp, err := registryManifest(ctx, parseImageRef(image))
+	if err != nil {
+		return "", err
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return "", fmt.Errorf("the tag does not exist in the registry")
+	default:
+		return "", fmt.Errorf("registry responded with %s", resp.Status)
+	}
+
+	digest := resp.Header.Get("Docker-Content-Digest")
+	if !strings.HasPrefix(digest, "sha256:") {
+		return "", fmt.Errorf("registry did not respond with a digest of the tag")
+	}
+
+	name := image
+	if i := strings.LastIndex(name, ":"); i > strings.LastIndex(name, "/") {
+		name = name[:i]
+	}
+
+	return name + "@" + digest, nil
+}
@@ -0,0 +1,170 @@
+package kubectl
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Arneproductions/go-kube/internal/resources"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestParseImageRef(t *testing.T) {
+	t.Run("parseImageRef_should_default_like_docker", func(t *testing.T) {
+		for image, want := range map[string]imageRef{
+			"nginx":                           {Registry: "registry-1.docker.io", Repository: "library/nginx", Reference: "latest"},
+			"busybox:1.36":                    {Registry: "registry-1.docker.io", Repository: "library/busybox", Reference: "1.36"},
+			"bitnami/kubectl:1.29":            {Registry: "registry-1.docker.io", Repository: "bitnami/kubectl", Reference: "1.29"},
+			"docker.io/library/nginx":         {Registry: "registry-1.docker.io", Repository: "library/nginx", Reference: "latest"},
+			"registry.k8s.io/pause:3.9":       {Registry: "registry.k8s.io", Repository: "pause", Reference: "3.9"},
+			"localhost:5000/app/web:v1":       {Registry: "localhost:5000", Repository: "app/web", Reference: "v1"},
+			"quay.io/org/app@sha256:abcdef01": {Registry: "quay.io", Repository: "org/app", Reference: "sha256:abcdef01"},
+		} {
+			assert.Equal(t, want, parseImageRef(image), image)
+		}
+	})
+}
+
+func TestRegistryHasImage(t *testing.T) {
+	// The registry asks for a token like Docker Hub, and only has the manifest of app:v1
+	mux := http.NewServeMux()
+	server := httptest.NewTLSServer(mux)
+	t.Cleanup(server.Close)
+
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "repository:app:pull", r.URL.Query().Get("scope"))
+		fmt.Fprint(w, `{"token":"test-token"}`)
+	})
+	mux.HandleFunc("/v2/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer test-token" {
+			w.Header().Set("Www-Authenticate", fmt.Sprintf(`Bearer realm="%s/token",service="test",scope="repository:app:pull"`, server.URL))
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		if r.URL.Path != "/v2/app/manifests/v1" {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	client := imageRegistryClient
+	imageRegistryClient = server.Client()
+	t.Cleanup(func() {
+		imageRegistryClient = client
+	})
+
+	registry := strings.TrimPrefix(server.URL, "https://")
+
+	t.Run("registryHasImage_should_find_existing_images", func(t *testing.T) {
+		exists, err := registryHasImage(context.Background(), parseImageRef(registry+"/app:v1"))
+		require.NoError(t, err)
+		assert.True(t, exists)
+	})
+
+	t.Run("registryHasImage_should_not_find_missing_tags", func(t *testing.T) {
+		exists, err := registryHasImage(context.Background(), parseImageRef(registry+"/app:does-not-exist"))
+		require.NoError(t, err)
+		assert.False(t, exists)
+	})
+}
+
+func TestPinImage(t *testing.T) {
+	// The registry only has the manifest of app:v1
+	mux := http.NewServeMux()
+	server := httptest.NewTLSServer(mux)
+	t.Cleanup(server.Close)
+
+	digest := "sha256:" + strings.Repeat("ab", 32)
+	mux.HandleFunc("/v2/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v2/app/manifests/v1" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		w.Header().Set("Docker-Content-Digest", digest)
+	})
+
+	client := imageRegistryClient
+	imageRegistryClient = server.Client()
+	t.Cleanup(func() {
+		imageRegistryClient = client
+	})
+
+	registry := strings.TrimPrefix(server.URL, "https://")
+
+	t.Run("pinImage_should_replace_the_tag_with_the_digest", func(t *testing.T) {
+		image, err := pinImage(context.Background(), registry+"/app:v1")
+		require.NoError(t, err)
+		assert.Equal(t, registry+"/app@"+digest, image)
+	})
+
+	t.Run("pinImage_should_keep_images_that_are_pinned", func(t *testing.T) {
+		image, err := pinImage(context.Background(), registry+"/app@"+digest)
+		require.NoError(t, err)
+		assert.Equal(t, registry+"/app@"+digest, image)
+	})
+
+	t.Run("pinImages_should_fail_on_missing_tags", func(t *testing.T) {
+		obj := &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "Pod",
+			"metadata":   map[string]interface{}{"name": "test-pod"},
+			"spec": map[string]interface{}{
+				"containers": []interface{}{
+					map[string]interface{}{"name": "app", "image": registry + "/app:does-not-exist"},
+				},
+			},
+		}}
+
+		err := pinImages(context.Background())(obj)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), fmt.Sprintf("could not pin image %s/app:does-not-exist of Pod test-pod", registry))
+	})
+}
+
+func TestVerifyImages(t *testing.T) {
+	c := resources.NewEphemeralCluster()
+	require.NoError(t, c.Start())
+
+	t.Cleanup(func() {
+		require.NoError(t, c.Stop())
+	})
+
+	t.Run("ApplyManifests_should_reject_images_that_do_not_exist", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		name := fmt.Sprintf("test-deploy-%s", uuid.New().String())
+		manifest := strings.Replace(loggingDeploymentManifest(name, 1), "busybox:1.36", "busybox:does-not-exist-tag", 1)
+
+		err := ApplyManifests(ctx, c.KubeConfigFilePath(), &ApplyManifestsOptions{VerifyImages: true}, writeTestManifest(t, manifest))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), fmt.Sprintf("image busybox:does-not-exist-tag of Deployment %s does not exist", name))
+
+		_, err = c.Client().AppsV1().Deployments("default").Get(ctx, name, metav1.GetOptions{})
+		assert.True(t, apierrors.IsNotFound(err))
+	})
+	t.Run("ApplyManifests_should_pin_images_to_their_digests", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		name := fmt.Sprintf("test-deploy-%s", uuid.New().String())
+
+		err := ApplyManifests(ctx, c.KubeConfigFilePath(), &ApplyManifestsOptions{PinImages: true}, writeTestManifest(t, loggingDeploymentManifest(name, 1)))
+		require.NoError(t, err)
+
+		deploy, err := c.Client().AppsV1().Deployments("default").Get(ctx, name, metav1.GetOptions{})
+		require.NoError(t, err)
+		assert.Regexp(t, `^busybox@sha256:[0-9a-f]{64}$`, deploy.Spec.Template.Spec.Containers[0].Image)
+	})
+}
@@ -0,0 +1,189 @@
+package kubectl
+
+import (
+	"context"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// Inventory records the objects that an apply applied, such that a later apply can prune the objects it no longer applies
+type Inventory struct {
+	Objects []InventoryObject `json:"objects"`
+}
+
+// InventoryObject identifies an object of an Inventory
+type InventoryObject struct {
+	// The API group of the object, empty for the core group
+	Group string `json:"group,omitempty"`
+	Kind  string `json:"kind"`
+	// The namespace of the object, empty for cluster scoped objects
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+}
+
+/*
+contains reports whether the inventory contains the object.
+*/
+func (i *Inventory) contains(obj InventoryObject) bool {
+	for _, o := range i.Objects {
+		if o == obj {
+			return true
+		}
+	}
+
+	return false
+}
+
+/*
+ApplyWithInventory applies the given files like ApplyManifests, and returns an Inventory of the objects that the apply
+reports, i.e. without the objects that the options filter out or the apply prunes. The inventory can be stored between
+runs, and given to PruneToInventory with the inventory of a later apply to delete the objects that are no longer
+applied, without relying on labels.
+
+Example:
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	inventory, err := ApplyWithInventory(ctx, "/path/to/kubeconfig", &ApplyManifestsOptions{}, "/path/to/manifest.yaml")
+	if err != nil {
+		// Handle error
+	}
+
+	err = PruneToInventory(ctx, "/path/to/kubeconfig", previousInventory, inventory)
+	if err != nil {
+		// Handle error
+	}
+*/
+func ApplyWithInventory(ctx context.Context, kubeconfigPath string, opts *ApplyManifestsOptions, filePaths ...string) (*Inventory, error) {
+	if opts == nil {
+		return nil, fmt.Errorf("options cannot be nil")
+	}
+
+	result, err := ApplyManifestsWithResult(ctx, kubeconfigPath, opts, filePaths...)
+	if err != nil {
+		return nil, err
+	}
+
+	f := newFactory(kubeconfigPath)
+
+	mapper, err := f.ToRESTMapper()
+	if err != nil {
+		return nil, fmt.Errorf("could not create rest mapper: %w", err)
+	}
+
+	namespace, _, err := f.ToRawKubeConfigLoader().Namespace()
+	if err != nil {
+		return nil, fmt.Errorf("could not determine default namespace: %w", err)
+	}
+
+	return inventoryOf(mapper, result, namespace)
+}
+
+/*
+inventoryOf returns the inventory of the objects that the apply of the result applied, leaving out the objects it
+pruned. The kinds are resolved from the lowercased kinds that kubectl prints.
+*/
+func inventoryOf(mapper meta.RESTMapper, result *ApplyResult, defaultNamespace string) (*Inventory, error) {
+	inventory := &Inventory{}
+	for _, obj := range result.Objects {
+		if obj.Operation == OperationPruned {
+			continue
+		}
+
+		gvk, err := mapper.KindFor(schema.GroupVersionResource{Group: obj.Group, Resource: obj.Kind})
+		if err != nil {
+			return nil, fmt.Errorf("could not find kind for %s: %w", obj.Kind, err)
+		}
+
+		mapping, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+		if err != nil {
+			return nil, fmt.Errorf("could not find resource for %s: %w", gvk.String(), err)
+		}
+
+		// The namespace is recorded the way the object was applied, as objects without one are applied to the default namespace
+		objNamespace := ""
+		if mapping.Scope.Name() == meta.RESTScopeNameNamespace {
+			objNamespace = obj.Namespace
+			if objNamespace == "" {
+				objNamespace = defaultNamespace
+			}
+		}
+
+		entry := InventoryObject{
+			Group:     gvk.Group,
+			Kind:      gvk.Kind,
+			Namespace: objNamespace,
+			Name:      obj.Name,
+		}
+
+		if !inventory.contains(entry) {
+			inventory.Objects = append(inventory.Objects, entry)
+		}
+	}
+
+	return inventory, nil
+}
+
+/*
+PruneToInventory deletes the objects of the previous inventory that are not in the current inventory from the cluster that the
+kubeconfigPath points to. Objects that are already gone are skipped. The objects are deleted in the reverse order of
+the previous inventory, such that objects are deleted before the namespaces and definitions they were applied after.
+
+Example:
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	err := PruneToInventory(ctx, "/path/to/kubeconfig", previousInventory, currentInventory)
+	if err != nil {
+		// Handle error
+	}
+*/
+func PruneToInventory(ctx context.Context, kubeconfigPath string, previous, current *Inventory) error {
+	if kubeconfigPath == "" {
+		return fmt.Errorf("kubeconfig path cannot be empty")
+	}
+
+	if previous == nil || current == nil {
+		return fmt.Errorf("inventories cannot be nil")
+	}
+
+	f := newFactory(kubeconfigPath)
+
+	dynamicClient, err := f.DynamicClient()
+	if err != nil {
+		return fmt.Errorf("could not create dynamic client: %w", err)
+	}
+
+	mapper, err := f.ToRESTMapper()
+	if err != nil {
+		return fmt.Errorf("could not create rest mapper: %w", err)
+	}
+
+	for i := len(previous.Objects) - 1; i >= 0; i-- {
+		obj := previous.Objects[i]
+		if current.contains(obj) {
+			continue
+		}
+
+		mapping, err := mapper.RESTMapping(schema.GroupKind{Group: obj.Group, Kind: obj.Kind})
+		if err != nil {
+			return fmt.Errorf("could not find resource for %s: %w", obj.Kind, err)
+		}
+
+		err = dynamicClient.Resource(mapping.Resource).Namespace(obj.Namespace).Delete(ctx, obj.Name, metav1.DeleteOptions{})
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("could not prune %s %s: %w", obj.Kind, obj.Name, err)
+		}
+	}
+
+	return nil
+}
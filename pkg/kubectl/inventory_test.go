@@ -0,0 +1,93 @@
+package kubectl
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Arneproductions/go-kube/internal/resources"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestInventoryOf(t *testing.T) {
+	t.Run("inventoryOf_should_record_the_applied_objects_of_the_result", func(t *testing.T) {
+		mapper := meta.NewDefaultRESTMapper(nil)
+		mapper.Add(schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"}, meta.RESTScopeNamespace)
+		mapper.Add(schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"}, meta.RESTScopeNamespace)
+		mapper.Add(schema.GroupVersionKind{Version: "v1", Kind: "Namespace"}, meta.RESTScopeRoot)
+
+		result := &ApplyResult{Objects: []ObjectResult{
+			{ObjectRef: ObjectRef{Kind: "namespace", Name: "foo"}, Operation: OperationCreated},
+			{ObjectRef: ObjectRef{Group: "apps", Kind: "deployment", Name: "nginx"}, Namespace: "foo", Operation: OperationConfigured},
+			{ObjectRef: ObjectRef{Kind: "configmap", Name: "bar"}, Operation: OperationUnchanged},
+			{ObjectRef: ObjectRef{Kind: "configmap", Name: "baz"}, Operation: OperationPruned},
+		}}
+
+		inventory, err := inventoryOf(mapper, result, "default")
+		require.NoError(t, err)
+		assert.Equal(t, []InventoryObject{
+			{Kind: "Namespace", Name: "foo"},
+			{Group: "apps", Kind: "Deployment", Namespace: "foo", Name: "nginx"},
+			{Kind: "ConfigMap", Namespace: "default", Name: "bar"},
+		}, inventory.Objects)
+	})
+}
+
+func TestInventory(t *testing.T) {
+	c := resources.NewEphemeralCluster()
+	require.NoError(t, c.Start())
+
+	t.Cleanup(func() {
+		require.NoError(t, c.Stop())
+	})
+
+	t.Run("PruneToInventory_should_delete_objects_missing_from_the_current_inventory", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		keptName := fmt.Sprintf("test-cm-%s", uuid.New().String())
+		prunedName := fmt.Sprintf("test-cm-%s", uuid.New().String())
+
+		t.Cleanup(func() {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			defer cancel()
+
+			_ = c.Client().CoreV1().ConfigMaps("default").Delete(ctx, keptName, metav1.DeleteOptions{})
+			_ = c.Client().CoreV1().ConfigMaps("default").Delete(ctx, prunedName, metav1.DeleteOptions{})
+		})
+
+		bothFile := writeTestManifest(t, configMapDataManifest(keptName, "foo", "bar")+"---"+configMapDataManifest(prunedName, "foo", "bar"))
+		keptFile := writeTestManifest(t, configMapDataManifest(keptName, "foo", "bar"))
+
+		previous, err := ApplyWithInventory(ctx, c.KubeConfigFilePath(), &ApplyManifestsOptions{}, bothFile)
+		require.NoError(t, err)
+		assert.Equal(t, []InventoryObject{
+			{Kind: "ConfigMap", Namespace: "default", Name: keptName},
+			{Kind: "ConfigMap", Namespace: "default", Name: prunedName},
+		}, previous.Objects)
+
+		current, err := ApplyWithInventory(ctx, c.KubeConfigFilePath(), &ApplyManifestsOptions{}, keptFile)
+		require.NoError(t, err)
+
+		err = PruneToInventory(ctx, c.KubeConfigFilePath(), previous, current)
+		require.NoError(t, err)
+
+		_, err = c.Client().CoreV1().ConfigMaps("default").Get(ctx, keptName, metav1.GetOptions{})
+		assert.NoError(t, err, "the config map in the current inventory should be kept")
+
+		_, err = c.Client().CoreV1().ConfigMaps("default").Get(ctx, prunedName, metav1.GetOptions{})
+		assert.True(t, apierrors.IsNotFound(err), "the config map missing from the current inventory should be pruned")
+
+		// Pruning again finds nothing left to delete
+		err = PruneToInventory(ctx, c.KubeConfigFilePath(), previous, current)
+		assert.NoError(t, err)
+	})
+}
@@ -0,0 +1,83 @@
+package kubectl
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/kustomize/api/krusty"
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+/*
+buildKustomizations builds every kustomization, and writes their objects to a temporary manifest file, which the
+returned cleanup function removes.
+*/
+func buildKustomizations(ctx context.Context, restrictor LoadRestrictor, paths ...string) (string, func(), error) {
+	cleanup := func() {}
+
+	objs := []*unstructured.Unstructured{}
+	for _, path := range paths {
+		kustomizationObjs, err := buildKustomization(ctx, restrictor, path)
+		if err != nil {
+			return "", cleanup, err
+		}
+
+		objs = append(objs, kustomizationObjs...)
+	}
+
+	manifestPath, err := writeManifests(objs)
+	if err != nil {
+		return "", cleanup, err
+	}
+
+	return manifestPath, func() { os.Remove(manifestPath) }, nil
+}
+
+/*
+buildKustomization builds the kustomization at the path, which is a directory or a remote kustomization, e.g.
+github.com/org/repo//overlays/prod?ref=v1. Remote bases are cloned with git, so building may take a while. The build
+is given up when the context is done, though the git commands of kustomize keep running until their own timeout.
+*/
+func buildKustomization(ctx context.Context, restrictor LoadRestrictor, path string) ([]*unstructured.Unstructured, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("could not build kustomization %s in time: %w", path, err)
+	}
+
+	opts := krusty.MakeDefaultOptions()
+	opts.LoadRestrictions = types.LoadRestrictionsRootOnly
+	if restrictor == LoadRestrictorNone {
+		opts.LoadRestrictions = types.LoadRestrictionsNone
+	}
+
+	type buildResult struct {
+		data []byte
+		err  error
+	}
+
+	// The kustomizer cannot be cancelled, so we wait for it in the background
+	done := make(chan buildResult, 1)
+	go func() {
+		resources, err := krusty.MakeKustomizer(opts).Run(filesys.MakeFsOnDisk(), path)
+		if err != nil {
+			done <- buildResult{err: err}
+			return
+		}
+
+		data, err := resources.AsYaml()
+		done <- buildResult{data: data, err: err}
+	}()
+
+	select {
+	case <-ctx.Done():
+		return nil, fmt.Errorf("could not build kustomization %s in time: %w", path, ctx.Err())
+	case result := <-done:
+		if result.err != nil {
+			return nil, fmt.Errorf("could not build kustomization %s: %w", path, result.err)
+		}
+
+		return decodeManifests(result.data, path)
+	}
+}
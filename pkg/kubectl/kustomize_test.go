@@ -0,0 +1,146 @@
+package kubectl
+
+import (
+	"context"
+	"fmt"
+	"net/http/cgi"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Arneproductions/go-kube/internal/resources"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+/*
+serveGitRepository commits the kustomization of a config map with the name to a git repository, and serves the
+repository over http with git http-backend. It returns the URL of the repository.
+*/
+func serveGitRepository(t *testing.T, name string) string {
+	gitPath, err := exec.LookPath("git")
+	if err != nil {
+		t.Skip("git is not available")
+	}
+
+	root := t.TempDir()
+	repo := filepath.Join(root, "manifests.git")
+	base := writeTestKustomization(t, name)
+
+	git := func(args ...string) {
+		cmd := exec.Command(gitPath, args...)
+		cmd.Dir = repo
+		cmd.Env = append(os.Environ(), "GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@go-kube.io", "GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@go-kube.io")
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+
+	require.NoError(t, os.MkdirAll(filepath.Join(repo, "base"), 0755))
+	for _, file := range []string{"kustomization.yaml", "configmap.yaml"} {
+		data, err := os.ReadFile(filepath.Join(base, file))
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(filepath.Join(repo, "base", file), data, 0644))
+	}
+
+	git("init", "--initial-branch", "main")
+	git("add", ".")
+	git("commit", "-m", "Add base")
+	git("config", "http.receivepack", "false")
+
+	server := httptest.NewServer(&cgi.Handler{
+		Path: gitPath,
+		Args: []string{"http-backend"},
+		Env:  []string{"GIT_PROJECT_ROOT=" + root, "GIT_HTTP_EXPORT_ALL=1"},
+	})
+	t.Cleanup(server.Close)
+
+	return server.URL + "/manifests.git"
+}
+
+// writeRemoteKustomization writes a kustomization with the remote base to a temporary directory
+func writeRemoteKustomization(t *testing.T, remoteBase string) string {
+	dir := t.TempDir()
+	kustomization := fmt.Sprintf("resources:\n- %s\n", remoteBase)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "kustomization.yaml"), []byte(kustomization), 0644))
+
+	return dir
+}
+
+func TestBuildKustomization(t *testing.T) {
+	t.Run("buildKustomization_should_resolve_remote_bases", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		repoURL := serveGitRepository(t, "remote")
+		dir := writeRemoteKustomization(t, repoURL+"//base?ref=main")
+
+		objs, err := buildKustomization(ctx, LoadRestrictorRootOnly, dir)
+		require.NoError(t, err)
+		require.Len(t, objs, 1)
+		assert.Equal(t, "remote", objs[0].GetName())
+	})
+
+	t.Run("buildKustomization_should_respect_the_LoadRestrictor", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		// The kustomization loads a file outside of its own directory
+		base := writeTestKustomization(t, "outside")
+		dir := t.TempDir()
+		kustomization := fmt.Sprintf("resources:\n- %s\n", filepath.Join(base, "configmap.yaml"))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "kustomization.yaml"), []byte(kustomization), 0644))
+
+		_, err := buildKustomization(ctx, LoadRestrictorRootOnly, dir)
+		assert.Error(t, err)
+
+		objs, err := buildKustomization(ctx, LoadRestrictorNone, dir)
+		require.NoError(t, err)
+		require.Len(t, objs, 1)
+	})
+
+	t.Run("buildKustomization_should_give_up_when_the_context_is_done", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := buildKustomization(ctx, LoadRestrictorRootOnly, writeTestKustomization(t, "cancelled"))
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+}
+
+func TestApplyKustomization(t *testing.T) {
+	c := resources.NewEphemeralCluster()
+	require.NoError(t, c.Start())
+
+	t.Cleanup(func() {
+		require.NoError(t, c.Stop())
+	})
+
+	t.Run("ApplyKustomization_should_apply_remote_bases", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		name := fmt.Sprintf("test-cm-%s", uuid.New().String())
+		repoURL := serveGitRepository(t, name)
+		dir := writeRemoteKustomization(t, repoURL+"//base?ref=main")
+
+		err := ApplyKustomization(ctx, c.KubeConfigFilePath(), &ApplyKustomizationOptions{BuildTimeout: 30 * time.Second}, dir)
+		require.NoError(t, err)
+
+		t.Cleanup(func() {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			defer cancel()
+
+			_ = c.Client().CoreV1().ConfigMaps("default").Delete(ctx, name, metav1.DeleteOptions{})
+		})
+
+		cm, err := c.Client().CoreV1().ConfigMaps("default").Get(ctx, name, metav1.GetOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, "bar", cm.Data["foo"])
+	})
+}
@@ -0,0 +1,188 @@
+package kubectl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"time"
+
+	"k8s.io/kubectl/pkg/cmd/util"
+)
+
+var (
+	// Guards the package-global fatal error handler of kubectl, see kubectlLock
+	kubectlMutex = make(slotMutex, 1)
+)
+
+/*
+slotMutex is a mutex that can be given up on while waiting for it, unlike sync.Mutex. It is held while its only slot is
+taken.
+*/
+type slotMutex chan struct{}
+
+func (m slotMutex) Lock() {
+	m <- struct{}{}
+}
+
+func (m slotMutex) Unlock() {
+	<-m
+}
+
+/*
+lockContext takes the mutex, unless the context is done or the deadline is reached first, in which case false is
+returned.
+*/
+func (m slotMutex) lockContext(ctx context.Context, deadline time.Time) bool {
+	timer := time.NewTimer(time.Until(deadline))
+	defer timer.Stop()
+
+	select {
+	case m <- struct{}{}:
+		return true
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return false
+	}
+}
+
+/*
+kubectlLock returns the mutex that every kubectl command runs under.
+
+The commands report their errors through the fatal error handler of kubectl, i.e. util.BehaviorOnFatal, which is
+package-global. Two commands running at once would overwrite the handler of each other, and report their errors to the
+wrong caller, so kubectl commands never run in parallel, also not against different clusters. Functions that use the
+API directly instead of a kubectl command, e.g. Get, List or applies with Concurrency, do not take the lock.
+*/
+func kubectlLock() slotMutex {
+	return kubectlMutex
+}
+
+/*
+commandLock is the kubectl lock held for a single kubectl command, see lockKubectl.
+*/
+type commandLock struct {
+	mu        slotMutex
+	operation *operationTracker
+	handedOff bool
+}
+
+/*
+beginKubectl registers the kubectl command described by operation as an operation in flight, see Shutdown, and takes
+the kubectl lock for it like lockKubectl. Like the lock, the operation ends on unlock, unless the command was started
+with run, in which case it ends once the command is done, such that Shutdown also waits for commands that timed out.
+*/
+func beginKubectl(ctx context.Context, operation string) (*commandLock, error) {
+	tracker := operations
+	if err := tracker.begin(); err != nil {
+		return nil, err
+	}
+
+	lock, err := lockKubectl(ctx, operation)
+	if err != nil {
+		tracker.end()
+		return nil, err
+	}
+
+	lock.operation = tracker
+
+	return lock, nil
+}
+
+/*
+lockKubectl takes the kubectl lock for the kubectl command described by operation. The caller defers unlock, which
+releases the lock unless the command was started with run, in which case the command releases it once it is done.
+
+The lock is waited for until the context is done, or its deadline is reached, like the command itself, see
+watchContext. A command that timed out may hold the lock until kubectl gives up on it, which must not keep the commands
+after it waiting beyond their own deadlines.
+*/
+func lockKubectl(ctx context.Context, operation string) (*commandLock, error) {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(15 * time.Second) // The same arbitrary deadline as watchContext
+	}
+
+	timeLeft := time.Until(deadline)
+
+	mu := kubectlLock()
+	if !mu.lockContext(ctx, deadline) {
+		operation = fmt.Sprintf("waiting for the kubectl lock for %s", operation)
+		if ctx.Err() != nil && !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return nil, cancelledError(operation, ctx.Err(), &syncBuffer{}, &syncBuffer{})
+		}
+
+		return nil, deadlineError(operation, timeLeft, ok, &syncBuffer{}, &syncBuffer{})
+	}
+
+	return &commandLock{mu: mu}, nil
+}
+
+func (l *commandLock) unlock() {
+	if !l.handedOff {
+		l.release()
+	}
+}
+
+func (l *commandLock) release() {
+	l.mu.Unlock()
+
+	if l.operation != nil {
+		l.operation.end()
+	}
+}
+
+/*
+run starts the kubectl command in a goroutine, and sends its outcome to errChan. The fatal errors of the command are
+sent to errChan as well, see onFatal.
+
+A command that timed out keeps running after the caller returned, and may still run into a fatal error. The goroutine
+of the command therefore holds the lock, the fatal error handler and the operation, until the command is done, such that the error
+neither ends up with the next command, nor exits the process through the default handler of kubectl. The errChan must
+have room for the outcome of the command when nobody receives anymore, see newErrChan.
+*/
+func (l *commandLock) run(errChan chan<- error, streamOut, streamErr *syncBuffer, cmd func() error) {
+	restore := onFatal(errChan, streamOut, streamErr)
+	l.handedOff = true
+
+	go func() {
+		// The deferred calls also run when the fatal error handler stops the goroutine
+		defer l.release()
+		defer restore()
+
+		errChan <- cmd()
+	}()
+}
+
+/*
+newErrChan returns the channel that a kubectl command reports its outcome to. It has room for both the outcome of the
+command and the error of watchContext, such that the goroutine of a command that timed out never blocks on reporting
+its outcome after the caller returned.
+*/
+func newErrChan() chan error {
+	return make(chan error, 2)
+}
+
+/*
+onFatal sets the fatal error handler of kubectl to send the fatal errors of a command to errChan, along with what the
+command wrote before then, and returns the function restoring the default handler. The kubectl lock must be held.
+
+The handler stops the goroutine that the command runs in, as kubectl expects the process to exit on a fatal error and
+would otherwise carry on with the failed command, e.g. with a nil result.
+*/
+func onFatal(errChan chan<- error, streamOut, streamErr *syncBuffer) func() {
+	util.BehaviorOnFatal(func(msg string, errCode int) {
+		errChan <- fmt.Errorf(
+			"fatal error: %s\nerror code: %d\nout stream: %s\nerror stream: %s\n",
+			msg,
+			errCode,
+			streamOut.String(),
+			streamErr.String(),
+		)
+
+		runtime.Goexit()
+	})
+
+	return util.DefaultBehaviorOnFatal
+}
@@ -0,0 +1,151 @@
+package kubectl
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// unreachableKubeConfig points to a server that is never reached, as the commands fail on their files first
+const unreachableKubeConfig = `
+apiVersion: v1
+kind: Config
+clusters:
+- name: unreachable
+  cluster:
+    server: https://127.0.0.1:1
+contexts:
+- name: unreachable
+  context:
+    cluster: unreachable
+    user: unreachable
+current-context: unreachable
+users:
+- name: unreachable
+  user:
+    token: not-a-real-token
+`
+
+func TestKubectlLock(t *testing.T) {
+	t.Run("kubectlLock_should_serialize_all_kubectl_commands", func(t *testing.T) {
+		first := kubectlLock()
+		first.Lock()
+
+		acquired := make(chan struct{})
+		go func() {
+			second := kubectlLock()
+			second.Lock()
+			defer second.Unlock()
+
+			close(acquired)
+		}()
+
+		select {
+		case <-acquired:
+			assert.Fail(t, "kubectl lock was not serialized")
+		case <-time.After(200 * time.Millisecond):
+		}
+
+		first.Unlock()
+
+		select {
+		case <-acquired:
+		case <-time.After(time.Second):
+			assert.Fail(t, "kubectl lock was never released")
+		}
+	})
+
+	t.Run("concurrent_applies_and_deletes_should_get_their_own_errors", func(t *testing.T) {
+		dir := t.TempDir()
+
+		// Every command runs against its own kubeconfig, as a test against several clusters would
+		kubeconfigs := []string{}
+		for i := 0; i < 2; i++ {
+			path := filepath.Join(dir, fmt.Sprintf("kubeconfig-%d", i))
+			require.NoError(t, os.WriteFile(path, []byte(unreachableKubeConfig), 0o600))
+
+			kubeconfigs = append(kubeconfigs, path)
+		}
+
+		wg := &sync.WaitGroup{}
+		for i := 0; i < 10; i++ {
+			wg.Add(2)
+
+			applyPath := filepath.Join(dir, fmt.Sprintf("missing-apply-%d.yaml", i))
+			deletePath := filepath.Join(dir, fmt.Sprintf("missing-delete-%d.yaml", i))
+
+			go func() {
+				defer wg.Done()
+
+				ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+				defer cancel()
+
+				err := ApplyManifests(ctx, kubeconfigs[0], &ApplyManifestsOptions{}, applyPath)
+				if assert.Error(t, err) {
+					assert.Contains(t, err.Error(), applyPath)
+					assert.NotContains(t, err.Error(), "missing-delete")
+				}
+			}()
+
+			go func() {
+				defer wg.Done()
+
+				ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+				defer cancel()
+
+				err := DeleteManifests(ctx, kubeconfigs[1], deletePath)
+				if assert.Error(t, err) {
+					assert.Contains(t, err.Error(), deletePath)
+					assert.NotContains(t, err.Error(), "missing-apply")
+				}
+			}()
+		}
+		wg.Wait()
+	})
+}
+
+func TestLockKubectl(t *testing.T) {
+	t.Run("lockKubectl_should_give_up_on_the_lock_at_the_deadline", func(t *testing.T) {
+		mu := kubectlLock()
+		mu.Lock()
+		defer mu.Unlock()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+
+		_, err := lockKubectl(ctx, "kubectl apply")
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Contains(t, err.Error(), "kubectl apply")
+	})
+
+	t.Run("lockKubectl_should_give_up_on_the_lock_when_the_context_is_cancelled", func(t *testing.T) {
+		mu := kubectlLock()
+		mu.Lock()
+		defer mu.Unlock()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := lockKubectl(ctx, "kubectl delete")
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("lockKubectl_should_release_the_lock_on_unlock", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+
+		for i := 0; i < 2; i++ {
+			lock, err := lockKubectl(ctx, "kubectl apply")
+			require.NoError(t, err)
+
+			lock.unlock()
+		}
+	})
+}
@@ -0,0 +1,182 @@
+package kubectl
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+type LogsOptions struct {
+	/*
+		The container to read logs from. When empty, logs are read from every container of the pods
+	*/
+	Container string
+	/*
+		Keeps streaming the logs until the context is done i.e. kubectl logs --follow
+	*/
+	Follow bool
+	/*
+		Only read the given number of most recent lines from each container. All lines are read when zero
+	*/
+	TailLines int64
+}
+
+/*
+LogsBySelector writes the logs of every pod in the namespace matching the label selector to w, in the cluster that the
+kubeconfigPath points to. Each line is prefixed with the pod and container it came from, like kubectl logs --prefix.
+
+When following logs, the lines of the pods are interleaved as they arrive, and LogsBySelector blocks until the context is done.
+
+Example:
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	err := LogsBySelector(ctx, "/path/to/kubeconfig", &LogsOptions{}, "default", "app=nginx", os.Stdout)
+	if err != nil {
+		// Handle error
+	}
+*/
+func LogsBySelector(ctx context.Context, kubeconfigPath string, opts *LogsOptions, namespace, selector string, w io.Writer) error {
+	if kubeconfigPath == "" {
+		return fmt.Errorf("kubeconfig path cannot be empty")
+	}
+
+	if opts == nil {
+		return fmt.Errorf("options cannot be nil")
+	}
+
+	clientset, err := newFactory(kubeconfigPath).KubernetesClientSet()
+	if err != nil {
+		return fmt.Errorf("could not create clientset: %w", err)
+	}
+
+	pods, err := clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return fmt.Errorf("could not list pods matching %s: %w", selector, err)
+	}
+
+	if len(pods.Items) == 0 {
+		return fmt.Errorf("no pods found in namespace %s matching %s", namespace, selector)
+	}
+
+	lw := &lineWriter{w: w}
+
+	if !opts.Follow {
+		for _, pod := range pods.Items {
+			for _, container := range logContainers(pod, opts.Container) {
+				err := streamLogs(ctx, clientset, opts, pod, container, lw)
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		return nil
+	}
+
+	// When following logs we need to stream from every container at once
+	errs := make(chan error)
+	wg := &sync.WaitGroup{}
+	for _, pod := range pods.Items {
+		for _, container := range logContainers(pod, opts.Container) {
+			wg.Add(1)
+
+			go func(pod corev1.Pod, container string) {
+				defer wg.Done()
+
+				errs <- streamLogs(ctx, clientset, opts, pod, container, lw)
+			}(pod, container)
+		}
+	}
+
+	go func() {
+		wg.Wait()
+		close(errs)
+	}()
+
+	var firstErr error
+	for err := range errs {
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	return firstErr
+}
+
+/*
+logContainers returns the containers of the pod to read logs from.
+*/
+func logContainers(pod corev1.Pod, container string) []string {
+	if container != "" {
+		return []string{container}
+	}
+
+	containers := []string{}
+	for _, c := range pod.Spec.Containers {
+		containers = append(containers, c.Name)
+	}
+
+	return containers
+}
+
+/*
+streamLogs writes the logs of the container to the lineWriter, prefixing every line with the pod and container.
+*/
+func streamLogs(ctx context.Context, clientset kubernetes.Interface, opts *LogsOptions, pod corev1.Pod, container string, lw *lineWriter) error {
+	logOpts := &corev1.PodLogOptions{
+		Container: container,
+		Follow:    opts.Follow,
+	}
+
+	if opts.TailLines > 0 {
+		logOpts.TailLines = &opts.TailLines
+	}
+
+	stream, err := clientset.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, logOpts).Stream(ctx)
+	if err != nil {
+		return fmt.Errorf("could not stream logs of pod %s container %s: %w", pod.Name, container, err)
+	}
+	defer stream.Close()
+
+	prefix := fmt.Sprintf("[pod/%s/%s] ", pod.Name, container)
+
+	scanner := bufio.NewScanner(stream)
+	for scanner.Scan() {
+		err := lw.writeLine(prefix + scanner.Text())
+		if err != nil {
+			return err
+		}
+	}
+
+	// A followed stream ends with an error when the context is done, which is how following is stopped
+	if err := scanner.Err(); err != nil && ctx.Err() == nil {
+		return fmt.Errorf("could not read logs of pod %s container %s: %w", pod.Name, container, err)
+	}
+
+	return nil
+}
+
+/*
+lineWriter writes whole lines to the underlying writer, such that lines from concurrent streams are not mixed.
+*/
+type lineWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (lw *lineWriter) writeLine(line string) error {
+	lw.mu.Lock()
+	defer lw.mu.Unlock()
+
+	_, err := fmt.Fprintln(lw.w, line)
+
+	return err
+}
@@ -0,0 +1,105 @@
+package kubectl
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Arneproductions/go-kube/internal/resources"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+func TestLogsBySelector(t *testing.T) {
+	c := resources.NewEphemeralCluster()
+	require.NoError(t, c.Start())
+
+	t.Cleanup(func() {
+		require.NoError(t, c.Stop())
+	})
+
+	t.Run("LogsBySelector_reads_logs_from_every_pod", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Minute)
+		defer cancel()
+
+		name := fmt.Sprintf("test-logs-%s", uuid.New().String())
+		manifest := writeTestManifest(t, loggingDeploymentManifest(name, 2))
+
+		err := applyFunc(ctx, c.KubeConfigFilePath(), &applyOptions{}, manifest)
+		require.NoError(t, err)
+
+		t.Cleanup(func() {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			defer cancel()
+
+			_ = c.Client().AppsV1().Deployments("default").Delete(ctx, name, metav1.DeleteOptions{})
+		})
+
+		selector := fmt.Sprintf("app=%s", name)
+
+		// Wait for both replicas to have started logging
+		var pods *corev1.PodList
+		err = wait.PollUntilContextCancel(ctx, time.Second, true, func(ctx context.Context) (bool, error) {
+			pods, err = c.Client().CoreV1().Pods("default").List(ctx, metav1.ListOptions{LabelSelector: selector})
+			if err != nil || len(pods.Items) != 2 {
+				return false, nil
+			}
+
+			for _, pod := range pods.Items {
+				if pod.Status.Phase != corev1.PodRunning {
+					return false, nil
+				}
+			}
+
+			return true, nil
+		})
+		require.NoError(t, err)
+
+		buf := &bytes.Buffer{}
+		err = LogsBySelector(ctx, c.KubeConfigFilePath(), &LogsOptions{}, "default", selector, buf)
+		require.NoError(t, err)
+
+		for _, pod := range pods.Items {
+			assert.Contains(t, buf.String(), fmt.Sprintf("[pod/%s/logger] hello from %s", pod.Name, pod.Name))
+		}
+	})
+
+	t.Run("LogsBySelector_should_error_when_no_pods_match", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		err := LogsBySelector(ctx, c.KubeConfigFilePath(), &LogsOptions{}, "default", "app=does-not-exist", &bytes.Buffer{})
+		assert.Error(t, err)
+	})
+}
+
+func loggingDeploymentManifest(name string, replicas int) string {
+	return fmt.Sprintf(`
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: %[1]s
+  namespace: default
+spec:
+  replicas: %[2]d
+  selector:
+    matchLabels:
+      app: %[1]s
+  template:
+    metadata:
+      labels:
+        app: %[1]s
+    spec:
+      containers:
+      - name: logger
+        image: busybox:1.36
+        command: ["sh", "-c", "echo hello from $HOSTNAME; sleep 3600"]
+`, name, replicas)
+}
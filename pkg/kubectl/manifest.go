@@ -0,0 +1,286 @@
+package kubectl
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/dynamic"
+	sigsyaml "sigs.k8s.io/yaml"
+)
+
+var (
+	// The file extensions that kubectl considers to be manifests when reading directories
+	manifestExtensions = []string{".json", ".yaml", ".yml"}
+)
+
+/*
+readManifests parses the objects from the given files, directories and URLs, in the same order as kubectl would.
+
+Directories are expanded to the manifests they contain, descending into sub directories only when recursive is set.
+*/
+func readManifests(filePaths []string, recursive bool) ([]*unstructured.Unstructured, error) {
+	objs := []*unstructured.Unstructured{}
+
+	for _, filePath := range filePaths {
+		if strings.HasPrefix(filePath, "http://") || strings.HasPrefix(filePath, "https://") {
+			urlObjs, err := readManifestURL(filePath)
+			if err != nil {
+				return nil, err
+			}
+
+			objs = append(objs, urlObjs...)
+			continue
+		}
+
+		files, err := expandManifestPath(filePath, recursive)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, file := range files {
+			data, err := os.ReadFile(file)
+			if err != nil {
+				return nil, fmt.Errorf("could not read manifest %s: %w", file, err)
+			}
+
+			fileObjs, err := decodeManifests(data, file)
+			if err != nil {
+				return nil, err
+			}
+
+			objs = append(objs, fileObjs...)
+		}
+	}
+
+	return objs, nil
+}
+
+/*
+expandManifestPath returns the manifest files that the given path refers to.
+*/
+func expandManifestPath(filePath string, recursive bool) ([]string, error) {
+	info, err := os.Stat(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("could not stat manifest path %s: %w", filePath, err)
+	}
+
+	if !info.IsDir() {
+		return []string{filePath}, nil
+	}
+
+	files := []string{}
+	err = filepath.WalkDir(filePath, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() {
+			if path != filePath && !recursive {
+				return filepath.SkipDir
+			}
+
+			return nil
+		}
+
+		if isManifestFile(path) {
+			files = append(files, path)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not walk manifest directory %s: %w", filePath, err)
+	}
+
+	return files, nil
+}
+
+/*
+filterManifestPaths returns the manifest files of the given paths that the filter accepts, walking directories into
+their files. URLs are given to the filter as they are.
+*/
+func filterManifestPaths(filter func(path string) bool, recursive bool, filePaths ...string) ([]string, error) {
+	filtered := []string{}
+
+	for _, filePath := range filePaths {
+		files := []string{filePath}
+
+		if !strings.HasPrefix(filePath, "http://") && !strings.HasPrefix(filePath, "https://") {
+			var err error
+			files, err = expandManifestPath(filePath, recursive)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		for _, file := range files {
+			if filter(file) {
+				filtered = append(filtered, file)
+			}
+		}
+	}
+
+	if len(filtered) == 0 {
+		return nil, fmt.Errorf("no manifest files match the path filter")
+	}
+
+	return filtered, nil
+}
+
+func isManifestFile(path string) bool {
+	ext := filepath.Ext(path)
+
+	for _, manifestExt := range manifestExtensions {
+		if ext == manifestExt {
+			return true
+		}
+	}
+
+	return false
+}
+
+func readManifestURL(url string) ([]*unstructured.Unstructured, error) {
+	resp, err := http.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch manifest %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("could not fetch manifest %s: unexpected status %s", url, resp.Status)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("could not read manifest %s: %w", url, err)
+	}
+
+	return decodeManifests(data, url)
+}
+
+/*
+decodeManifests decodes every YAML or JSON document in data into an object. List kinds are flattened
+into their items, and empty documents are skipped. The source is only used for error messages.
+*/
+func decodeManifests(data []byte, source string) ([]*unstructured.Unstructured, error) {
+	objs := []*unstructured.Unstructured{}
+	decoder := yaml.NewYAMLOrJSONDecoder(bytes.NewReader(data), 4096)
+
+	for {
+		// We decode each document as raw JSON first, such that the unstructured decoder keeps integers as integers
+		raw := json.RawMessage{}
+		err := decoder.Decode(&raw)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("could not decode manifest %s: %w", source, err)
+		}
+
+		// Documents only containing comments or whitespace decode to nothing
+		trimmed := bytes.TrimSpace(raw)
+		if len(trimmed) == 0 || bytes.Equal(trimmed, []byte("null")) || bytes.Equal(trimmed, []byte("{}")) {
+			continue
+		}
+
+		obj := &unstructured.Unstructured{}
+		err = obj.UnmarshalJSON(raw)
+		if err != nil {
+			return nil, fmt.Errorf("could not decode manifest %s: %w", source, err)
+		}
+
+		if !obj.IsList() {
+			objs = append(objs, obj)
+			continue
+		}
+
+		err = obj.EachListItem(func(item runtime.Object) error {
+			objs = append(objs, item.(*unstructured.Unstructured))
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("could not decode list in manifest %s: %w", source, err)
+		}
+	}
+
+	return objs, nil
+}
+
+/*
+encodeManifests encodes the objects as a multi-document YAML manifest.
+*/
+func encodeManifests(objs []*unstructured.Unstructured) ([]byte, error) {
+	buf := &bytes.Buffer{}
+
+	for _, obj := range objs {
+		data, err := sigsyaml.Marshal(obj.Object)
+		if err != nil {
+			return nil, fmt.Errorf("could not encode %s %s: %w", obj.GetKind(), obj.GetName(), err)
+		}
+
+		buf.WriteString("---\n")
+		buf.Write(data)
+	}
+
+	return buf.Bytes(), nil
+}
+
+/*
+writeManifests writes the objects to a temporary manifest file and returns the path to it.
+The caller is responsible for removing the file.
+*/
+func writeManifests(objs []*unstructured.Unstructured) (string, error) {
+	data, err := encodeManifests(objs)
+	if err != nil {
+		return "", err
+	}
+
+	tmpFile, err := os.CreateTemp("", "go-kube-manifest-*.yaml")
+	if err != nil {
+		return "", fmt.Errorf("could not create temporary manifest file: %w", err)
+	}
+	defer tmpFile.Close()
+
+	_, err = tmpFile.Write(data)
+	if err != nil {
+		os.Remove(tmpFile.Name())
+		return "", fmt.Errorf("could not write temporary manifest file: %w", err)
+	}
+
+	return tmpFile.Name(), nil
+}
+
+/*
+objectClient returns a dynamic client for the resource of the given object. Namespaced resources
+without a namespace are scoped to the defaultNamespace, the same way kubectl does it.
+*/
+func objectClient(dynamicClient dynamic.Interface, mapper meta.RESTMapper, defaultNamespace string, obj *unstructured.Unstructured) (dynamic.ResourceInterface, error) {
+	gvk := obj.GroupVersionKind()
+
+	mapping, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		return nil, fmt.Errorf("could not find resource for %s: %w", gvk.String(), err)
+	}
+
+	if mapping.Scope.Name() != meta.RESTScopeNameNamespace {
+		return dynamicClient.Resource(mapping.Resource), nil
+	}
+
+	namespace := obj.GetNamespace()
+	if namespace == "" {
+		namespace = defaultNamespace
+	}
+
+	return dynamicClient.Resource(mapping.Resource).Namespace(namespace), nil
+}
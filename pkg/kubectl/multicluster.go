@@ -0,0 +1,55 @@
+package kubectl
+
+import (
+	"context"
+	"sync"
+)
+
+/*
+ApplyToClusters applies the given files to every cluster that the kubeconfigPaths point to with the given ApplyManifestsOptions.
+The clusters are applied to concurrently, and the returned map holds the error of the apply for each kubeconfig path.
+The kubectl commands of the applies still run one at a time, as kubectl reports their errors through package-global
+state, so the applies only overlap in what they do around the commands, e.g. waiting for readiness.
+
+Example:
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	errs := ApplyToClusters(
+		ctx,
+		[]string{"/path/to/kubeconfig1", "/path/to/kubeconfig2"},
+		&ApplyManifestsOptions{
+		},
+		"/path/to/manifest1.yaml",
+	)
+	for kubeconfigPath, err := range errs {
+		if err != nil {
+			// Handle error
+		}
+	}
+*/
+func ApplyToClusters(ctx context.Context, kubeconfigPaths []string, opts *ApplyManifestsOptions, filePaths ...string) map[string]error {
+	errs := make(map[string]error, len(kubeconfigPaths))
+	errsMutex := &sync.Mutex{}
+
+	wg := &sync.WaitGroup{}
+	for _, kubeconfigPath := range kubeconfigPaths {
+		wg.Add(1)
+
+		go func(kubeconfigPath string) {
+			defer wg.Done()
+
+			err := ApplyManifests(ctx, kubeconfigPath, opts, filePaths...)
+
+			errsMutex.Lock()
+			defer errsMutex.Unlock()
+
+			errs[kubeconfigPath] = err
+		}(kubeconfigPath)
+	}
+
+	wg.Wait()
+
+	return errs
+}
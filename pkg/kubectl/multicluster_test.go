@@ -0,0 +1,54 @@
+package kubectl
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Arneproductions/go-kube/internal/resources"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestApplyToClusters(t *testing.T) {
+	clusters := []*resources.EphemeralCluster{
+		resources.NewEphemeralCluster(),
+		resources.NewEphemeralCluster(),
+	}
+
+	for _, c := range clusters {
+		require.NoError(t, c.Start())
+
+		c := c
+		t.Cleanup(func() {
+			require.NoError(t, c.Stop())
+		})
+	}
+
+	t.Run("ApplyToClusters_applies_to_every_cluster", func(t *testing.T) {
+		randomNS := fmt.Sprintf("test-ns-%s", uuid.New().String())
+		manifest := writeTestManifest(t, namespaceManifest(randomNS))
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		kubeconfigPaths := []string{}
+		for _, c := range clusters {
+			kubeconfigPaths = append(kubeconfigPaths, c.KubeConfigFilePath())
+		}
+
+		errs := ApplyToClusters(ctx, kubeconfigPaths, &ApplyManifestsOptions{}, manifest)
+		require.Len(t, errs, len(clusters))
+
+		for _, c := range clusters {
+			assert.NoError(t, errs[c.KubeConfigFilePath()])
+
+			_, err := c.Client().CoreV1().Namespaces().Get(ctx, randomNS, metav1.GetOptions{})
+			assert.NoError(t, err)
+		}
+	})
+}
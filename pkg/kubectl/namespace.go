@@ -0,0 +1,187 @@
+package kubectl
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+)
+
+/*
+ensureNamespaces creates the namespaces that the manifests define or place objects in, if they do not exist already.
+The namespaces are created bare apart from the labels, the manifests are expected to apply the rest of a namespace
+definition afterwards.
+*/
+func ensureNamespaces(ctx context.Context, kubeconfigPath string, labels map[string]string, recursive bool, filePaths ...string) error {
+	objs, err := readManifests(filePaths, recursive)
+	if err != nil {
+		return err
+	}
+
+	namespaces := referencedNamespaces(objs)
+	if len(namespaces) == 0 {
+		return nil
+	}
+
+	clientset, err := newFactory(kubeconfigPath).KubernetesClientSet()
+	if err != nil {
+		return fmt.Errorf("could not create clientset: %w", err)
+	}
+
+	for _, namespace := range namespaces {
+		ns := &corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   namespace,
+				Labels: labels,
+			},
+		}
+
+		_, err := clientset.CoreV1().Namespaces().Create(ctx, ns, metav1.CreateOptions{FieldManager: FieldManager})
+		if err != nil && !apierrors.IsAlreadyExists(err) {
+			return fmt.Errorf("could not create namespace %s: %w", namespace, err)
+		}
+	}
+
+	return nil
+}
+
+/*
+referencedNamespaces returns the namespaces defined by or referenced in the objects, in the order they first appear.
+*/
+func referencedNamespaces(objs []*unstructured.Unstructured) []string {
+	seen := map[string]bool{}
+	namespaces := []string{}
+
+	for _, obj := range objs {
+		namespace := obj.GetNamespace()
+		if obj.GetAPIVersion() == "v1" && obj.GetKind() == "Namespace" {
+			namespace = obj.GetName()
+		}
+
+		if namespace == "" || seen[namespace] {
+			continue
+		}
+
+		seen[namespace] = true
+		namespaces = append(namespaces, namespace)
+	}
+
+	return namespaces
+}
+
+/*
+DeleteNamespace deletes the namespace of the cluster that the kubeconfigPath points to. When wait is set, it waits until
+the namespace is fully terminated, including the finalizers of the namespace and its contents. Should the context expire
+before then, the error describes what the namespace is still waiting for.
+
+Example:
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	err := DeleteNamespace(ctx, "/path/to/kubeconfig", "my-namespace", true)
+	if err != nil {
+		// Handle error
+	}
+*/
+func DeleteNamespace(ctx context.Context, kubeconfigPath string, name string, wait bool) error {
+	if err := operations.begin(); err != nil {
+		return err
+	}
+	defer operations.end()
+
+	if kubeconfigPath == "" {
+		return fmt.Errorf("kubeconfig path cannot be empty")
+	}
+
+	if name == "" {
+		return fmt.Errorf("namespace cannot be empty")
+	}
+
+	clientset, err := newFactory(kubeconfigPath).KubernetesClientSet()
+	if err != nil {
+		return fmt.Errorf("could not create clientset: %w", err)
+	}
+
+	err = clientset.CoreV1().Namespaces().Delete(ctx, name, metav1.DeleteOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("could not delete namespace %s: %w", name, err)
+	}
+
+	if !wait {
+		return nil
+	}
+
+	return waitForNamespaceDeletion(ctx, clientset, name)
+}
+
+func waitForNamespaceDeletion(ctx context.Context, clientset kubernetes.Interface, name string) error {
+	var ns *corev1.Namespace
+
+	err := wait.PollUntilContextCancel(ctx, time.Second, true, func(ctx context.Context) (bool, error) {
+		var err error
+
+		ns, err = clientset.CoreV1().Namespaces().Get(ctx, name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			return true, nil
+		}
+		if err != nil {
+			return false, fmt.Errorf("could not get namespace %s: %w", name, err)
+		}
+
+		return false, nil
+	})
+	if err == nil {
+		return nil
+	}
+
+	if ns == nil || ctx.Err() == nil {
+		return err
+	}
+
+	return fmt.Errorf("namespace %s is still terminating: %s: %w", name, terminationBlockers(ns), err)
+}
+
+/*
+terminationBlockers describes what keeps the namespace from terminating, from its finalizers and status conditions.
+*/
+func terminationBlockers(ns *corev1.Namespace) string {
+	blockers := []string{}
+
+	if len(ns.Spec.Finalizers) > 0 {
+		finalizers := []string{}
+		for _, finalizer := range ns.Spec.Finalizers {
+			finalizers = append(finalizers, string(finalizer))
+		}
+
+		blockers = append(blockers, fmt.Sprintf("finalizers %s", strings.Join(finalizers, ", ")))
+	}
+
+	for _, condition := range ns.Status.Conditions {
+		if condition.Status != corev1.ConditionTrue {
+			continue
+		}
+
+		switch condition.Type {
+		case corev1.NamespaceContentRemaining, corev1.NamespaceFinalizersRemaining, corev1.NamespaceDeletionContentFailure,
+			corev1.NamespaceDeletionDiscoveryFailure, corev1.NamespaceDeletionGVParsingFailure:
+			blockers = append(blockers, fmt.Sprintf("%s: %s", condition.Type, condition.Message))
+		}
+	}
+
+	if len(blockers) == 0 {
+		return "no finalizers or conditions reported"
+	}
+
+	return strings.Join(blockers, "; ")
+}
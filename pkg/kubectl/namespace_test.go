@@ -0,0 +1,168 @@
+package kubectl
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Arneproductions/go-kube/internal/resources"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestEnsureNamespaces(t *testing.T) {
+	c := resources.NewEphemeralCluster()
+	require.NoError(t, c.Start())
+
+	t.Cleanup(func() {
+		require.NoError(t, c.Stop())
+	})
+
+	t.Run("ApplyManifests_should_apply_namespace_and_its_objects_in_one_call", func(t *testing.T) {
+		// We apply a few bundles, as the ordering issue does not show up on every apply
+		for i := 0; i < 3; i++ {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			defer cancel()
+
+			namespace := fmt.Sprintf("test-ns-%s", uuid.New().String())
+			manifest := writeTestManifest(t, namespaceManifest(namespace)+fmt.Sprintf(`
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: test-config
+  namespace: %s
+data:
+  foo: bar
+`, namespace))
+
+			err := ApplyManifests(ctx, c.KubeConfigFilePath(), &ApplyManifestsOptions{EnsureNamespaces: true}, manifest)
+			require.NoError(t, err)
+
+			t.Cleanup(func() {
+				ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+				defer cancel()
+
+				_ = c.Client().CoreV1().Namespaces().Delete(ctx, namespace, metav1.DeleteOptions{})
+			})
+
+			cm, err := c.Client().CoreV1().ConfigMaps(namespace).Get(ctx, "test-config", metav1.GetOptions{})
+			require.NoError(t, err)
+			assert.Equal(t, "bar", cm.Data["foo"])
+		}
+	})
+
+	t.Run("ApplyManifests_should_apply_when_namespaces_exist", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		name := fmt.Sprintf("test-cm-%s", uuid.New().String())
+		manifest := writeTestManifest(t, labelledConfigMapManifest(name, "ensure-namespaces"))
+
+		err := ApplyManifests(ctx, c.KubeConfigFilePath(), &ApplyManifestsOptions{EnsureNamespaces: true}, manifest)
+		require.NoError(t, err)
+
+		t.Cleanup(func() {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			defer cancel()
+
+			_ = c.Client().CoreV1().ConfigMaps("default").Delete(ctx, name, metav1.DeleteOptions{})
+		})
+	})
+}
+
+func TestReferencedNamespaces(t *testing.T) {
+	t.Run("referencedNamespaces_should_return_defined_and_referenced_namespaces_once", func(t *testing.T) {
+		objs := mustDecodeManifests(t, namespaceManifest("first")+`
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: in-first
+  namespace: first
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: in-second
+  namespace: second
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: in-default
+`)
+
+		assert.Equal(t, []string{"first", "second"}, referencedNamespaces(objs))
+	})
+}
+
+func TestDeleteNamespace(t *testing.T) {
+	c := resources.NewEphemeralCluster()
+	require.NoError(t, c.Start())
+
+	t.Cleanup(func() {
+		require.NoError(t, c.Stop())
+	})
+
+	t.Run("DeleteNamespace_should_wait_until_namespace_is_gone", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+		defer cancel()
+
+		namespace := fmt.Sprintf("test-ns-%s", uuid.New().String())
+
+		_, err := c.Client().CoreV1().Namespaces().Create(ctx, &corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{Name: namespace},
+		}, metav1.CreateOptions{})
+		require.NoError(t, err)
+
+		err = DeleteNamespace(ctx, c.KubeConfigFilePath(), namespace, true)
+		require.NoError(t, err)
+
+		_, err = c.Client().CoreV1().Namespaces().Get(ctx, namespace, metav1.GetOptions{})
+		assert.True(t, apierrors.IsNotFound(err))
+	})
+
+	t.Run("DeleteNamespace_should_ignore_missing_namespace", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		err := DeleteNamespace(ctx, c.KubeConfigFilePath(), fmt.Sprintf("test-ns-%s", uuid.New().String()), true)
+		assert.NoError(t, err)
+	})
+}
+
+func TestTerminationBlockers(t *testing.T) {
+	t.Run("terminationBlockers_should_describe_finalizers_and_conditions", func(t *testing.T) {
+		ns := &corev1.Namespace{
+			Spec: corev1.NamespaceSpec{
+				Finalizers: []corev1.FinalizerName{corev1.FinalizerKubernetes},
+			},
+			Status: corev1.NamespaceStatus{
+				Conditions: []corev1.NamespaceCondition{
+					{
+						Type:    corev1.NamespaceFinalizersRemaining,
+						Status:  corev1.ConditionTrue,
+						Message: "Some content in the namespace has finalizers remaining: example.com/stuck in 1 resource instances",
+					},
+					{
+						Type:   corev1.NamespaceDeletionDiscoveryFailure,
+						Status: corev1.ConditionFalse,
+					},
+				},
+			},
+		}
+
+		blockers := terminationBlockers(ns)
+
+		assert.Contains(t, blockers, "finalizers kubernetes")
+		assert.Contains(t, blockers, "example.com/stuck")
+		assert.NotContains(t, blockers, string(corev1.NamespaceDeletionDiscoveryFailure))
+	})
+}
@@ -0,0 +1,141 @@
+package kubectl
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic"
+)
+
+var (
+	// The workloads that WaitForNamespaceReady waits for
+	namespaceWorkloads = []schema.GroupVersionResource{
+		{Group: "apps", Version: "v1", Resource: "deployments"},
+		{Group: "apps", Version: "v1", Resource: "statefulsets"},
+		{Group: "apps", Version: "v1", Resource: "daemonsets"},
+	}
+)
+
+/*
+WaitForNamespaceReady waits until every Deployment, StatefulSet and DaemonSet in the namespace of the cluster that the
+kubeconfigPath points to is ready, using the same readiness checks as the WaitForReady option of ApplyManifests. The
+workloads are discovered again while waiting, so workloads that are created meanwhile are waited for as well.
+
+The error describes every workload that is not ready when the context is done. A Deployment that exceeds its progress
+deadline fails the wait right away.
+
+Example:
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	err := WaitForNamespaceReady(ctx, "/path/to/kubeconfig", "my-app")
+	if err != nil {
+		// Handle error
+	}
+*/
+func WaitForNamespaceReady(ctx context.Context, kubeconfigPath string, namespace string) error {
+	if kubeconfigPath == "" {
+		return fmt.Errorf("kubeconfig path cannot be empty")
+	}
+
+	if namespace == "" {
+		return fmt.Errorf("namespace cannot be empty")
+	}
+
+	dynamicClient, err := newFactory(kubeconfigPath).DynamicClient()
+	if err != nil {
+		return fmt.Errorf("could not create dynamic client: %w", err)
+	}
+
+	notReady := []string{}
+
+	err = wait.PollUntilContextCancel(ctx, time.Second, true, func(ctx context.Context) (bool, error) {
+		notReady, err = notReadyWorkloads(ctx, dynamicClient, namespace)
+		if err != nil {
+			return false, err
+		}
+
+		return len(notReady) == 0, nil
+	})
+	if err != nil && len(notReady) > 0 {
+		return fmt.Errorf("workloads in namespace %s are not ready:\n%s\n: %w", namespace, strings.Join(notReady, "\n"), err)
+	}
+	if err != nil {
+		return fmt.Errorf("could not wait for namespace %s: %w", namespace, err)
+	}
+
+	return nil
+}
+
+/*
+notReadyWorkloads describes the workloads in the namespace that are not ready, e.g. "deployment nginx: 1/3 ready".
+An error is returned when a workload failed to become ready.
+*/
+func notReadyWorkloads(ctx context.Context, dynamicClient dynamic.Interface, namespace string) ([]string, error) {
+	notReady := []string{}
+
+	for _, gvr := range namespaceWorkloads {
+		list, err := dynamicClient.Resource(gvr).Namespace(namespace).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("could not list %s in namespace %s: %w", gvr.Resource, namespace, err)
+		}
+
+		for i := range list.Items {
+			obj := &list.Items[i]
+			kind := strings.ToLower(obj.GetKind())
+
+			if hasFalseCondition(obj, "Progressing", "ProgressDeadlineExceeded") {
+				return nil, fmt.Errorf("%s %s exceeded its progress deadline: %s", kind, obj.GetName(), workloadStatus(obj))
+			}
+
+			if readinessCheck(nil, obj.GroupVersionKind(), true)(obj) {
+				continue
+			}
+
+			notReady = append(notReady, fmt.Sprintf("%s %s: %s", kind, obj.GetName(), workloadStatus(obj)))
+		}
+	}
+	sort.Strings(notReady)
+
+	return notReady, nil
+}
+
+/*
+workloadStatus describes how many replicas of the workload are ready, out of how many are wanted.
+*/
+func workloadStatus(obj *unstructured.Unstructured) string {
+	if obj.GetKind() == "DaemonSet" {
+		desired, _, _ := unstructured.NestedInt64(obj.Object, "status", "desiredNumberScheduled")
+		ready, _, _ := unstructured.NestedInt64(obj.Object, "status", "numberReady")
+
+		return fmt.Sprintf("%d/%d ready", ready, desired)
+	}
+
+	replicas, found, _ := unstructured.NestedInt64(obj.Object, "spec", "replicas")
+	if !found {
+		replicas = 1
+	}
+	ready, _, _ := unstructured.NestedInt64(obj.Object, "status", "readyReplicas")
+
+	return fmt.Sprintf("%d/%d ready", ready, replicas)
+}
+
+func hasFalseCondition(obj *unstructured.Unstructured, conditionType, reason string) bool {
+	conditions, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	for _, c := range conditions {
+		condition, ok := c.(map[string]interface{})
+		if ok && condition["type"] == conditionType && condition["status"] == "False" && condition["reason"] == reason {
+			return true
+		}
+	}
+
+	return false
+}
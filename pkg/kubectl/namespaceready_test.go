@@ -0,0 +1,103 @@
+package kubectl
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Arneproductions/go-kube/internal/resources"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// namespacedDeploymentManifest is a deployment of the given replicas in the namespace
+func namespacedDeploymentManifest(namespace, name string, replicas int) string {
+	return strings.Replace(loggingDeploymentManifest(name, replicas), "namespace: default", "namespace: "+namespace, 1)
+}
+
+func TestWaitForNamespaceReady(t *testing.T) {
+	c := resources.NewEphemeralCluster()
+	require.NoError(t, c.Start())
+
+	t.Cleanup(func() {
+		require.NoError(t, c.Stop())
+	})
+
+	t.Run("WaitForNamespaceReady_should_return_once_every_deployment_is_ready", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Minute)
+		defer cancel()
+
+		namespace := fmt.Sprintf("test-ns-%s", uuid.New().String())
+		manifest := strings.Join([]string{
+			namespaceManifest(namespace),
+			namespacedDeploymentManifest(namespace, "first", 1),
+			namespacedDeploymentManifest(namespace, "second", 2),
+		}, "\n---\n")
+
+		err := ApplyManifests(ctx, c.KubeConfigFilePath(), &ApplyManifestsOptions{}, writeTestManifest(t, manifest))
+		require.NoError(t, err)
+
+		t.Cleanup(func() {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			defer cancel()
+
+			_ = c.Client().CoreV1().Namespaces().Delete(ctx, namespace, metav1.DeleteOptions{})
+		})
+
+		err = WaitForNamespaceReady(ctx, c.KubeConfigFilePath(), namespace)
+		require.NoError(t, err)
+
+		for _, name := range []string{"first", "second"} {
+			deployment, err := c.Client().AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+			require.NoError(t, err)
+			assert.Equal(t, *deployment.Spec.Replicas, deployment.Status.ReadyReplicas)
+		}
+	})
+
+	t.Run("WaitForNamespaceReady_should_describe_the_workloads_that_are_not_ready", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		namespace := fmt.Sprintf("test-ns-%s", uuid.New().String())
+		deployment := strings.Replace(namespacedDeploymentManifest(namespace, "broken", 1), "busybox:1.36", "busybox:does-not-exist-tag", 1)
+		manifest := namespaceManifest(namespace) + "\n---\n" + deployment
+
+		err := ApplyManifests(ctx, c.KubeConfigFilePath(), &ApplyManifestsOptions{}, writeTestManifest(t, manifest))
+		require.NoError(t, err)
+
+		t.Cleanup(func() {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			defer cancel()
+
+			_ = c.Client().CoreV1().Namespaces().Delete(ctx, namespace, metav1.DeleteOptions{})
+		})
+
+		waitCtx, waitCancel := context.WithTimeout(ctx, 15*time.Second)
+		defer waitCancel()
+
+		err = WaitForNamespaceReady(waitCtx, c.KubeConfigFilePath(), namespace)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "deployment broken: 0/1 ready")
+	})
+}
+
+func TestNotReadyWorkloads(t *testing.T) {
+	t.Run("hasFalseCondition_should_detect_exceeded_progress_deadlines", func(t *testing.T) {
+		obj := mustDecodeManifests(t, loggingDeploymentManifest("foo", 1))[0]
+		obj.Object["status"] = map[string]interface{}{
+			"conditions": []interface{}{
+				map[string]interface{}{"type": string(appsv1.DeploymentProgressing), "status": "False", "reason": "ProgressDeadlineExceeded"},
+			},
+		}
+
+		assert.True(t, hasFalseCondition(obj, "Progressing", "ProgressDeadlineExceeded"))
+		assert.False(t, hasFalseCondition(obj, "Available", "MinimumReplicasUnavailable"))
+		assert.Equal(t, "0/1 ready", workloadStatus(obj))
+	})
+}
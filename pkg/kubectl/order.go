@@ -0,0 +1,176 @@
+package kubectl
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+var (
+	/*
+		DefaultInstallOrder is a Helm-like install order, where namespaces and CRDs come first, then RBAC and configuration,
+		and workloads last
+	*/
+	DefaultInstallOrder = []string{
+		"Namespace",
+		"CustomResourceDefinition",
+		"NetworkPolicy",
+		"ResourceQuota",
+		"LimitRange",
+		"PodDisruptionBudget",
+		"ServiceAccount",
+		"Secret",
+		"ConfigMap",
+		"StorageClass",
+		"PersistentVolume",
+		"PersistentVolumeClaim",
+		"ClusterRole",
+		"ClusterRoleBinding",
+		"Role",
+		"RoleBinding",
+		"Service",
+		"DaemonSet",
+		"Pod",
+		"ReplicationController",
+		"ReplicaSet",
+		"Deployment",
+		"HorizontalPodAutoscaler",
+		"StatefulSet",
+		"Job",
+		"CronJob",
+		"IngressClass",
+		"Ingress",
+		"APIService",
+	}
+
+	crdResource = schema.GroupVersionResource{Group: "apiextensions.k8s.io", Version: "v1", Resource: "customresourcedefinitions"}
+)
+
+/*
+applyInOrder applies the objects of the given files grouped by kind, one group at a time in the install order.
+The CRDs of a group are established before the next group is applied, such that the next group can use them.
+*/
+func applyInOrder(ctx context.Context, kubeconfigPath string, opts *ApplyManifestsOptions, filePaths ...string) (*ApplyResult, error) {
+	if opts.Prune {
+		// Pruning after each group would prune the objects of every other group
+		return nil, fmt.Errorf("pruning is not supported when applying in install order")
+	}
+
+	objs, err := readManifests(filePaths, opts.Recursive)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ApplyResult{}
+
+	for _, group := range orderManifests(objs, opts.InstallOrder) {
+		manifestPath, err := writeManifests(group)
+		if err != nil {
+			return nil, err
+		}
+
+		var groupResult *ApplyResult
+		if opts.PerObject {
+			groupResult, err = applyPerObject(ctx, kubeconfigPath, opts, manifestPath)
+		} else {
+			groupResult, err = applyManifestsFunc(ctx, kubeconfigPath, opts, manifestPath)
+		}
+		os.Remove(manifestPath)
+
+		if groupResult != nil {
+			result.merge(groupResult)
+		}
+		if err != nil {
+			return result, err
+		}
+
+		if opts.DryRun == DryRunNone {
+			err = waitForCRDsEstablished(ctx, kubeconfigPath, group)
+			if err != nil {
+				return result, err
+			}
+		}
+	}
+
+	return result, nil
+}
+
+/*
+orderManifests groups the objects by kind in the install order. Kinds that are not in the install order are
+grouped last, and the objects keep their original order within a group.
+*/
+func orderManifests(objs []*unstructured.Unstructured, installOrder []string) [][]*unstructured.Unstructured {
+	rank := map[string]int{}
+	for i, kind := range installOrder {
+		rank[kind] = i
+	}
+
+	kindRank := func(obj *unstructured.Unstructured) int {
+		r, ok := rank[obj.GetKind()]
+		if !ok {
+			return len(installOrder)
+		}
+
+		return r
+	}
+
+	sorted := append([]*unstructured.Unstructured{}, objs...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return kindRank(sorted[i]) < kindRank(sorted[j])
+	})
+
+	groups := [][]*unstructured.Unstructured{}
+	for i, obj := range sorted {
+		if i == 0 || kindRank(sorted[i-1]) != kindRank(obj) {
+			groups = append(groups, []*unstructured.Unstructured{})
+		}
+
+		groups[len(groups)-1] = append(groups[len(groups)-1], obj)
+	}
+
+	return groups
+}
+
+/*
+waitForCRDsEstablished waits until the CRDs among the objects are established, meaning their kinds are served.
+*/
+func waitForCRDsEstablished(ctx context.Context, kubeconfigPath string, objs []*unstructured.Unstructured) error {
+	names := []string{}
+	for _, obj := range objs {
+		if obj.GroupVersionKind().GroupKind() == (schema.GroupKind{Group: "apiextensions.k8s.io", Kind: "CustomResourceDefinition"}) {
+			names = append(names, obj.GetName())
+		}
+	}
+
+	if len(names) == 0 {
+		return nil
+	}
+
+	dynamicClient, err := newFactory(kubeconfigPath).DynamicClient()
+	if err != nil {
+		return fmt.Errorf("could not create dynamic client: %w", err)
+	}
+
+	for _, name := range names {
+		err := wait.PollUntilContextCancel(ctx, 500*time.Millisecond, true, func(ctx context.Context) (bool, error) {
+			crd, err := dynamicClient.Resource(crdResource).Get(ctx, name, metav1.GetOptions{})
+			if err != nil {
+				return false, fmt.Errorf("could not get CRD %s: %w", name, err)
+			}
+
+			return hasCondition(crd, "Established"), nil
+		})
+		if err != nil {
+			return fmt.Errorf("CRD %s was not established: %w", name, err)
+		}
+	}
+
+	return nil
+}
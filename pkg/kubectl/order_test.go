@@ -0,0 +1,89 @@
+package kubectl
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Arneproductions/go-kube/internal/resources"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInstallOrder(t *testing.T) {
+	c := resources.NewEphemeralCluster()
+	require.NoError(t, c.Start())
+
+	t.Cleanup(func() {
+		require.NoError(t, c.Stop())
+	})
+
+	t.Run("ApplyManifests_should_apply_custom_resources_after_their_CRD", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+		defer cancel()
+
+		// The custom resource comes before its CRD, which a single kubectl apply cannot resolve
+		manifest := writeTestManifest(t, widgetManifest("ordered-widget", 1, "")+"---"+widgetCRDManifest)
+
+		result, err := ApplyManifestsWithResult(ctx, c.KubeConfigFilePath(), &ApplyManifestsOptions{
+			InstallOrder: DefaultInstallOrder,
+		}, manifest)
+		require.NoError(t, err)
+
+		require.Len(t, result.Objects, 2)
+		assert.Equal(t, "customresourcedefinition", result.Objects[0].Kind)
+		assert.Equal(t, "widget", result.Objects[1].Kind)
+
+		_, err = getTestWidget(ctx, t, c.KubeConfigFilePath(), "ordered-widget")
+		assert.NoError(t, err)
+	})
+}
+
+func TestOrderManifests(t *testing.T) {
+	t.Run("orderManifests_should_group_kinds_in_install_order", func(t *testing.T) {
+		objs := mustDecodeManifests(t, `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+---
+apiVersion: example.com/v1
+kind: Unknown
+metadata:
+  name: unknown
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: first
+---
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: namespace
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: second
+`)
+
+		groups := orderManifests(objs, DefaultInstallOrder)
+
+		names := [][]string{}
+		for _, group := range groups {
+			groupNames := []string{}
+			for _, obj := range group {
+				groupNames = append(groupNames, obj.GetName())
+			}
+			names = append(names, groupNames)
+		}
+
+		assert.Equal(t, [][]string{
+			{"namespace"},
+			{"first", "second"},
+			{"app"},
+			{"unknown"},
+		}, names)
+	})
+}
@@ -0,0 +1,125 @@
+package kubectl
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	// The API server limits the total size of the annotations of an object, which the last-applied annotation
+	// of a client-side apply has to fit within
+	maxLastAppliedSize = 256 * 1024
+)
+
+/*
+checkObjectSizes fails when any object of the given files is too large to be applied client-side.
+*/
+func checkObjectSizes(recursive bool, filePaths ...string) error {
+	objs, err := readManifests(filePaths, recursive)
+	if err != nil {
+		return err
+	}
+
+	oversized, _, err := splitOversized(objs)
+	if err != nil {
+		return err
+	}
+
+	if len(oversized) == 0 {
+		return nil
+	}
+
+	descriptions := []string{}
+	for _, obj := range oversized {
+		descriptions = append(descriptions, fmt.Sprintf("%s %s", obj.GetKind(), obj.GetName()))
+	}
+
+	return fmt.Errorf(
+		"objects are larger than the %d bytes that the last-applied annotation of a client-side apply can hold, apply them server-side instead: %s",
+		maxLastAppliedSize,
+		strings.Join(descriptions, "; "),
+	)
+}
+
+/*
+applyOversizedServerSide applies the objects of the given files that are too large to be applied client-side
+server-side, before applying the other objects client-side.
+*/
+func applyOversizedServerSide(ctx context.Context, kubeconfigPath string, opts *ApplyManifestsOptions, filePaths ...string) (*ApplyResult, error) {
+	objs, err := readManifests(filePaths, opts.Recursive)
+	if err != nil {
+		return nil, err
+	}
+
+	oversized, rest, err := splitOversized(objs)
+	if err != nil {
+		return nil, err
+	}
+
+	// The options without an oversize policy apply the objects as usual
+	clientSideOpts := *opts
+	clientSideOpts.OversizePolicy = OversizePolicyError
+
+	if len(oversized) == 0 {
+		return applyManifestsFunc(ctx, kubeconfigPath, &clientSideOpts, filePaths...)
+	}
+
+	if opts.Prune {
+		// Pruning with either of the applies would prune the objects of the other
+		return nil, fmt.Errorf("pruning is not supported when oversized objects are applied server-side")
+	}
+
+	serverSideOpts := clientSideOpts
+	serverSideOpts.ServerSide = true
+
+	// The oversized objects are applied first, as they are usually configuration that the other objects depend on
+	result, err := applyObjects(ctx, kubeconfigPath, &serverSideOpts, oversized)
+	if err != nil || len(rest) == 0 {
+		return result, err
+	}
+
+	restResult, err := applyObjects(ctx, kubeconfigPath, &clientSideOpts, rest)
+	if restResult != nil {
+		result.merge(restResult)
+	}
+
+	return result, err
+}
+
+func applyObjects(ctx context.Context, kubeconfigPath string, opts *ApplyManifestsOptions, objs []*unstructured.Unstructured) (*ApplyResult, error) {
+	manifestPath, err := writeManifests(objs)
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(manifestPath)
+
+	return applyManifestsFunc(ctx, kubeconfigPath, opts, manifestPath)
+}
+
+/*
+splitOversized splits the objects into the objects that are too large to be applied client-side, and the rest.
+*/
+func splitOversized(objs []*unstructured.Unstructured) ([]*unstructured.Unstructured, []*unstructured.Unstructured, error) {
+	oversized := []*unstructured.Unstructured{}
+	rest := []*unstructured.Unstructured{}
+
+	for _, obj := range objs {
+		data, err := json.Marshal(obj.Object)
+		if err != nil {
+			return nil, nil, fmt.Errorf("could not encode %s %s: %w", obj.GetKind(), obj.GetName(), err)
+		}
+
+		if len(data) > maxLastAppliedSize {
+			oversized = append(oversized, obj)
+		} else {
+			rest = append(rest, obj)
+		}
+	}
+
+	return oversized, rest, nil
+}
@@ -0,0 +1,100 @@
+package kubectl
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Arneproductions/go-kube/internal/resources"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// oversizedConfigMapManifest returns a config map that is too large for the last-applied annotation
+func oversizedConfigMapManifest(name string) string {
+	return configMapDataManifest(name, "payload", strings.Repeat("x", maxLastAppliedSize+1024))
+}
+
+func TestSplitOversized(t *testing.T) {
+	t.Run("splitOversized_should_split_objects_larger_than_the_annotation_limit", func(t *testing.T) {
+		objs := mustDecodeManifests(t, oversizedConfigMapManifest("big")+"---"+configMapDataManifest("small", "foo", "bar"))
+
+		oversized, rest, err := splitOversized(objs)
+		require.NoError(t, err)
+
+		require.Len(t, oversized, 1)
+		assert.Equal(t, "big", oversized[0].GetName())
+		require.Len(t, rest, 1)
+		assert.Equal(t, "small", rest[0].GetName())
+	})
+
+	t.Run("checkObjectSizes_should_name_the_oversized_objects", func(t *testing.T) {
+		manifest := writeTestManifest(t, oversizedConfigMapManifest("big")+"---"+configMapDataManifest("small", "foo", "bar"))
+
+		err := checkObjectSizes(false, manifest)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "ConfigMap big")
+		assert.NotContains(t, err.Error(), "small")
+	})
+}
+
+func TestOversizePolicy(t *testing.T) {
+	c := resources.NewEphemeralCluster()
+	require.NoError(t, c.Start())
+
+	t.Cleanup(func() {
+		require.NoError(t, c.Stop())
+	})
+
+	t.Run("ApplyManifests_should_fail_on_oversized_objects_by_default", func(t *testing.T) {
+		t.Parallel()
+
+		name := fmt.Sprintf("test-cm-%s", uuid.New().String())
+		manifest := writeTestManifest(t, oversizedConfigMapManifest(name))
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		err := ApplyManifests(ctx, c.KubeConfigFilePath(), &ApplyManifestsOptions{}, manifest)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), name)
+
+		_, err = c.Client().CoreV1().ConfigMaps("default").Get(ctx, name, metav1.GetOptions{})
+		assert.Error(t, err, "the oversized config map should not be applied")
+	})
+
+	t.Run("ApplyManifests_should_apply_oversized_objects_server_side_with_OversizePolicyServerSide", func(t *testing.T) {
+		t.Parallel()
+
+		bigName := fmt.Sprintf("test-cm-%s", uuid.New().String())
+		smallName := fmt.Sprintf("test-cm-%s", uuid.New().String())
+		manifest := writeTestManifest(t, oversizedConfigMapManifest(bigName)+"---"+configMapDataManifest(smallName, "foo", "bar"))
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		t.Cleanup(func() {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			defer cancel()
+
+			_ = c.Client().CoreV1().ConfigMaps("default").Delete(ctx, bigName, metav1.DeleteOptions{})
+			_ = c.Client().CoreV1().ConfigMaps("default").Delete(ctx, smallName, metav1.DeleteOptions{})
+		})
+
+		err := ApplyManifests(ctx, c.KubeConfigFilePath(), &ApplyManifestsOptions{OversizePolicy: OversizePolicyServerSide}, manifest)
+		require.NoError(t, err)
+
+		big, err := c.Client().CoreV1().ConfigMaps("default").Get(ctx, bigName, metav1.GetOptions{})
+		require.NoError(t, err)
+		assert.NotContains(t, big.Annotations, "kubectl.kubernetes.io/last-applied-configuration")
+
+		small, err := c.Client().CoreV1().ConfigMaps("default").Get(ctx, smallName, metav1.GetOptions{})
+		require.NoError(t, err)
+		assert.Contains(t, small.Annotations, "kubectl.kubernetes.io/last-applied-configuration")
+	})
+}
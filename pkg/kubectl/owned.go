@@ -0,0 +1,195 @@
+package kubectl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+)
+
+/*
+DeleteOwnedBy deletes every object that the owner is an owner of, i.e. whose owner references name the owner, in the
+cluster that the kubeconfigPath points to. Every kind that can be listed and deleted is searched, in the namespace for
+namespaced owners, or the namespace of the current context when it is empty, and in every namespace and the cluster
+scope for cluster scoped owners, whose namespace is left empty. The owner itself is not deleted.
+
+This deletes the dependents without the garbage collector, e.g. when it is disabled, or foreground deletion is too slow.
+When the owner still exists, only the objects referencing its UID are deleted. When it is gone, the objects referencing
+its kind and name are. Only the direct dependents are deleted, the garbage collector handles their own dependents.
+
+Kinds that cannot be listed, e.g. as they are forbidden, are skipped. Every other kind is searched even when some of
+them fail, and the errors are returned together.
+
+Example:
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	err := DeleteOwnedBy(ctx, "/path/to/kubeconfig", schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"}, "nginx", "default")
+	if err != nil {
+		// Handle error
+	}
+*/
+func DeleteOwnedBy(ctx context.Context, kubeconfigPath string, ownerGVK schema.GroupVersionKind, ownerName, namespace string) error {
+	if kubeconfigPath == "" {
+		return fmt.Errorf("kubeconfig path cannot be empty")
+	}
+
+	if ownerGVK.Kind == "" || ownerName == "" {
+		return fmt.Errorf("owner kind and name cannot be empty")
+	}
+
+	f := newFactory(kubeconfigPath)
+
+	dynamicClient, err := f.DynamicClient()
+	if err != nil {
+		return fmt.Errorf("could not create dynamic client: %w", err)
+	}
+
+	mapper, err := f.ToRESTMapper()
+	if err != nil {
+		return fmt.Errorf("could not create rest mapper: %w", err)
+	}
+
+	discoveryClient, err := f.ToDiscoveryClient()
+	if err != nil {
+		return fmt.Errorf("could not create discovery client: %w", err)
+	}
+
+	mapping, err := mapper.RESTMapping(ownerGVK.GroupKind(), ownerGVK.Version)
+	if err != nil {
+		return fmt.Errorf("could not find resource for %s: %w", ownerGVK.String(), err)
+	}
+
+	if mapping.Scope.Name() != meta.RESTScopeNameNamespace {
+		namespace = ""
+	} else if namespace == "" {
+		// An empty namespace would list the objects of every namespace, so we keep to the namespace of the context
+		namespace, _, err = f.ToRawKubeConfigLoader().Namespace()
+		if err != nil {
+			return fmt.Errorf("could not determine default namespace: %w", err)
+		}
+	}
+
+	ownerClient := dynamicClient.Resource(mapping.Resource).Namespace(namespace)
+
+	var ownerUID types.UID
+	owner, err := ownerClient.Get(ctx, ownerName, metav1.GetOptions{})
+	if err == nil {
+		ownerUID = owner.GetUID()
+	} else if !apierrors.IsNotFound(err) {
+		return fmt.Errorf("could not get %s %s: %w", ownerGVK.Kind, ownerName, err)
+	}
+
+	resources, err := discovery.ServerPreferredResources(discoveryClient)
+	if err != nil && !discovery.IsGroupDiscoveryFailedError(err) {
+		return fmt.Errorf("could not discover the resources of the cluster: %w", err)
+	}
+	resources = discovery.FilteredBy(discovery.SupportsAllVerbs{Verbs: []string{"list", "delete"}}, resources)
+
+	errs := []error{}
+
+	for _, list := range resources {
+		gv, err := schema.ParseGroupVersion(list.GroupVersion)
+		if err != nil {
+			continue
+		}
+
+		for _, resource := range list.APIResources {
+			// A namespaced owner can only own objects in its own namespace
+			if strings.Contains(resource.Name, "/") || (namespace != "" && !resource.Namespaced) {
+				continue
+			}
+
+			gvr := gv.WithResource(resource.Name)
+
+			var client dynamic.ResourceInterface = dynamicClient.Resource(gvr)
+			if resource.Namespaced {
+				client = dynamicClient.Resource(gvr).Namespace(namespace)
+			}
+
+			objs, err := client.List(ctx, metav1.ListOptions{})
+			if isUnlistable(err) {
+				continue
+			}
+			if err != nil {
+				errs = append(errs, fmt.Errorf("could not list %s: %w", resource.Name, err))
+				continue
+			}
+
+			for _, obj := range objs.Items {
+				if !ownedBy(&obj, ownerGVK.GroupKind(), ownerName, ownerUID) {
+					continue
+				}
+
+				err := deleteOwned(ctx, dynamicClient, gvr, &obj)
+				if err != nil {
+					errs = append(errs, err)
+				}
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("could not delete every object owned by %s %s: %w", ownerGVK.Kind, ownerName, errors.Join(errs...))
+	}
+
+	return nil
+}
+
+/*
+isUnlistable reports whether listing a resource failed because the resource cannot be listed by us, e.g. as we are not
+allowed to, or it was removed since it was discovered, such that it is skipped.
+*/
+func isUnlistable(err error) bool {
+	return apierrors.IsForbidden(err) || apierrors.IsNotFound(err) || apierrors.IsMethodNotSupported(err)
+}
+
+/*
+ownedBy reports whether the object has an owner reference to the owner, by its UID when it is known, and by its kind
+and name otherwise.
+*/
+func ownedBy(obj *unstructured.Unstructured, ownerGK schema.GroupKind, ownerName string, ownerUID types.UID) bool {
+	for _, ref := range obj.GetOwnerReferences() {
+		if ownerUID != "" {
+			if ref.UID == ownerUID {
+				return true
+			}
+
+			continue
+		}
+
+		gv, err := schema.ParseGroupVersion(ref.APIVersion)
+		if err == nil && gv.Group == ownerGK.Group && ref.Kind == ownerGK.Kind && ref.Name == ownerName {
+			return true
+		}
+	}
+
+	return false
+}
+
+/*
+deleteOwned deletes the object unless it was recreated since it was listed, and treats objects that are already gone
+as deleted.
+*/
+func deleteOwned(ctx context.Context, dynamicClient dynamic.Interface, gvr schema.GroupVersionResource, obj *unstructured.Unstructured) error {
+	client := dynamicClient.Resource(gvr).Namespace(obj.GetNamespace())
+
+	// The precondition makes sure that we do not delete an object that was recreated with the same name
+	uid := obj.GetUID()
+	err := client.Delete(ctx, obj.GetName(), metav1.DeleteOptions{Preconditions: &metav1.Preconditions{UID: &uid}})
+	if err != nil && !apierrors.IsNotFound(err) && !apierrors.IsConflict(err) {
+		return fmt.Errorf("could not delete %s %s: %w", obj.GetKind(), obj.GetName(), err)
+	}
+
+	return nil
+}
@@ -0,0 +1,146 @@
+package kubectl
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Arneproductions/go-kube/internal/resources"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestOwnedBy(t *testing.T) {
+	obj := &unstructured.Unstructured{}
+	obj.SetOwnerReferences([]metav1.OwnerReference{{APIVersion: "apps/v1", Kind: "Deployment", Name: "nginx", UID: "owner-uid"}})
+
+	deployment := schema.GroupKind{Group: "apps", Kind: "Deployment"}
+
+	t.Run("ownedBy_should_match_the_uid_of_existing_owners", func(t *testing.T) {
+		assert.True(t, ownedBy(obj, deployment, "nginx", "owner-uid"))
+		assert.False(t, ownedBy(obj, deployment, "nginx", "recreated-owner-uid"))
+	})
+
+	t.Run("ownedBy_should_match_the_kind_and_name_of_deleted_owners", func(t *testing.T) {
+		assert.True(t, ownedBy(obj, deployment, "nginx", ""))
+		assert.False(t, ownedBy(obj, deployment, "other", ""))
+		assert.False(t, ownedBy(obj, schema.GroupKind{Group: "apps", Kind: "StatefulSet"}, "nginx", ""))
+	})
+}
+
+func TestIsUnlistable(t *testing.T) {
+	t.Run("isUnlistable_should_skip_resources_that_cannot_be_listed", func(t *testing.T) {
+		resource := schema.GroupResource{Resource: "secrets"}
+
+		assert.True(t, isUnlistable(apierrors.NewForbidden(resource, "", fmt.Errorf("forbidden"))))
+		assert.True(t, isUnlistable(apierrors.NewNotFound(resource, "")))
+		assert.True(t, isUnlistable(apierrors.NewMethodNotSupported(resource, "list")))
+
+		assert.False(t, isUnlistable(nil))
+		assert.False(t, isUnlistable(apierrors.NewInternalError(fmt.Errorf("internal"))))
+	})
+}
+
+func TestDeleteOwnedBy(t *testing.T) {
+	c := resources.NewEphemeralCluster()
+	require.NoError(t, c.Start())
+
+	t.Cleanup(func() {
+		require.NoError(t, c.Stop())
+	})
+
+	t.Run("DeleteOwnedBy_should_delete_exactly_the_children_of_the_owner", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		name := fmt.Sprintf("test-owner-%s", uuid.New().String())
+
+		owner, err := c.Client().CoreV1().ConfigMaps("default").Create(ctx, &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+		}, metav1.CreateOptions{})
+		require.NoError(t, err)
+
+		ownerRefs := []metav1.OwnerReference{{APIVersion: "v1", Kind: "ConfigMap", Name: owner.Name, UID: owner.UID}}
+		otherRefs := []metav1.OwnerReference{{APIVersion: "v1", Kind: "ConfigMap", Name: "other", UID: types.UID(uuid.New().String())}}
+
+		for _, child := range []struct {
+			name      string
+			ownerRefs []metav1.OwnerReference
+		}{
+			{name: name + "-child", ownerRefs: ownerRefs},
+			{name: name + "-unrelated", ownerRefs: otherRefs},
+			{name: name + "-orphan"},
+		} {
+			meta := metav1.ObjectMeta{Name: child.name, OwnerReferences: child.ownerRefs}
+
+			_, err = c.Client().CoreV1().ConfigMaps("default").Create(ctx, &corev1.ConfigMap{ObjectMeta: meta}, metav1.CreateOptions{})
+			require.NoError(t, err)
+
+			_, err = c.Client().CoreV1().Secrets("default").Create(ctx, &corev1.Secret{ObjectMeta: meta}, metav1.CreateOptions{})
+			require.NoError(t, err)
+		}
+
+		err = DeleteOwnedBy(ctx, c.KubeConfigFilePath(), schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"}, name, "default")
+		require.NoError(t, err)
+
+		_, err = c.Client().CoreV1().ConfigMaps("default").Get(ctx, name+"-child", metav1.GetOptions{})
+		assert.True(t, apierrors.IsNotFound(err))
+
+		_, err = c.Client().CoreV1().Secrets("default").Get(ctx, name+"-child", metav1.GetOptions{})
+		assert.True(t, apierrors.IsNotFound(err))
+
+		for _, kept := range []string{name, name + "-unrelated", name + "-orphan"} {
+			_, err = c.Client().CoreV1().ConfigMaps("default").Get(ctx, kept, metav1.GetOptions{})
+			assert.NoError(t, err, kept)
+		}
+
+		for _, kept := range []string{name + "-unrelated", name + "-orphan"} {
+			_, err = c.Client().CoreV1().Secrets("default").Get(ctx, kept, metav1.GetOptions{})
+			assert.NoError(t, err, kept)
+		}
+	})
+
+	t.Run("DeleteOwnedBy_should_keep_to_the_namespace_of_the_context", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		name := fmt.Sprintf("test-owner-%s", uuid.New().String())
+		ns := fmt.Sprintf("test-ns-%s", uuid.New().String())
+
+		_, err := c.Client().CoreV1().Namespaces().Create(ctx, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: ns}}, metav1.CreateOptions{})
+		require.NoError(t, err)
+
+		t.Cleanup(func() {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			defer cancel()
+
+			_ = c.Client().CoreV1().Namespaces().Delete(ctx, ns, metav1.DeleteOptions{})
+		})
+
+		// Owners of the same name in both namespaces are gone, so their children are matched by kind and name
+		for _, namespace := range []string{"default", ns} {
+			_, err = c.Client().CoreV1().ConfigMaps(namespace).Create(ctx, &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+				Name:            name + "-child",
+				OwnerReferences: []metav1.OwnerReference{{APIVersion: "v1", Kind: "ConfigMap", Name: name, UID: types.UID(uuid.New().String())}},
+			}}, metav1.CreateOptions{})
+			require.NoError(t, err)
+		}
+
+		err = DeleteOwnedBy(ctx, c.KubeConfigFilePath(), schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"}, name, "")
+		require.NoError(t, err)
+
+		_, err = c.Client().CoreV1().ConfigMaps("default").Get(ctx, name+"-child", metav1.GetOptions{})
+		assert.True(t, apierrors.IsNotFound(err))
+
+		_, err = c.Client().CoreV1().ConfigMaps(ns).Get(ctx, name+"-child", metav1.GetOptions{})
+		assert.NoError(t, err, "the child in another namespace should be kept")
+	})
+}
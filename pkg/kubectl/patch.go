@@ -0,0 +1,137 @@
+package kubectl
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/kubectl/pkg/cmd/patch"
+)
+
+type PatchOptions struct {
+	/*
+		The namespace of the resource to patch, the namespace of the current context is used when empty
+	*/
+	Namespace string
+	/*
+		The type of the patch i.e. kubectl patch --type
+	*/
+	Type PatchType
+	/*
+		Runs a dry-run of the patch i.e. kubectl patch --dry-run. DryRunClient only prints the patched resource
+		without sending the patch, and DryRunServer sends it to the API server without persisting it
+	*/
+	DryRun DryRunType
+	/*
+		Patches the resource as the given API version, e.g. v1 or apps/v1, instead of the preferred version of the
+		resource i.e. kubectl patch RESOURCE.VERSION.GROUP. The version has to be served for the resource, and
+		its group replaces any group in the resource type
+	*/
+	APIVersion string
+}
+
+/*
+PatchFromFile patches the resource in the cluster that the kubeconfigPath points to with the patch in the patchFile
+i.e. kubectl patch RESOURCE NAME --patch-file PATCH_FILE.
+
+Example:
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	err := PatchFromFile(
+		ctx,
+		"/path/to/kubeconfig",
+		&PatchOptions{
+			Namespace: "my-namespace",
+			Type:      PatchTypeMerge,
+		},
+		"configmap",
+		"my-config",
+		"/path/to/patch.json",
+	)
+	if err != nil {
+		// Handle error
+	}
+*/
+func PatchFromFile(ctx context.Context, kubeconfigPath string, opts *PatchOptions, resourceType, name, patchFile string) error {
+	// Like the apply command, the patch command may encounter a fatal error which
+	// changes global behaviour
+	lock, err := beginKubectl(ctx, "kubectl patch")
+	if err != nil {
+		return err
+	}
+	defer lock.unlock()
+
+	if kubeconfigPath == "" {
+		return fmt.Errorf("kubeconfig path cannot be empty")
+	}
+
+	if opts == nil {
+		return fmt.Errorf("options cannot be nil")
+	}
+
+	if resourceType == "" || name == "" {
+		return fmt.Errorf("resource type and name cannot be empty")
+	}
+
+	if patchFile == "" {
+		return fmt.Errorf("patch file cannot be empty")
+	}
+
+	if opts.APIVersion != "" {
+		versioned, err := versionedResource(resourceType, opts.APIVersion)
+		if err != nil {
+			return err
+		}
+
+		resourceType = versioned
+	}
+
+	ioStreams, streamOut, streamErr := newIOStreams()
+
+	errChan := newErrChan()
+
+	// Send an error to the error channel when the context is cancelled or its deadline is reached
+	operation := fmt.Sprintf("kubectl patch of %s %s", resourceType, name)
+	timeLeft, stopWatching := watchContext(ctx, operation, errChan, streamOut, streamErr)
+	defer stopWatching()
+
+	// The deadline may have passed while we waited for the kubectl lock
+	if err := expiredError(operation, timeLeft, streamOut, streamErr); err != nil {
+		return err
+	}
+
+	f := newCommandFactory(kubeconfigPath, opts.Namespace, timeLeft)
+
+	patchCmd := patch.NewCmdPatch(f, ioStreams)
+	patchCmd.Flags().Set("patch-file", patchFile)
+	patchCmd.Flags().Set("type", opts.Type.String())
+	patchCmd.Flags().Set("dry-run", opts.DryRun.String())
+	patchCmd.Flags().Set("field-manager", FieldManager)
+
+	lock.run(errChan, streamOut, streamErr, func() error {
+		// patchCmd is blocking. Should it fail it should have called the fatal error handler which
+		// run overrides to send an error to errChan
+		patchCmd.Run(patchCmd, []string{resourceType, name})
+		return nil
+	})
+
+	return <-errChan
+}
+
+/*
+versionedResource returns the fully qualified RESOURCE.VERSION.GROUP form of the resource type that kubectl resolves to
+the given API version, e.g. deployments.v1.apps for deployment and apps/v1. The core group is left empty, e.g. pods.v1.
+*/
+func versionedResource(resourceType, apiVersion string) (string, error) {
+	gv, err := schema.ParseGroupVersion(apiVersion)
+	if err != nil || gv.Version == "" {
+		return "", fmt.Errorf("invalid api version %s", apiVersion)
+	}
+
+	resource, _, _ := strings.Cut(resourceType, ".")
+
+	return fmt.Sprintf("%s.%s.%s", resource, gv.Version, gv.Group), nil
+}
@@ -0,0 +1,223 @@
+package kubectl
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Arneproductions/go-kube/internal/resources"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+func TestPatchFromFile(t *testing.T) {
+	c := resources.NewEphemeralCluster()
+	require.NoError(t, c.Start())
+
+	t.Cleanup(func() {
+		require.NoError(t, c.Stop())
+	})
+
+	t.Run("PatchFromFile_should_apply_merge_patch_to_resource", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		namespace := fmt.Sprintf("test-ns-%s", uuid.New().String())
+
+		_, err := c.Client().CoreV1().Namespaces().Create(ctx, &corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{Name: namespace},
+		}, metav1.CreateOptions{})
+		require.NoError(t, err)
+
+		t.Cleanup(func() {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			defer cancel()
+
+			_ = c.Client().CoreV1().Namespaces().Delete(ctx, namespace, metav1.DeleteOptions{})
+		})
+
+		_, err = c.Client().CoreV1().ConfigMaps(namespace).Create(ctx, &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "patched"},
+			Data:       map[string]string{"foo": "bar"},
+		}, metav1.CreateOptions{})
+		require.NoError(t, err)
+
+		patchFile := writeTestManifest(t, `{"data":{"foo":"patched","baz":"qux"}}`)
+
+		err = PatchFromFile(ctx, c.KubeConfigFilePath(), &PatchOptions{Namespace: namespace, Type: PatchTypeMerge}, "configmap", "patched", patchFile)
+		require.NoError(t, err)
+
+		cm, err := c.Client().CoreV1().ConfigMaps(namespace).Get(ctx, "patched", metav1.GetOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{"foo": "patched", "baz": "qux"}, cm.Data)
+	})
+
+	t.Run("PatchFromFile_should_leave_resource_alone_on_dry_run", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		name := fmt.Sprintf("test-cm-%s", uuid.New().String())
+
+		_, err := c.Client().CoreV1().ConfigMaps("default").Create(ctx, &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Data:       map[string]string{"foo": "bar"},
+		}, metav1.CreateOptions{})
+		require.NoError(t, err)
+
+		t.Cleanup(func() {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			defer cancel()
+
+			_ = c.Client().CoreV1().ConfigMaps("default").Delete(ctx, name, metav1.DeleteOptions{})
+		})
+
+		patchFile := writeTestManifest(t, `{"data":{"foo":"patched"}}`)
+
+		for _, dryRun := range []DryRunType{DryRunClient, DryRunServer} {
+			err = PatchFromFile(ctx, c.KubeConfigFilePath(), &PatchOptions{Namespace: "default", Type: PatchTypeMerge, DryRun: dryRun}, "configmap", name, patchFile)
+			require.NoError(t, err, dryRun.String())
+		}
+
+		cm, err := c.Client().CoreV1().ConfigMaps("default").Get(ctx, name, metav1.GetOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{"foo": "bar"}, cm.Data)
+	})
+
+	t.Run("PatchFromFile_should_fail_on_missing_resource", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		patchFile := writeTestManifest(t, `{"data":{"foo":"patched"}}`)
+
+		err := PatchFromFile(ctx, c.KubeConfigFilePath(), &PatchOptions{Type: PatchTypeMerge}, "configmap", "does-not-exist", patchFile)
+		assert.Error(t, err)
+	})
+
+	t.Run("PatchFromFile_should_patch_as_the_given_api_version", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+		defer cancel()
+
+		err := ApplyManifests(ctx, c.KubeConfigFilePath(), &ApplyManifestsOptions{}, writeTestManifest(t, gizmoCRDManifest))
+		require.NoError(t, err)
+
+		// The gizmo kind is only served once the CRD is established
+		gizmo := writeTestManifest(t, `
+apiVersion: test.go-kube.io/v2
+kind: Gizmo
+metadata:
+  name: versioned-gizmo
+  namespace: default
+spec:
+  size: 1
+`)
+		err = wait.PollUntilContextCancel(ctx, time.Second, true, func(ctx context.Context) (bool, error) {
+			return ApplyManifests(ctx, c.KubeConfigFilePath(), &ApplyManifestsOptions{}, gizmo) == nil, nil
+		})
+		require.NoError(t, err)
+
+		patchFile := writeTestManifest(t, `{"spec":{"replicas":3}}`)
+
+		// v1 does not know the replicas, so they are pruned from the patch
+		err = PatchFromFile(ctx, c.KubeConfigFilePath(), &PatchOptions{Type: PatchTypeMerge, APIVersion: "test.go-kube.io/v1"}, "gizmos", "versioned-gizmo", patchFile)
+		require.NoError(t, err)
+
+		_, found, _ := unstructured.NestedInt64(getTestGizmo(ctx, t, c.KubeConfigFilePath(), "versioned-gizmo").Object, "spec", "replicas")
+		assert.False(t, found, "the replicas should be pruned when patching as v1")
+
+		err = PatchFromFile(ctx, c.KubeConfigFilePath(), &PatchOptions{Type: PatchTypeMerge, APIVersion: "test.go-kube.io/v2"}, "gizmos", "versioned-gizmo", patchFile)
+		require.NoError(t, err)
+
+		replicas, _, _ := unstructured.NestedInt64(getTestGizmo(ctx, t, c.KubeConfigFilePath(), "versioned-gizmo").Object, "spec", "replicas")
+		assert.Equal(t, int64(3), replicas)
+
+		err = PatchFromFile(ctx, c.KubeConfigFilePath(), &PatchOptions{Type: PatchTypeMerge, APIVersion: "test.go-kube.io/v3"}, "gizmos", "versioned-gizmo", patchFile)
+		assert.Error(t, err, "v3 is not served")
+	})
+}
+
+func TestVersionedResource(t *testing.T) {
+	t.Run("versionedResource_should_qualify_the_resource_with_the_version", func(t *testing.T) {
+		for _, tc := range []struct {
+			resourceType string
+			apiVersion   string
+			expected     string
+		}{
+			{"deployment", "apps/v1", "deployment.v1.apps"},
+			{"deployments.apps", "apps/v1", "deployments.v1.apps"},
+			{"pods", "v1", "pods.v1."},
+			{"gizmos.v1.test.go-kube.io", "test.go-kube.io/v2", "gizmos.v2.test.go-kube.io"},
+		} {
+			versioned, err := versionedResource(tc.resourceType, tc.apiVersion)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, versioned)
+		}
+	})
+
+	t.Run("versionedResource_should_reject_invalid_api_versions", func(t *testing.T) {
+		for _, apiVersion := range []string{"apps/", "apps/v1/beta"} {
+			_, err := versionedResource("deployment", apiVersion)
+			assert.Error(t, err, apiVersion)
+		}
+	})
+}
+
+const gizmoCRDManifest = `
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: gizmos.test.go-kube.io
+spec:
+  group: test.go-kube.io
+  scope: Namespaced
+  names:
+    plural: gizmos
+    singular: gizmo
+    kind: Gizmo
+  versions:
+  - name: v1
+    served: true
+    storage: false
+    schema:
+      openAPIV3Schema:
+        type: object
+        properties:
+          spec:
+            type: object
+            properties:
+              size:
+                type: integer
+  - name: v2
+    served: true
+    storage: true
+    schema:
+      openAPIV3Schema:
+        type: object
+        properties:
+          spec:
+            type: object
+            properties:
+              size:
+                type: integer
+              replicas:
+                type: integer
+`
+
+func getTestGizmo(ctx context.Context, t *testing.T, kubeconfigPath string, name string) *unstructured.Unstructured {
+	dynamicClient, err := newFactory(kubeconfigPath).DynamicClient()
+	require.NoError(t, err)
+
+	gvr := schema.GroupVersionResource{Group: "test.go-kube.io", Version: "v2", Resource: "gizmos"}
+
+	live, err := dynamicClient.Resource(gvr).Namespace("default").Get(ctx, name, metav1.GetOptions{})
+	require.NoError(t, err)
+
+	return live
+}
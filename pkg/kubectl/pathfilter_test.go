@@ -0,0 +1,89 @@
+package kubectl
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Arneproductions/go-kube/internal/resources"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// writeEnvironmentManifests writes a config map for the dev and the prod environment to a temporary directory
+func writeEnvironmentManifests(t *testing.T, devName, prodName string) string {
+	dir, err := os.MkdirTemp("", "test-path-filter-*")
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		os.RemoveAll(dir)
+	})
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a.dev.yaml"), []byte(configMapDataManifest(devName, "env", "dev")), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "b.prod.yaml"), []byte(configMapDataManifest(prodName, "env", "prod")), 0644))
+
+	return dir
+}
+
+func isProdManifest(path string) bool {
+	return strings.HasSuffix(path, ".prod.yaml")
+}
+
+func TestFilterManifestPaths(t *testing.T) {
+	t.Run("filterManifestPaths_should_only_return_accepted_files", func(t *testing.T) {
+		dir := writeEnvironmentManifests(t, "a", "b")
+
+		files, err := filterManifestPaths(isProdManifest, false, dir)
+		require.NoError(t, err)
+		assert.Equal(t, []string{filepath.Join(dir, "b.prod.yaml")}, files)
+	})
+
+	t.Run("filterManifestPaths_should_fail_when_no_files_are_accepted", func(t *testing.T) {
+		dir := writeEnvironmentManifests(t, "a", "b")
+
+		_, err := filterManifestPaths(func(string) bool { return false }, false, dir)
+		assert.Error(t, err)
+	})
+}
+
+func TestPathFilter(t *testing.T) {
+	c := resources.NewEphemeralCluster()
+	require.NoError(t, c.Start())
+
+	t.Cleanup(func() {
+		require.NoError(t, c.Stop())
+	})
+
+	t.Run("ApplyManifests_should_only_apply_files_accepted_by_PathFilter", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		devName := fmt.Sprintf("test-cm-%s", uuid.New().String())
+		prodName := fmt.Sprintf("test-cm-%s", uuid.New().String())
+		dir := writeEnvironmentManifests(t, devName, prodName)
+
+		err := ApplyManifests(ctx, c.KubeConfigFilePath(), &ApplyManifestsOptions{PathFilter: isProdManifest}, dir)
+		require.NoError(t, err)
+
+		t.Cleanup(func() {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			defer cancel()
+
+			_ = c.Client().CoreV1().ConfigMaps("default").Delete(ctx, prodName, metav1.DeleteOptions{})
+		})
+
+		_, err = c.Client().CoreV1().ConfigMaps("default").Get(ctx, prodName, metav1.GetOptions{})
+		assert.NoError(t, err, "the prod config map should be applied")
+
+		_, err = c.Client().CoreV1().ConfigMaps("default").Get(ctx, devName, metav1.GetOptions{})
+		assert.True(t, apierrors.IsNotFound(err), "the dev config map should not be applied")
+	})
+}
@@ -0,0 +1,108 @@
+package kubectl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+/*
+applyPerObject applies every object of the given files on its own, each bounded by its own timeout. Every object is
+attempted, even when some of them fail, and the result holds the timing and error of every object.
+*/
+func applyPerObject(ctx context.Context, kubeconfigPath string, opts *ApplyManifestsOptions, filePaths ...string) (*ApplyResult, error) {
+	if opts.Prune {
+		// Pruning after each object would prune every object that is applied on its own
+		return nil, fmt.Errorf("pruning is not supported when applying per object")
+	}
+
+	objs, err := readManifests(filePaths, opts.Recursive)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ApplyResult{}
+	errs := []error{}
+
+	for i, obj := range objs {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+
+		objResult, err := applyObject(ctx, kubeconfigPath, opts, obj, objectTimeout(ctx, opts.ObjectTimeout, len(objs)-i))
+		result.add([]ObjectResult{objResult})
+
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if len(errs) > 0 {
+		return result, fmt.Errorf("could not apply %d of %d objects: %w", len(errs), len(objs), errors.Join(errs...))
+	}
+
+	return result, nil
+}
+
+/*
+applyObject applies the object with a timeout, and returns the outcome of it.
+*/
+func applyObject(ctx context.Context, kubeconfigPath string, opts *ApplyManifestsOptions, obj *unstructured.Unstructured, timeout time.Duration) (ObjectResult, error) {
+	objResult := ObjectResult{
+		ObjectRef: objectRefOf(obj),
+		DryRun:    opts.DryRun != DryRunNone,
+	}
+
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	manifestPath, err := writeManifests([]*unstructured.Unstructured{obj})
+	if err != nil {
+		objResult.Err = err
+		return objResult, err
+	}
+	defer os.Remove(manifestPath)
+
+	start := time.Now()
+	applied, err := applyManifestsFunc(ctx, kubeconfigPath, opts, manifestPath)
+	objResult.Duration = time.Since(start)
+
+	if err != nil {
+		objResult.Err = fmt.Errorf("could not apply %s %s: %w", obj.GetKind(), obj.GetName(), err)
+		return objResult, objResult.Err
+	}
+
+	// kubectl prints a single line for the object it applied
+	if len(applied.Objects) > 0 {
+		objResult.ObjectRef = applied.Objects[0].ObjectRef
+		objResult.Operation = applied.Objects[0].Operation
+		objResult.DryRun = applied.Objects[0].DryRun
+	}
+
+	return objResult, nil
+}
+
+/*
+objectTimeout returns the timeout of the next object, which is either the given timeout, or an even share of
+the time left of the context between the remaining objects. A zero timeout means the object is not bounded
+on its own.
+*/
+func objectTimeout(ctx context.Context, timeout time.Duration, remaining int) time.Duration {
+	if timeout > 0 {
+		return timeout
+	}
+
+	deadline, ok := ctx.Deadline()
+	if !ok || remaining <= 0 {
+		return 0
+	}
+
+	return time.Until(deadline) / time.Duration(remaining)
+}
@@ -0,0 +1,132 @@
+package kubectl
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Arneproductions/go-kube/internal/resources"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// slowWebhookManifest is a webhook for the config maps labelled slow=true. The webhook points to an address that does not
+// respond, such that the API server waits for the webhook to time out before it admits the config map.
+const slowWebhookManifest = `
+apiVersion: admissionregistration.k8s.io/v1
+kind: ValidatingWebhookConfiguration
+metadata:
+  name: slow-webhook
+webhooks:
+- name: slow.go-kube.io
+  clientConfig:
+    url: https://10.255.255.1:443/validate
+  rules:
+  - apiGroups: [""]
+    apiVersions: ["v1"]
+    operations: ["CREATE", "UPDATE"]
+    resources: ["configmaps"]
+  objectSelector:
+    matchLabels:
+      slow: "true"
+  failurePolicy: Ignore
+  sideEffects: None
+  admissionReviewVersions: ["v1"]
+  timeoutSeconds: 20
+`
+
+func TestApplyPerObject(t *testing.T) {
+	c := resources.NewEphemeralCluster()
+	require.NoError(t, c.Start())
+
+	t.Cleanup(func() {
+		require.NoError(t, c.Stop())
+	})
+
+	t.Run("ApplyManifestsWithResult_should_apply_objects_behind_slow_object", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+		defer cancel()
+
+		err := ApplyManifests(ctx, c.KubeConfigFilePath(), &ApplyManifestsOptions{}, writeTestManifest(t, slowWebhookManifest))
+		require.NoError(t, err)
+
+		before := fmt.Sprintf("test-cm-%s", uuid.New().String())
+		slow := fmt.Sprintf("test-cm-%s", uuid.New().String())
+		after := fmt.Sprintf("test-cm-%s", uuid.New().String())
+
+		manifest := writeTestManifest(t, fmt.Sprintf(`
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: %s
+  namespace: default
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: %s
+  namespace: default
+  labels:
+    slow: "true"
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: %s
+  namespace: default
+`, before, slow, after))
+
+		result, err := ApplyManifestsWithResult(ctx, c.KubeConfigFilePath(), &ApplyManifestsOptions{
+			PerObject:     true,
+			ObjectTimeout: 5 * time.Second,
+		}, manifest)
+		require.Error(t, err)
+		require.NotNil(t, result)
+		require.Len(t, result.Objects, 3)
+
+		assert.NoError(t, result.Objects[0].Err)
+		assert.Error(t, result.Objects[1].Err)
+		assert.NoError(t, result.Objects[2].Err)
+
+		for _, obj := range result.Objects {
+			assert.Less(t, obj.Duration, 10*time.Second)
+		}
+
+		for _, name := range []string{before, after} {
+			_, err := c.Client().CoreV1().ConfigMaps("default").Get(ctx, name, metav1.GetOptions{})
+			assert.NoError(t, err)
+		}
+	})
+
+	t.Run("ApplyManifests_should_reject_pruning_per_object", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		manifest := writeTestManifest(t, labelledConfigMapManifest("prune-per-object", "prune"))
+
+		err := ApplyManifests(ctx, c.KubeConfigFilePath(), &ApplyManifestsOptions{PerObject: true, Prune: true, Selector: "test=prune"}, manifest)
+		assert.Error(t, err)
+	})
+}
+
+func TestObjectTimeout(t *testing.T) {
+	t.Run("objectTimeout_should_prefer_the_given_timeout", func(t *testing.T) {
+		assert.Equal(t, time.Second, objectTimeout(context.Background(), time.Second, 3))
+	})
+
+	t.Run("objectTimeout_should_share_the_time_left_between_remaining_objects", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		timeout := objectTimeout(ctx, 0, 3)
+		assert.InDelta(t, 10*time.Second, timeout, float64(time.Second))
+	})
+
+	t.Run("objectTimeout_should_not_bound_objects_without_deadline", func(t *testing.T) {
+		assert.Zero(t, objectTimeout(context.Background(), 0, 3))
+	})
+}
@@ -0,0 +1,124 @@
+package kubectl
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
+)
+
+/*
+PodsFor returns the names of the running pods backing the workload in the namespace of the cluster that the
+kubeconfigPath points to. The resourceType is a Deployment, StatefulSet, DaemonSet, ReplicaSet or Service, in
+any of the forms kubectl accepts, e.g. deployment, deployments or deploy. The names are sorted.
+
+Example:
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	pods, err := PodsFor(ctx, "/path/to/kubeconfig", "deployment", "nginx", "default")
+	if err != nil {
+		// Handle error
+	}
+*/
+func PodsFor(ctx context.Context, kubeconfigPath string, resourceType, name, namespace string) ([]string, error) {
+	if kubeconfigPath == "" {
+		return nil, fmt.Errorf("kubeconfig path cannot be empty")
+	}
+
+	if name == "" {
+		return nil, fmt.Errorf("name cannot be empty")
+	}
+
+	clientset, err := newFactory(kubeconfigPath).KubernetesClientSet()
+	if err != nil {
+		return nil, fmt.Errorf("could not create clientset: %w", err)
+	}
+
+	selector, err := workloadSelector(ctx, clientset, resourceType, name, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	pods, err := clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return nil, fmt.Errorf("could not list pods of %s %s: %w", resourceType, name, err)
+	}
+
+	names := []string{}
+	for _, pod := range pods.Items {
+		if pod.Status.Phase != corev1.PodRunning || pod.DeletionTimestamp != nil {
+			continue
+		}
+
+		names = append(names, pod.Name)
+	}
+	sort.Strings(names)
+
+	return names, nil
+}
+
+/*
+workloadSelector returns the pod selector of the workload.
+*/
+func workloadSelector(ctx context.Context, clientset kubernetes.Interface, resourceType, name, namespace string) (labels.Selector, error) {
+	var podSelector *metav1.LabelSelector
+
+	switch strings.ToLower(resourceType) {
+	case "deployment", "deployments", "deploy":
+		deployment, err := clientset.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("could not get deployment %s: %w", name, err)
+		}
+		podSelector = deployment.Spec.Selector
+
+	case "statefulset", "statefulsets", "sts":
+		statefulSet, err := clientset.AppsV1().StatefulSets(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("could not get statefulset %s: %w", name, err)
+		}
+		podSelector = statefulSet.Spec.Selector
+
+	case "daemonset", "daemonsets", "ds":
+		daemonSet, err := clientset.AppsV1().DaemonSets(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("could not get daemonset %s: %w", name, err)
+		}
+		podSelector = daemonSet.Spec.Selector
+
+	case "replicaset", "replicasets", "rs":
+		replicaSet, err := clientset.AppsV1().ReplicaSets(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("could not get replicaset %s: %w", name, err)
+		}
+		podSelector = replicaSet.Spec.Selector
+
+	case "service", "services", "svc":
+		service, err := clientset.CoreV1().Services(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("could not get service %s: %w", name, err)
+		}
+
+		if len(service.Spec.Selector) == 0 {
+			return nil, fmt.Errorf("service %s has no selector", name)
+		}
+
+		return labels.SelectorFromSet(service.Spec.Selector), nil
+
+	default:
+		return nil, fmt.Errorf("unsupported resource type %s", resourceType)
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(podSelector)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse selector of %s %s: %w", resourceType, name, err)
+	}
+
+	return selector, nil
+}
@@ -0,0 +1,64 @@
+package kubectl
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Arneproductions/go-kube/internal/resources"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+func TestPodsFor(t *testing.T) {
+	c := resources.NewEphemeralCluster()
+	require.NoError(t, c.Start())
+
+	t.Cleanup(func() {
+		require.NoError(t, c.Stop())
+	})
+
+	t.Run("PodsFor_should_return_the_pods_of_a_deployment", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Minute)
+		defer cancel()
+
+		name := fmt.Sprintf("test-deploy-%s", uuid.New().String())
+
+		err := ApplyManifests(ctx, c.KubeConfigFilePath(), &ApplyManifestsOptions{}, writeTestManifest(t, loggingDeploymentManifest(name, 1)))
+		require.NoError(t, err)
+
+		t.Cleanup(func() {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			defer cancel()
+
+			_ = c.Client().AppsV1().Deployments("default").Delete(ctx, name, metav1.DeleteOptions{})
+		})
+
+		var pods []string
+		err = wait.PollUntilContextCancel(ctx, time.Second, true, func(ctx context.Context) (bool, error) {
+			pods, err = PodsFor(ctx, c.KubeConfigFilePath(), "deployment", name, "default")
+			if err != nil {
+				return false, err
+			}
+
+			return len(pods) == 1, nil
+		})
+		require.NoError(t, err)
+
+		assert.True(t, strings.HasPrefix(pods[0], name))
+	})
+
+	t.Run("PodsFor_should_reject_unsupported_resource_types", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		_, err := PodsFor(ctx, c.KubeConfigFilePath(), "configmap", "foo", "default")
+		assert.Error(t, err)
+	})
+}
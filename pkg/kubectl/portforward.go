@@ -0,0 +1,210 @@
+package kubectl
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/tools/portforward"
+	"k8s.io/client-go/transport/spdy"
+)
+
+/*
+PortForward forwards a random local port on 127.0.0.1 to the port of the pod in the namespace of the cluster that the
+kubeconfigPath points to, like kubectl port-forward. It returns once the tunnel is ready, with the local port and a
+function closing the tunnel. The tunnel is also closed when the context is done.
+
+Example:
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	localPort, stop, err := PortForward(ctx, "/path/to/kubeconfig", "default", "nginx-7c5ddbdf54-8kx2p", 80)
+	if err != nil {
+		// Handle error
+	}
+	defer stop()
+*/
+func PortForward(ctx context.Context, kubeconfigPath string, namespace, pod string, port int) (int, func(), error) {
+	if kubeconfigPath == "" {
+		return 0, nil, fmt.Errorf("kubeconfig path cannot be empty")
+	}
+
+	if pod == "" {
+		return 0, nil, fmt.Errorf("pod cannot be empty")
+	}
+
+	f := newFactory(kubeconfigPath)
+
+	restConfig, err := f.ToRESTConfig()
+	if err != nil {
+		return 0, nil, fmt.Errorf("could not create rest config: %w", err)
+	}
+
+	clientset, err := f.KubernetesClientSet()
+	if err != nil {
+		return 0, nil, fmt.Errorf("could not create clientset: %w", err)
+	}
+
+	transport, upgrader, err := spdy.RoundTripperFor(restConfig)
+	if err != nil {
+		return 0, nil, fmt.Errorf("could not create port-forward transport: %w", err)
+	}
+
+	url := clientset.CoreV1().RESTClient().Post().Resource("pods").Namespace(namespace).Name(pod).SubResource("portforward").URL()
+	dialer := spdy.NewDialer(upgrader, &http.Client{Transport: transport}, http.MethodPost, url)
+
+	stopChan := make(chan struct{})
+	readyChan := make(chan struct{})
+	errChan := make(chan error, 1)
+
+	var once sync.Once
+	stop := func() {
+		once.Do(func() { close(stopChan) })
+	}
+
+	forwarder, err := portforward.NewOnAddresses(dialer, []string{"127.0.0.1"}, []string{fmt.Sprintf("0:%d", port)}, stopChan, readyChan, io.Discard, io.Discard)
+	if err != nil {
+		return 0, nil, fmt.Errorf("could not create port-forward to pod %s: %w", pod, err)
+	}
+
+	go func() {
+		errChan <- forwarder.ForwardPorts()
+	}()
+
+	select {
+	case <-readyChan:
+	case err := <-errChan:
+		return 0, nil, fmt.Errorf("could not port-forward to pod %s: %w", pod, err)
+	case <-ctx.Done():
+		stop()
+		return 0, nil, fmt.Errorf("could not port-forward to pod %s: %w", pod, ctx.Err())
+	}
+
+	go func() {
+		select {
+		case <-ctx.Done():
+			stop()
+		case <-stopChan:
+		}
+	}()
+
+	ports, err := forwarder.GetPorts()
+	if err != nil || len(ports) == 0 {
+		stop()
+		return 0, nil, fmt.Errorf("could not determine the local port of the port-forward to pod %s: %w", pod, err)
+	}
+
+	return int(ports[0].Local), stop, nil
+}
+
+/*
+HTTPClientForService port-forwards to a running pod behind the service in the namespace of the cluster that the
+kubeconfigPath points to, and returns an HTTP client along with the base URL reaching the port of the service through
+the tunnel. The returned stop function closes the tunnel, which is also closed when the context is done.
+
+Example:
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	client, baseURL, stop, err := HTTPClientForService(ctx, "/path/to/kubeconfig", "default", "nginx", 80)
+	if err != nil {
+		// Handle error
+	}
+	defer stop()
+
+	resp, err := client.Get(baseURL + "/healthz")
+*/
+func HTTPClientForService(ctx context.Context, kubeconfigPath string, namespace, service string, port int) (*http.Client, string, func(), error) {
+	if kubeconfigPath == "" {
+		return nil, "", nil, fmt.Errorf("kubeconfig path cannot be empty")
+	}
+
+	if service == "" {
+		return nil, "", nil, fmt.Errorf("service cannot be empty")
+	}
+
+	clientset, err := newFactory(kubeconfigPath).KubernetesClientSet()
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("could not create clientset: %w", err)
+	}
+
+	svc, err := clientset.CoreV1().Services(namespace).Get(ctx, service, metav1.GetOptions{})
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("could not get service %s: %w", service, err)
+	}
+
+	if len(svc.Spec.Selector) == 0 {
+		return nil, "", nil, fmt.Errorf("service %s has no selector", service)
+	}
+
+	var targetPort *intstr.IntOrString
+	for _, p := range svc.Spec.Ports {
+		if int(p.Port) == port {
+			targetPort = &p.TargetPort
+			break
+		}
+	}
+
+	if targetPort == nil {
+		return nil, "", nil, fmt.Errorf("service %s does not expose port %d", service, port)
+	}
+
+	selector := labels.SelectorFromSet(svc.Spec.Selector).String()
+	pods, err := clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("could not list pods of service %s: %w", service, err)
+	}
+
+	for _, pod := range pods.Items {
+		if pod.Status.Phase != corev1.PodRunning || pod.DeletionTimestamp != nil {
+			continue
+		}
+
+		podPort, err := resolveTargetPort(pod, *targetPort, port)
+		if err != nil {
+			return nil, "", nil, fmt.Errorf("could not resolve port %d of service %s: %w", port, service, err)
+		}
+
+		localPort, stop, err := PortForward(ctx, kubeconfigPath, namespace, pod.Name, podPort)
+		if err != nil {
+			return nil, "", nil, err
+		}
+
+		return &http.Client{}, "http://127.0.0.1:" + strconv.Itoa(localPort), stop, nil
+	}
+
+	return nil, "", nil, fmt.Errorf("service %s has no running pods", service)
+}
+
+/*
+resolveTargetPort returns the container port of the pod that the target port of a service port refers to. Named
+target ports are looked up in the container ports of the pod, and an unset target port defaults to the service port.
+*/
+func resolveTargetPort(pod corev1.Pod, targetPort intstr.IntOrString, servicePort int) (int, error) {
+	if targetPort.Type == intstr.Int {
+		if targetPort.IntVal == 0 {
+			return servicePort, nil
+		}
+
+		return int(targetPort.IntVal), nil
+	}
+
+	for _, container := range pod.Spec.Containers {
+		for _, p := range container.Ports {
+			if p.Name == targetPort.StrVal {
+				return int(p.ContainerPort), nil
+			}
+		}
+	}
+
+	return 0, fmt.Errorf("pod %s has no container port named %s", pod.Name, targetPort.StrVal)
+}
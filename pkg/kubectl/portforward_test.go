@@ -0,0 +1,151 @@
+package kubectl
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Arneproductions/go-kube/internal/resources"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func TestResolveTargetPort(t *testing.T) {
+	pod := corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "web"},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{
+				Name:  "web",
+				Ports: []corev1.ContainerPort{{Name: "http", ContainerPort: 8080}},
+			}},
+		},
+	}
+
+	t.Run("resolveTargetPort_should_use_numeric_target_ports", func(t *testing.T) {
+		port, err := resolveTargetPort(pod, intstr.FromInt32(9090), 80)
+		assert.NoError(t, err)
+		assert.Equal(t, 9090, port)
+	})
+
+	t.Run("resolveTargetPort_should_default_to_the_service_port", func(t *testing.T) {
+		port, err := resolveTargetPort(pod, intstr.IntOrString{}, 80)
+		assert.NoError(t, err)
+		assert.Equal(t, 80, port)
+	})
+
+	t.Run("resolveTargetPort_should_look_up_named_target_ports", func(t *testing.T) {
+		port, err := resolveTargetPort(pod, intstr.FromString("http"), 80)
+		assert.NoError(t, err)
+		assert.Equal(t, 8080, port)
+	})
+
+	t.Run("resolveTargetPort_should_error_on_unknown_named_target_ports", func(t *testing.T) {
+		_, err := resolveTargetPort(pod, intstr.FromString("grpc"), 80)
+		assert.Error(t, err)
+	})
+}
+
+func TestHTTPClientForService(t *testing.T) {
+	c := resources.NewEphemeralCluster()
+	require.NoError(t, c.Start())
+
+	t.Cleanup(func() {
+		require.NoError(t, c.Stop())
+	})
+
+	t.Run("HTTPClientForService_should_reach_the_service_through_the_tunnel", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Minute)
+		defer cancel()
+
+		name := fmt.Sprintf("test-web-%s", uuid.New().String())
+		manifest := writeTestManifest(t, httpServiceManifest(name, "hello from "+name))
+
+		err := ApplyManifests(ctx, c.KubeConfigFilePath(), &ApplyManifestsOptions{WaitForReady: true}, manifest)
+		require.NoError(t, err)
+
+		t.Cleanup(func() {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			defer cancel()
+
+			_ = DeleteManifests(ctx, c.KubeConfigFilePath(), manifest)
+		})
+
+		client, baseURL, stop, err := HTTPClientForService(ctx, c.KubeConfigFilePath(), "default", name, 80)
+		require.NoError(t, err)
+		defer stop()
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, baseURL+"/", nil)
+		require.NoError(t, err)
+
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "hello from "+name+"\n", string(body))
+	})
+
+	t.Run("HTTPClientForService_should_error_on_ports_the_service_does_not_expose", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		name := fmt.Sprintf("test-web-%s", uuid.New().String())
+		manifest := writeTestManifest(t, httpServiceManifest(name, "hello"))
+
+		require.NoError(t, ApplyManifests(ctx, c.KubeConfigFilePath(), &ApplyManifestsOptions{}, manifest))
+
+		t.Cleanup(func() {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			defer cancel()
+
+			_ = DeleteManifests(ctx, c.KubeConfigFilePath(), manifest)
+		})
+
+		_, _, _, err := HTTPClientForService(ctx, c.KubeConfigFilePath(), "default", name, 8443)
+		assert.Error(t, err)
+	})
+}
+
+func httpServiceManifest(name, body string) string {
+	return fmt.Sprintf(`apiVersion: v1
+kind: Pod
+metadata:
+  name: %[1]s
+  namespace: default
+  labels:
+    app: %[1]s
+spec:
+  containers:
+  - name: web
+    image: busybox:1.36
+    command: ["sh", "-c", "mkdir -p /www && echo '%[2]s' > /www/index.html && httpd -f -p 8080 -h /www"]
+    ports:
+    - name: http
+      containerPort: 8080
+    readinessProbe:
+      tcpSocket:
+        port: http
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: %[1]s
+  namespace: default
+spec:
+  selector:
+    app: %[1]s
+  ports:
+  - port: 80
+    targetPort: http
+`, name, body)
+}
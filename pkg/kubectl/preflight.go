@@ -0,0 +1,77 @@
+package kubectl
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+/*
+Preflight checks that every required kind is served by the cluster that the kubeconfigPath points to. The returned
+error lists every kind that is missing, such that a bundle depending on e.g. CRDs can fail fast before it is applied.
+
+Example:
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	err := Preflight(ctx, "/path/to/kubeconfig", []schema.GroupVersionKind{
+		{Group: "cert-manager.io", Version: "v1", Kind: "Certificate"},
+	})
+	if err != nil {
+		// Handle error
+	}
+*/
+func Preflight(ctx context.Context, kubeconfigPath string, required []schema.GroupVersionKind) error {
+	if kubeconfigPath == "" {
+		return fmt.Errorf("kubeconfig path cannot be empty")
+	}
+
+	discoveryClient, err := newFactory(kubeconfigPath).ToDiscoveryClient()
+	if err != nil {
+		return fmt.Errorf("could not create discovery client: %w", err)
+	}
+
+	// We cache the served kinds per group version, as many required kinds often share a group version
+	servedKinds := map[schema.GroupVersion]map[string]bool{}
+	missing := []string{}
+
+	for _, gvk := range required {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		gv := gvk.GroupVersion()
+
+		kinds, ok := servedKinds[gv]
+		if !ok {
+			kinds = map[string]bool{}
+
+			resources, err := discoveryClient.ServerResourcesForGroupVersion(gv.String())
+			if err != nil && !apierrors.IsNotFound(err) {
+				return fmt.Errorf("could not discover resources for %s: %w", gv.String(), err)
+			}
+
+			if resources != nil {
+				for _, resource := range resources.APIResources {
+					kinds[resource.Kind] = true
+				}
+			}
+
+			servedKinds[gv] = kinds
+		}
+
+		if !kinds[gvk.Kind] {
+			missing = append(missing, gvk.String())
+		}
+	}
+
+	if len(missing) > 0 {
+		return fmt.Errorf("cluster is missing required kinds: %s", strings.Join(missing, "; "))
+	}
+
+	return nil
+}
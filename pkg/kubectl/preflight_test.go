@@ -0,0 +1,47 @@
+package kubectl
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Arneproductions/go-kube/internal/resources"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestPreflight(t *testing.T) {
+	c := resources.NewEphemeralCluster()
+	require.NoError(t, c.Start())
+
+	t.Cleanup(func() {
+		require.NoError(t, c.Stop())
+	})
+
+	t.Run("Preflight_passes_when_kinds_are_served", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		err := Preflight(ctx, c.KubeConfigFilePath(), []schema.GroupVersionKind{
+			{Group: "apps", Version: "v1", Kind: "Deployment"},
+			{Group: "", Version: "v1", Kind: "ConfigMap"},
+		})
+		assert.NoError(t, err)
+	})
+
+	t.Run("Preflight_lists_missing_kinds", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		err := Preflight(ctx, c.KubeConfigFilePath(), []schema.GroupVersionKind{
+			{Group: "apps", Version: "v1", Kind: "Deployment"},
+			{Group: "cert-manager.io", Version: "v1", Kind: "Certificate"},
+		})
+		require.Error(t, err)
+
+		assert.Contains(t, err.Error(), "cert-manager.io/v1, Kind=Certificate")
+		assert.NotContains(t, err.Error(), "Deployment")
+	})
+}
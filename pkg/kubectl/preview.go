@@ -0,0 +1,136 @@
+package kubectl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// FieldConflict is a field that a server-side apply would take from another field manager
+type FieldConflict struct {
+	Object ObjectRef
+	// The path of the field, e.g. .data.foo
+	Field string
+	// The field manager that owns the field
+	Manager string
+}
+
+/*
+previewConflicts server-side dry-run applies the objects in the given files without forcing conflicts, and collects the
+fields that other field managers own. Nothing is applied.
+*/
+func previewConflicts(ctx context.Context, kubeconfigPath string, opts *ApplyManifestsOptions, filePaths ...string) (*ApplyResult, error) {
+	if err := operations.begin(); err != nil {
+		return nil, err
+	}
+	defer operations.end()
+
+	if kubeconfigPath == "" {
+		return nil, fmt.Errorf("kubeconfig path cannot be empty")
+	}
+
+	objs, err := readManifests(filePaths, opts.Recursive)
+	if err != nil {
+		return nil, err
+	}
+
+	f := newFactory(kubeconfigPath)
+
+	dynamicClient, err := f.DynamicClient()
+	if err != nil {
+		return nil, fmt.Errorf("could not create dynamic client: %w", err)
+	}
+
+	mapper, err := f.ToRESTMapper()
+	if err != nil {
+		return nil, fmt.Errorf("could not create rest mapper: %w", err)
+	}
+
+	namespace, _, err := f.ToRawKubeConfigLoader().Namespace()
+	if err != nil {
+		return nil, fmt.Errorf("could not determine default namespace: %w", err)
+	}
+
+	applyOpts := metav1.ApplyOptions{
+		FieldManager: fieldManagerOf(opts),
+		DryRun:       []string{metav1.DryRunAll},
+	}
+
+	result := &ApplyResult{}
+
+	for _, obj := range objs {
+		client, err := objectClient(dynamicClient, mapper, namespace, obj)
+		if err != nil {
+			return nil, err
+		}
+
+		ref := objectRefOf(obj)
+
+		_, err = client.Apply(ctx, obj.GetName(), obj, applyOpts)
+		if apierrors.IsConflict(err) {
+			conflicts := fieldConflicts(ref, err)
+			if len(conflicts) > 0 {
+				result.Conflicts = append(result.Conflicts, conflicts...)
+				result.add([]ObjectResult{{ObjectRef: ref, Operation: OperationServerSideApplied, DryRun: true, Err: err}})
+				continue
+			}
+		}
+		if err != nil {
+			return nil, fmt.Errorf("could not dry-run apply %s %s: %w", obj.GetKind(), obj.GetName(), err)
+		}
+
+		result.add([]ObjectResult{{ObjectRef: ref, Operation: OperationServerSideApplied, DryRun: true}})
+	}
+
+	return result, nil
+}
+
+/*
+fieldConflicts returns the conflicting fields that the API server reports in the causes of a server-side apply
+conflict, i.e.
+
+	{"reason": "FieldManagerConflict", "message": "conflict with \"other-manager\"", "field": ".data.foo"}
+*/
+func fieldConflicts(ref ObjectRef, err error) []FieldConflict {
+	statusErr := &apierrors.StatusError{}
+	if !errors.As(err, &statusErr) || statusErr.ErrStatus.Details == nil {
+		return nil
+	}
+
+	conflicts := []FieldConflict{}
+	for _, cause := range statusErr.ErrStatus.Details.Causes {
+		if cause.Type != metav1.CauseTypeFieldManagerConflict {
+			continue
+		}
+
+		conflicts = append(conflicts, FieldConflict{
+			Object:  ref,
+			Field:   cause.Field,
+			Manager: conflictManager(cause.Message),
+		})
+	}
+
+	return conflicts
+}
+
+/*
+conflictManager returns the name of the field manager in the message of a conflict, which is quoted and may be
+followed by the subresource, API version and time of the update, e.g.
+
+	conflict with "kubectl-edit" using v1 at 2024-01-01T00:00:00Z
+*/
+func conflictManager(message string) string {
+	message = strings.TrimPrefix(message, "conflict with ")
+
+	manager := ""
+	_, err := fmt.Sscanf(message, "%q", &manager)
+	if err != nil {
+		return message
+	}
+
+	return manager
+}
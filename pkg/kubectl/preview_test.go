@@ -0,0 +1,106 @@
+package kubectl
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Arneproductions/go-kube/internal/resources"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestFieldConflicts(t *testing.T) {
+	t.Run("fieldConflicts_should_return_the_fields_and_managers_of_the_causes", func(t *testing.T) {
+		ref := ObjectRef{Kind: "configmap", Name: "foo"}
+		err := apierrors.NewApplyConflict([]metav1.StatusCause{
+			{Type: metav1.CauseTypeFieldManagerConflict, Message: `conflict with "other-manager"`, Field: ".data.foo"},
+			{Type: metav1.CauseTypeFieldManagerConflict, Message: `conflict with "kubectl-edit" using v1 at 2024-01-01T00:00:00Z`, Field: ".data.bar"},
+			{Type: metav1.CauseTypeFieldValueInvalid, Message: "not a conflict", Field: ".data.baz"},
+		}, "Apply failed with 2 conflicts")
+
+		assert.Equal(t, []FieldConflict{
+			{Object: ref, Field: ".data.foo", Manager: "other-manager"},
+			{Object: ref, Field: ".data.bar", Manager: "kubectl-edit"},
+		}, fieldConflicts(ref, err))
+	})
+
+	t.Run("fieldConflicts_should_ignore_other_errors", func(t *testing.T) {
+		err := apierrors.NewNotFound(schema.GroupResource{Resource: "configmaps"}, "foo")
+
+		assert.Empty(t, fieldConflicts(ObjectRef{}, err))
+	})
+}
+
+func TestPreviewConflicts(t *testing.T) {
+	c := resources.NewEphemeralCluster()
+	require.NoError(t, c.Start())
+
+	t.Cleanup(func() {
+		require.NoError(t, c.Stop())
+	})
+
+	t.Run("ApplyManifests_should_report_conflicts_without_changing_the_object", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		name := fmt.Sprintf("test-cm-%s", uuid.New().String())
+		patch := fmt.Sprintf(`{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"%s","namespace":"default"},"data":{"foo":"other"}}`, name)
+
+		_, err := c.Client().CoreV1().ConfigMaps("default").Patch(
+			ctx,
+			name,
+			types.ApplyPatchType,
+			[]byte(patch),
+			metav1.PatchOptions{FieldManager: "other-manager"},
+		)
+		require.NoError(t, err)
+
+		manifest := writeTestManifest(t, fmt.Sprintf(`
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: %s
+  namespace: default
+data:
+  foo: ours
+  bar: ours
+`, name))
+
+		result, err := ApplyManifestsWithResult(ctx, c.KubeConfigFilePath(), &ApplyManifestsOptions{PreviewConflicts: true}, manifest)
+		require.NoError(t, err)
+		assert.Equal(t, []FieldConflict{
+			{Object: ObjectRef{Kind: "configmap", Name: name}, Field: ".data.foo", Manager: "other-manager"},
+		}, result.Conflicts)
+
+		cm, err := c.Client().CoreV1().ConfigMaps("default").Get(ctx, name, metav1.GetOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{"foo": "other"}, cm.Data)
+	})
+
+	t.Run("ApplyManifests_should_report_no_conflicts_for_new_objects", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		name := fmt.Sprintf("test-cm-%s", uuid.New().String())
+		manifest := writeTestManifest(t, configMapDataManifest(name, "foo", "bar"))
+
+		result, err := ApplyManifestsWithResult(ctx, c.KubeConfigFilePath(), &ApplyManifestsOptions{PreviewConflicts: true}, manifest)
+		require.NoError(t, err)
+		assert.Empty(t, result.Conflicts)
+
+		_, err = c.Client().CoreV1().ConfigMaps("default").Get(ctx, name, metav1.GetOptions{})
+		assert.True(t, apierrors.IsNotFound(err))
+	})
+}
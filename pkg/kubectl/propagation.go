@@ -0,0 +1,100 @@
+package kubectl
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+/*
+DeleteWithPropagation deletes the object in the namespace of the cluster that the kubeconfigPath points to through the
+dynamic client, letting the garbage collector handle its dependents according to the propagation policy. It blocks until
+the object is gone, which with metav1.DeletePropagationForeground is after every dependent of it is gone. The resourceType
+is in any of the forms kubectl accepts, e.g. deployment, deploy or deployments.apps, and the namespace is left empty for
+cluster scoped resources.
+
+Unlike DeleteManifests, this does not run kubectl and does not wait for other kubectl commands against the cluster.
+
+Example:
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	err := DeleteWithPropagation(ctx, "/path/to/kubeconfig", "deployment", "nginx", "default", metav1.DeletePropagationForeground)
+	if err != nil {
+		// Handle error
+	}
+*/
+func DeleteWithPropagation(ctx context.Context, kubeconfigPath string, resourceType, name, namespace string, propagation metav1.DeletionPropagation) error {
+	if kubeconfigPath == "" {
+		return fmt.Errorf("kubeconfig path cannot be empty")
+	}
+
+	if resourceType == "" || name == "" {
+		return fmt.Errorf("resource type and name cannot be empty")
+	}
+
+	switch propagation {
+	case metav1.DeletePropagationForeground, metav1.DeletePropagationBackground, metav1.DeletePropagationOrphan:
+	default:
+		return fmt.Errorf("unsupported propagation policy %s", propagation)
+	}
+
+	f := newFactory(kubeconfigPath)
+
+	dynamicClient, err := f.DynamicClient()
+	if err != nil {
+		return fmt.Errorf("could not create dynamic client: %w", err)
+	}
+
+	mapper, err := f.ToRESTMapper()
+	if err != nil {
+		return fmt.Errorf("could not create rest mapper: %w", err)
+	}
+
+	gvr, err := resolveResource(mapper, resourceType, "")
+	if err != nil {
+		return err
+	}
+
+	client := dynamicClient.Resource(gvr).Namespace(namespace)
+
+	obj, err := client.Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("could not get %s %s: %w", resourceType, name, err)
+	}
+	uid := obj.GetUID()
+
+	// The precondition makes sure that we do not delete an object that was recreated with the same name
+	err = client.Delete(ctx, name, metav1.DeleteOptions{
+		PropagationPolicy: &propagation,
+		Preconditions:     &metav1.Preconditions{UID: &uid},
+	})
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("could not delete %s %s: %w", resourceType, name, err)
+	}
+
+	err = wait.PollUntilContextCancel(ctx, time.Second, true, func(ctx context.Context) (bool, error) {
+		obj, err := client.Get(ctx, name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			return true, nil
+		}
+		if err != nil {
+			return false, fmt.Errorf("could not get %s %s: %w", resourceType, name, err)
+		}
+
+		return obj.GetUID() != uid, nil
+	})
+	if err != nil {
+		return fmt.Errorf("%s %s was not deleted: %w", resourceType, name, err)
+	}
+
+	return nil
+}
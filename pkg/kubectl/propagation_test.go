@@ -0,0 +1,65 @@
+package kubectl
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Arneproductions/go-kube/internal/resources"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+func TestDeleteWithPropagation(t *testing.T) {
+	c := resources.NewEphemeralCluster()
+	require.NoError(t, c.Start())
+
+	t.Cleanup(func() {
+		require.NoError(t, c.Stop())
+	})
+
+	t.Run("DeleteWithPropagation_should_wait_for_dependents_with_foreground_propagation", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Minute)
+		defer cancel()
+
+		name := fmt.Sprintf("test-deploy-%s", uuid.New().String())
+		selector := fmt.Sprintf("app=%s", name)
+
+		err := ApplyManifests(ctx, c.KubeConfigFilePath(), &ApplyManifestsOptions{}, writeTestManifest(t, loggingDeploymentManifest(name, 1)))
+		require.NoError(t, err)
+
+		err = wait.PollUntilContextCancel(ctx, time.Second, true, func(ctx context.Context) (bool, error) {
+			replicaSets, err := c.Client().AppsV1().ReplicaSets("default").List(ctx, metav1.ListOptions{LabelSelector: selector})
+			if err != nil {
+				return false, err
+			}
+
+			return len(replicaSets.Items) > 0, nil
+		})
+		require.NoError(t, err)
+
+		err = DeleteWithPropagation(ctx, c.KubeConfigFilePath(), "deployment", name, "default", metav1.DeletePropagationForeground)
+		require.NoError(t, err)
+
+		_, err = c.Client().AppsV1().Deployments("default").Get(ctx, name, metav1.GetOptions{})
+		assert.True(t, apierrors.IsNotFound(err), "the deployment should be deleted")
+
+		replicaSets, err := c.Client().AppsV1().ReplicaSets("default").List(ctx, metav1.ListOptions{LabelSelector: selector})
+		require.NoError(t, err)
+		assert.Empty(t, replicaSets.Items, "the replica sets should be deleted before the deployment")
+	})
+
+	t.Run("DeleteWithPropagation_should_reject_unsupported_propagation_policies", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		err := DeleteWithPropagation(ctx, c.KubeConfigFilePath(), "deployment", "nginx", "default", "Sideways")
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,252 @@
+package kubectl
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+/*
+ReadinessCheck reports whether the live object is ready
+*/
+type ReadinessCheck func(obj *unstructured.Unstructured) bool
+
+var (
+	// The built-in readiness checks of the kinds that do not report readiness through a Ready condition alone, and of
+	// the kinds that have a status without conditions, which are ready as soon as they exist
+	builtinReadinessChecks = map[schema.GroupKind]ReadinessCheck{
+		{Group: "apps", Kind: "Deployment"}:            replicasReady,
+		{Group: "apps", Kind: "StatefulSet"}:           replicasReady,
+		{Group: "apps", Kind: "ReplicaSet"}:            replicasReady,
+		{Group: "apps", Kind: "DaemonSet"}:             daemonSetReady,
+		{Group: "batch", Kind: "Job"}:                  jobComplete,
+		{Kind: "Pod"}:                                  podReady,
+		{Kind: "ReplicationController"}:                replicasReady,
+		{Kind: "Service"}:                              exists,
+		{Kind: "Namespace"}:                            exists,
+		{Kind: "PersistentVolume"}:                     exists,
+		{Kind: "PersistentVolumeClaim"}:                exists,
+		{Kind: "ResourceQuota"}:                        exists,
+		{Group: "policy", Kind: "PodDisruptionBudget"}: exists,
+	}
+
+	// The built-in checks of the kinds that can fail, such that they never become ready, which are used along with the
+	// built-in readiness checks
+	builtinFailureChecks = map[schema.GroupKind]func(obj *unstructured.Unstructured) (string, bool){
+		{Group: "batch", Kind: "Job"}: jobFailed,
+	}
+)
+
+/*
+waitForReady waits until every object of the given files is ready in the cluster, using the readiness check of its kind.
+*/
+func waitForReady(ctx context.Context, kubeconfigPath string, checks map[schema.GroupVersionKind]ReadinessCheck, recursive bool, filePaths ...string) error {
+	objs, err := readManifests(filePaths, recursive)
+	if err != nil {
+		return err
+	}
+
+	f := newFactory(kubeconfigPath)
+
+	dynamicClient, err := f.DynamicClient()
+	if err != nil {
+		return fmt.Errorf("could not create dynamic client: %w", err)
+	}
+
+	mapper, err := f.ToRESTMapper()
+	if err != nil {
+		return fmt.Errorf("could not create rest mapper: %w", err)
+	}
+
+	discoveryClient, err := f.ToDiscoveryClient()
+	if err != nil {
+		return fmt.Errorf("could not create discovery client: %w", err)
+	}
+
+	namespace, _, err := f.ToRawKubeConfigLoader().Namespace()
+	if err != nil {
+		return fmt.Errorf("could not determine default namespace: %w", err)
+	}
+
+	// We cache the resources with a status subresource per group version, as many objects often share one
+	withStatus := map[schema.GroupVersion]map[string]bool{}
+
+	for _, obj := range objs {
+		client, err := objectClient(dynamicClient, mapper, namespace, obj)
+		if err != nil {
+			return err
+		}
+
+		gvk := obj.GroupVersionKind()
+
+		mapping, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+		if err != nil {
+			return fmt.Errorf("could not find resource for %s: %w", gvk.String(), err)
+		}
+
+		gv := mapping.Resource.GroupVersion()
+		if _, ok := withStatus[gv]; !ok {
+			withStatus[gv] = map[string]bool{}
+
+			resources, err := discoveryClient.ServerResourcesForGroupVersion(gv.String())
+			if err != nil {
+				return fmt.Errorf("could not discover resources for %s: %w", gv.String(), err)
+			}
+
+			for _, resource := range resources.APIResources {
+				if name, found := strings.CutSuffix(resource.Name, "/status"); found {
+					withStatus[gv][name] = true
+				}
+			}
+		}
+
+		check := readinessCheck(checks, gvk, withStatus[gv][mapping.Resource.Resource])
+
+		failed, hasFailure := builtinFailureChecks[gvk.GroupKind()]
+		if _, ok := checks[gvk]; ok {
+			hasFailure = false
+		}
+
+		err = wait.PollUntilContextCancel(ctx, time.Second, true, func(ctx context.Context) (bool, error) {
+			live, err := client.Get(ctx, obj.GetName(), metav1.GetOptions{})
+			if err != nil {
+				return false, fmt.Errorf("could not get %s %s: %w", obj.GetKind(), obj.GetName(), err)
+			}
+
+			if hasFailure {
+				if message, ok := failed(live); ok {
+					return false, fmt.Errorf("%s %s failed: %s", obj.GetKind(), obj.GetName(), message)
+				}
+			}
+
+			return check(live), nil
+		})
+		if err != nil {
+			return fmt.Errorf("%s %s did not become ready: %w", obj.GetKind(), obj.GetName(), err)
+		}
+	}
+
+	return nil
+}
+
+/*
+readinessCheck returns the readiness check of the kind, preferring the given checks over the built-in checks. Kinds
+without either are ready once their Ready condition is true, when their resource has a status subresource, and as soon
+as they exist otherwise, e.g. ConfigMaps.
+*/
+func readinessCheck(checks map[schema.GroupVersionKind]ReadinessCheck, gvk schema.GroupVersionKind, hasStatus bool) ReadinessCheck {
+	if check, ok := checks[gvk]; ok {
+		return check
+	}
+
+	if check, ok := builtinReadinessChecks[gvk.GroupKind()]; ok {
+		return check
+	}
+
+	if !hasStatus {
+		return exists
+	}
+
+	return conditionReady
+}
+
+/*
+replicasReady checks that the object has observed its latest generation, and has as many updated and ready
+replicas as its spec asks for.
+*/
+func replicasReady(obj *unstructured.Unstructured) bool {
+	if !generationObserved(obj) {
+		return false
+	}
+
+	replicas, found, _ := unstructured.NestedInt64(obj.Object, "spec", "replicas")
+	if !found {
+		// The replicas default to one when left out
+		replicas = 1
+	}
+
+	ready, _, _ := unstructured.NestedInt64(obj.Object, "status", "readyReplicas")
+	updated, found, _ := unstructured.NestedInt64(obj.Object, "status", "updatedReplicas")
+	if !found {
+		// ReplicaSets do not report updated replicas
+		updated = ready
+	}
+
+	return ready >= replicas && updated >= replicas
+}
+
+func daemonSetReady(obj *unstructured.Unstructured) bool {
+	if !generationObserved(obj) {
+		return false
+	}
+
+	desired, _, _ := unstructured.NestedInt64(obj.Object, "status", "desiredNumberScheduled")
+	ready, _, _ := unstructured.NestedInt64(obj.Object, "status", "numberReady")
+	updated, _, _ := unstructured.NestedInt64(obj.Object, "status", "updatedNumberScheduled")
+
+	return ready >= desired && updated >= desired
+}
+
+func jobComplete(obj *unstructured.Unstructured) bool {
+	return hasCondition(obj, "Complete")
+}
+
+/*
+jobFailed reports Jobs that failed, which never complete, along with the message of their Failed condition.
+*/
+func jobFailed(obj *unstructured.Unstructured) (string, bool) {
+	conditions, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	for _, c := range conditions {
+		condition, ok := c.(map[string]interface{})
+		if ok && condition["type"] == "Failed" && condition["status"] == "True" {
+			return fmt.Sprint(condition["message"]), true
+		}
+	}
+
+	return "", false
+}
+
+/*
+podReady reports Pods as ready when their Ready condition is true, or when they ran to completion.
+*/
+func podReady(obj *unstructured.Unstructured) bool {
+	phase, _, _ := unstructured.NestedString(obj.Object, "status", "phase")
+
+	return phase == "Succeeded" || hasCondition(obj, "Ready")
+}
+
+/*
+conditionReady reports objects as ready when their Ready condition is true. Objects that have not reported a Ready
+condition yet, e.g. as their controller has not seen them, are not ready.
+*/
+func conditionReady(obj *unstructured.Unstructured) bool {
+	return hasCondition(obj, "Ready")
+}
+
+func exists(obj *unstructured.Unstructured) bool {
+	return true
+}
+
+func hasCondition(obj *unstructured.Unstructured, conditionType string) bool {
+	conditions, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	for _, c := range conditions {
+		condition, ok := c.(map[string]interface{})
+		if ok && condition["type"] == conditionType && condition["status"] == "True" {
+			return true
+		}
+	}
+
+	return false
+}
+
+func generationObserved(obj *unstructured.Unstructured) bool {
+	observed, _, _ := unstructured.NestedInt64(obj.Object, "status", "observedGeneration")
+
+	return observed >= obj.GetGeneration()
+}
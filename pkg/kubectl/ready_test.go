@@ -0,0 +1,164 @@
+package kubectl
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Arneproductions/go-kube/internal/resources"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+func TestWaitForReady(t *testing.T) {
+	c := resources.NewEphemeralCluster()
+	require.NoError(t, c.Start())
+
+	t.Cleanup(func() {
+		require.NoError(t, c.Stop())
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	err := ApplyManifests(ctx, c.KubeConfigFilePath(), &ApplyManifestsOptions{}, writeTestManifest(t, widgetCRDManifest))
+	require.NoError(t, err)
+
+	// The widget kind is only served once the CRD is established
+	err = wait.PollUntilContextCancel(ctx, time.Second, true, func(ctx context.Context) (bool, error) {
+		return ApplyManifests(ctx, c.KubeConfigFilePath(), &ApplyManifestsOptions{}, writeTestManifest(t, widgetManifest("established", 1, ""))) == nil, nil
+	})
+	require.NoError(t, err)
+
+	t.Run("ApplyManifests_should_wait_for_custom_readiness_check", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		widget := writeTestManifest(t, widgetManifest("ready-widget", 1, ""))
+		status := writeTestManifest(t, widgetManifest("ready-widget", 1, "Ready"))
+
+		widgetReady := func(obj *unstructured.Unstructured) bool {
+			phase, _, _ := unstructured.NestedString(obj.Object, "status", "phase")
+			return phase == "Ready"
+		}
+
+		// The widget is made ready a while after it has been applied
+		statusErr := make(chan error, 1)
+		go func() {
+			time.Sleep(5 * time.Second)
+			statusErr <- ApplyStatus(ctx, c.KubeConfigFilePath(), &ApplyManifestsOptions{}, status)
+		}()
+
+		start := time.Now()
+		err := ApplyManifests(ctx, c.KubeConfigFilePath(), &ApplyManifestsOptions{
+			WaitForReady: true,
+			ReadinessChecks: map[schema.GroupVersionKind]ReadinessCheck{
+				{Group: "test.go-kube.io", Version: "v1", Kind: "Widget"}: widgetReady,
+			},
+		}, widget)
+		require.NoError(t, err)
+		require.NoError(t, <-statusErr)
+
+		assert.GreaterOrEqual(t, time.Since(start), 5*time.Second)
+	})
+}
+
+func TestReadinessChecks(t *testing.T) {
+	t.Run("replicasReady_should_require_ready_and_updated_replicas", func(t *testing.T) {
+		deployment := mustDecodeManifests(t, `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+  generation: 2
+spec:
+  replicas: 3
+status:
+  observedGeneration: 2
+  readyReplicas: 3
+  updatedReplicas: 2
+`)[0]
+
+		check := readinessCheck(nil, deployment.GroupVersionKind(), true)
+		assert.False(t, check(deployment))
+
+		require.NoError(t, unstructured.SetNestedField(deployment.Object, int64(3), "status", "updatedReplicas"))
+		assert.True(t, check(deployment))
+
+		deployment.SetGeneration(3)
+		assert.False(t, check(deployment))
+	})
+
+	t.Run("conditionReady_should_use_the_ready_condition", func(t *testing.T) {
+		pod := mustDecodeManifests(t, `
+apiVersion: v1
+kind: Pod
+metadata:
+  name: pod
+status:
+  conditions:
+  - type: Ready
+    status: "False"
+`)[0]
+		configMap := mustDecodeManifests(t, labelledConfigMapManifest("config", "ready"))[0]
+
+		assert.False(t, readinessCheck(nil, pod.GroupVersionKind(), true)(pod))
+		assert.True(t, readinessCheck(nil, configMap.GroupVersionKind(), false)(configMap))
+	})
+
+	t.Run("conditionReady_should_wait_for_the_ready_condition_of_kinds_with_a_status", func(t *testing.T) {
+		widget := mustDecodeManifests(t, `
+apiVersion: test.go-kube.io/v1
+kind: Widget
+metadata:
+  name: widget
+`)[0]
+
+		check := readinessCheck(nil, widget.GroupVersionKind(), true)
+		assert.False(t, check(widget))
+
+		require.NoError(t, unstructured.SetNestedSlice(widget.Object, []interface{}{
+			map[string]interface{}{"type": "Ready", "status": "True"},
+		}, "status", "conditions"))
+		assert.True(t, check(widget))
+	})
+
+	t.Run("podReady_should_accept_pods_that_ran_to_completion", func(t *testing.T) {
+		pod := mustDecodeManifests(t, `
+apiVersion: v1
+kind: Pod
+metadata:
+  name: pod
+status:
+  phase: Succeeded
+  conditions:
+  - type: Ready
+    status: "False"
+`)[0]
+
+		assert.True(t, readinessCheck(nil, pod.GroupVersionKind(), true)(pod))
+	})
+
+	t.Run("jobFailed_should_report_the_failed_condition", func(t *testing.T) {
+		job := mustDecodeManifests(t, `
+apiVersion: batch/v1
+kind: Job
+metadata:
+  name: job
+status:
+  conditions:
+  - type: Failed
+    status: "True"
+    message: Job has reached the specified backoff limit
+`)[0]
+
+		message, failed := jobFailed(job)
+		assert.True(t, failed)
+		assert.Equal(t, "Job has reached the specified backoff limit", message)
+		assert.False(t, readinessCheck(nil, job.GroupVersionKind(), true)(job))
+	})
+}
@@ -0,0 +1,80 @@
+package kubectl
+
+import (
+	"context"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+/*
+releaseOwnership server-side applies the objects in the given files without any fields, such that the FieldManager
+no longer owns any of their fields. Objects that do not exist are skipped, as the apply would create them.
+*/
+func releaseOwnership(ctx context.Context, kubeconfigPath string, recursive bool, filePaths ...string) error {
+	if err := operations.begin(); err != nil {
+		return err
+	}
+	defer operations.end()
+
+	if kubeconfigPath == "" {
+		return fmt.Errorf("kubeconfig path cannot be empty")
+	}
+
+	if len(filePaths) == 0 {
+		return fmt.Errorf("no files to delete")
+	}
+
+	objs, err := readManifests(filePaths, recursive)
+	if err != nil {
+		return err
+	}
+
+	f := newFactory(kubeconfigPath)
+
+	dynamicClient, err := f.DynamicClient()
+	if err != nil {
+		return fmt.Errorf("could not create dynamic client: %w", err)
+	}
+
+	mapper, err := f.ToRESTMapper()
+	if err != nil {
+		return fmt.Errorf("could not create rest mapper: %w", err)
+	}
+
+	namespace, _, err := f.ToRawKubeConfigLoader().Namespace()
+	if err != nil {
+		return fmt.Errorf("could not determine default namespace: %w", err)
+	}
+
+	for _, obj := range objs {
+		client, err := objectClient(dynamicClient, mapper, namespace, obj)
+		if err != nil {
+			return err
+		}
+
+		_, err = client.Get(ctx, obj.GetName(), metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("could not get %s %s: %w", obj.GetKind(), obj.GetName(), err)
+		}
+
+		// An apply of only the identity of the object owns nothing
+		empty := &unstructured.Unstructured{}
+		empty.SetAPIVersion(obj.GetAPIVersion())
+		empty.SetKind(obj.GetKind())
+		empty.SetName(obj.GetName())
+		empty.SetNamespace(obj.GetNamespace())
+
+		_, err = client.Apply(ctx, obj.GetName(), empty, metav1.ApplyOptions{FieldManager: FieldManager})
+		if err != nil {
+			return fmt.Errorf("could not release ownership of %s %s: %w", obj.GetKind(), obj.GetName(), err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,76 @@
+package kubectl
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+/*
+WaitForReplicas waits until the workload in the namespace of the cluster that the kubeconfigPath points to has exactly
+want ready replicas, or the context is done. The resourceType is any resource with a status.readyReplicas, e.g. a
+Deployment or StatefulSet, in any of the forms kubectl accepts, e.g. deployment, deploy or deployments.apps.
+
+Example:
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	err := WaitForReplicas(ctx, "/path/to/kubeconfig", "deployment", "nginx", "default", 3)
+	if err != nil {
+		// Handle error
+	}
+*/
+func WaitForReplicas(ctx context.Context, kubeconfigPath string, resourceType, name, namespace string, want int32) error {
+	if kubeconfigPath == "" {
+		return fmt.Errorf("kubeconfig path cannot be empty")
+	}
+
+	if resourceType == "" || name == "" {
+		return fmt.Errorf("resource type and name cannot be empty")
+	}
+
+	f := newFactory(kubeconfigPath)
+
+	dynamicClient, err := f.DynamicClient()
+	if err != nil {
+		return fmt.Errorf("could not create dynamic client: %w", err)
+	}
+
+	mapper, err := f.ToRESTMapper()
+	if err != nil {
+		return fmt.Errorf("could not create rest mapper: %w", err)
+	}
+
+	gvr, err := resolveResource(mapper, resourceType, "")
+	if err != nil {
+		return err
+	}
+
+	client := dynamicClient.Resource(gvr).Namespace(namespace)
+	readyReplicas := int64(0)
+
+	err = wait.PollUntilContextCancel(ctx, time.Second, true, func(ctx context.Context) (bool, error) {
+		obj, err := client.Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return false, fmt.Errorf("could not get %s %s: %w", resourceType, name, err)
+		}
+
+		// The ready replicas are left out of the status while there are none
+		readyReplicas, _, err = unstructured.NestedInt64(obj.Object, "status", "readyReplicas")
+		if err != nil {
+			return false, fmt.Errorf("could not read ready replicas of %s %s: %w", resourceType, name, err)
+		}
+
+		return readyReplicas == int64(want), nil
+	})
+	if err != nil {
+		return fmt.Errorf("%s %s has %d ready replicas, want %d: %w", resourceType, name, readyReplicas, want, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,72 @@
+package kubectl
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Arneproductions/go-kube/internal/resources"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestWaitForReplicas(t *testing.T) {
+	c := resources.NewEphemeralCluster()
+	require.NoError(t, c.Start())
+
+	t.Cleanup(func() {
+		require.NoError(t, c.Stop())
+	})
+
+	t.Run("WaitForReplicas_should_return_when_replicas_are_ready", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Minute)
+		defer cancel()
+
+		name := fmt.Sprintf("test-deploy-%s", uuid.New().String())
+
+		err := ApplyManifests(ctx, c.KubeConfigFilePath(), &ApplyManifestsOptions{}, writeTestManifest(t, loggingDeploymentManifest(name, 3)))
+		require.NoError(t, err)
+
+		t.Cleanup(func() {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			defer cancel()
+
+			_ = c.Client().AppsV1().Deployments("default").Delete(ctx, name, metav1.DeleteOptions{})
+		})
+
+		err = WaitForReplicas(ctx, c.KubeConfigFilePath(), "deploy", name, "default", 3)
+		require.NoError(t, err)
+
+		deployment, err := c.Client().AppsV1().Deployments("default").Get(ctx, name, metav1.GetOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, int32(3), deployment.Status.ReadyReplicas)
+	})
+
+	t.Run("WaitForReplicas_should_fail_when_context_expires", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		name := fmt.Sprintf("test-deploy-%s", uuid.New().String())
+
+		err := ApplyManifests(ctx, c.KubeConfigFilePath(), &ApplyManifestsOptions{}, writeTestManifest(t, loggingDeploymentManifest(name, 0)))
+		require.NoError(t, err)
+
+		t.Cleanup(func() {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			defer cancel()
+
+			_ = c.Client().AppsV1().Deployments("default").Delete(ctx, name, metav1.DeleteOptions{})
+		})
+
+		waitCtx, waitCancel := context.WithTimeout(ctx, 3*time.Second)
+		defer waitCancel()
+
+		err = WaitForReplicas(waitCtx, c.KubeConfigFilePath(), "deployment", name, "default", 1)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "has 0 ready replicas, want 1")
+	})
+}
@@ -0,0 +1,319 @@
+package kubectl
+
+import (
+	"bufio"
+	"strconv"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// ApplyOperation is the operation that kubectl performed on an object during an apply
+type ApplyOperation string
+
+const (
+	OperationCreated           ApplyOperation = "created"
+	OperationConfigured        ApplyOperation = "configured"
+	OperationUnchanged         ApplyOperation = "unchanged"
+	OperationPruned            ApplyOperation = "pruned"
+	OperationServerSideApplied ApplyOperation = "serverside-applied"
+)
+
+// ObjectRef identifies an object in the cluster
+type ObjectRef struct {
+	// The API group of the object, empty for the core group
+	Group string
+	// The lowercased kind of the object, as kubectl prints it
+	Kind string
+	Name string
+}
+
+// ObjectResult is the outcome of an apply for a single object
+type ObjectResult struct {
+	ObjectRef
+	// The namespace of the object, empty for cluster-scoped and pruned objects
+	Namespace string
+	Operation ApplyOperation
+	// Whether the operation was only performed as a dry-run
+	DryRun bool
+	// How long the apply of the object took, only set when objects are applied on their own
+	Duration time.Duration
+	// Why the apply of the object failed, only set when objects are applied on their own
+	Err error
+}
+
+// ApplyResult is the outcome of an apply
+type ApplyResult struct {
+	Objects []ObjectResult
+	// The objects that were pruned
+	Pruned []ObjectRef
+	// The objects that would have been pruned, had the apply not been a dry-run
+	WouldPrune []ObjectRef
+	// The warnings of the API server, e.g. about deprecated API versions
+	Warnings []string
+	// The fields that other field managers own, only set when previewing conflicts
+	Conflicts []FieldConflict
+}
+
+/*
+objectRefOf returns the reference to the object, in the form that kubectl prints it.
+*/
+func objectRefOf(obj *unstructured.Unstructured) ObjectRef {
+	return ObjectRef{
+		Group: obj.GroupVersionKind().Group,
+		Kind:  strings.ToLower(obj.GetKind()),
+		Name:  obj.GetName(),
+	}
+}
+
+/*
+setNamespaces sets the namespaces of the object results from the objects of the manifests, as kubectl does not print
+them. Namespaced objects without a namespace are in the default namespace of the kubeconfig. The namespaces are only
+informational, so they are left out when they cannot be determined.
+*/
+func (r *ApplyResult) setNamespaces(kubeconfigPath string, recursive bool, filePaths ...string) {
+	if r == nil || len(r.Objects) == 0 {
+		return
+	}
+
+	objs, err := readManifests(filePaths, recursive)
+	if err != nil {
+		return
+	}
+
+	f := newFactory(kubeconfigPath)
+
+	mapper, err := f.ToRESTMapper()
+	if err != nil {
+		return
+	}
+
+	defaultNamespace, _, err := f.ToRawKubeConfigLoader().Namespace()
+	if err != nil {
+		return
+	}
+
+	// Objects with the same kind and name in several namespaces are printed in the order of the manifests
+	used := make([]bool, len(objs))
+
+	for i := range r.Objects {
+		if r.Objects[i].Operation == OperationPruned {
+			continue
+		}
+
+		for j, obj := range objs {
+			if used[j] || objectRefOf(obj) != r.Objects[i].ObjectRef {
+				continue
+			}
+			used[j] = true
+
+			gvk := obj.GroupVersionKind()
+			mapping, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+			if err != nil || mapping.Scope.Name() != meta.RESTScopeNameNamespace {
+				break
+			}
+
+			r.Objects[i].Namespace = obj.GetNamespace()
+			if r.Objects[i].Namespace == "" {
+				r.Objects[i].Namespace = defaultNamespace
+			}
+
+			break
+		}
+	}
+}
+
+/*
+add adds the object results to the result, sorting pruned objects into Pruned or WouldPrune.
+*/
+func (r *ApplyResult) add(objs []ObjectResult) {
+	for _, obj := range objs {
+		r.Objects = append(r.Objects, obj)
+
+		if obj.Operation != OperationPruned {
+			continue
+		}
+
+		if obj.DryRun {
+			r.WouldPrune = append(r.WouldPrune, obj.ObjectRef)
+		} else {
+			r.Pruned = append(r.Pruned, obj.ObjectRef)
+		}
+	}
+}
+
+/*
+merge adds the outcome of another apply to the result.
+*/
+func (r *ApplyResult) merge(other *ApplyResult) {
+	r.Objects = append(r.Objects, other.Objects...)
+	r.Pruned = append(r.Pruned, other.Pruned...)
+	r.WouldPrune = append(r.WouldPrune, other.WouldPrune...)
+	r.Warnings = append(r.Warnings, other.Warnings...)
+	r.Conflicts = append(r.Conflicts, other.Conflicts...)
+}
+
+/*
+Changed reports whether the apply created, configured or pruned any objects.
+
+Server-side applies do not report whether an object was changed, so server-side applied objects are
+considered changed.
+*/
+func (r *ApplyResult) Changed() bool {
+	for _, obj := range r.Objects {
+		if obj.Operation != OperationUnchanged {
+			return true
+		}
+	}
+
+	return false
+}
+
+/*
+parseApplyOutput parses the lines that kubectl apply prints for every object, i.e.
+
+	deployment.apps/nginx created
+	configmap/foo unchanged
+	configmap/bar created (server dry run)
+
+Lines that do not describe an object are ignored.
+*/
+func parseApplyOutput(out string) []ObjectResult {
+	results := []ObjectResult{}
+
+	scanner := bufio.NewScanner(strings.NewReader(out))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		dryRun := false
+		for _, suffix := range []string{" (dry run)", " (server dry run)"} {
+			if strings.HasSuffix(line, suffix) {
+				line = strings.TrimSuffix(line, suffix)
+				dryRun = true
+			}
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+
+		ref, ok := parseObjectRef(fields[0])
+		if !ok {
+			continue
+		}
+
+		results = append(results, ObjectResult{
+			ObjectRef: ref,
+			Operation: ApplyOperation(fields[1]),
+			DryRun:    dryRun,
+		})
+	}
+
+	return results
+}
+
+/*
+parseObjectRef parses the `kind.group/name` form that kubectl prints objects in.
+*/
+func parseObjectRef(s string) (ObjectRef, bool) {
+	resource, name, ok := strings.Cut(s, "/")
+	if !ok || resource == "" || name == "" {
+		return ObjectRef{}, false
+	}
+
+	kind, group, _ := strings.Cut(resource, ".")
+
+	return ObjectRef{
+		Group: group,
+		Kind:  kind,
+		Name:  name,
+	}, true
+}
+
+// DeleteResult is the outcome of a delete
+type DeleteResult struct {
+	// The objects that were deleted
+	Deleted []ObjectRef
+	// The objects that would have been deleted, had the delete not been a dry-run
+	WouldDelete []ObjectRef
+	// The objects of the manifests that did not exist, only set when not found objects are ignored
+	NotFound []ObjectRef
+}
+
+/*
+parseDeleteOutput parses the lines that kubectl delete prints for every object, i.e.
+
+	deployment.apps "nginx" deleted
+	configmap "foo" force deleted
+	configmap "bar" deleted (server dry run)
+
+Lines that do not describe an object are ignored.
+*/
+func parseDeleteOutput(out string) *DeleteResult {
+	result := &DeleteResult{}
+
+	scanner := bufio.NewScanner(strings.NewReader(out))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		dryRun := false
+		for _, suffix := range []string{" (dry run)", " (server dry run)"} {
+			if strings.HasSuffix(line, suffix) {
+				line = strings.TrimSuffix(line, suffix)
+				dryRun = true
+			}
+		}
+
+		if !strings.HasSuffix(line, " deleted") {
+			continue
+		}
+		line = strings.TrimSuffix(strings.TrimSuffix(line, " deleted"), " force")
+
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+
+		name, err := strconv.Unquote(fields[1])
+		if err != nil {
+			continue
+		}
+
+		ref, ok := parseObjectRef(fields[0] + "/" + name)
+		if !ok {
+			continue
+		}
+
+		if dryRun {
+			result.WouldDelete = append(result.WouldDelete, ref)
+		} else {
+			result.Deleted = append(result.Deleted, ref)
+		}
+	}
+
+	return result
+}
+
+/*
+notFoundObjects returns the references to the objects that kubectl did not report as deleted, which kubectl leaves out
+silently when it ignores objects that are not found.
+*/
+func notFoundObjects(objs []*unstructured.Unstructured, result *DeleteResult) []ObjectRef {
+	reported := map[ObjectRef]bool{}
+	for _, ref := range append(append([]ObjectRef{}, result.Deleted...), result.WouldDelete...) {
+		reported[ref] = true
+	}
+
+	notFound := []ObjectRef{}
+	for _, obj := range objs {
+		ref := objectRefOf(obj)
+		if !reported[ref] {
+			notFound = append(notFound, ref)
+		}
+	}
+
+	return notFound
+}
@@ -0,0 +1,72 @@
+package kubectl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseApplyOutput(t *testing.T) {
+	t.Run("parseApplyOutput_parses_object_lines", func(t *testing.T) {
+		out := `deployment.apps/nginx created
+configmap/foo unchanged
+Warning: resource configmaps/bar is missing the kubectl.kubernetes.io/last-applied-configuration annotation
+configmap/bar configured
+namespace/baz created (server dry run)
+`
+
+		results := parseApplyOutput(out)
+
+		assert.Equal(t, []ObjectResult{
+			{ObjectRef: ObjectRef{Group: "apps", Kind: "deployment", Name: "nginx"}, Operation: OperationCreated},
+			{ObjectRef: ObjectRef{Kind: "configmap", Name: "foo"}, Operation: OperationUnchanged},
+			{ObjectRef: ObjectRef{Kind: "configmap", Name: "bar"}, Operation: OperationConfigured},
+			{ObjectRef: ObjectRef{Kind: "namespace", Name: "baz"}, Operation: OperationCreated, DryRun: true},
+		}, results)
+	})
+
+	t.Run("ApplyResult_Changed_is_false_when_everything_is_unchanged", func(t *testing.T) {
+		result := &ApplyResult{Objects: parseApplyOutput("configmap/foo unchanged\nconfigmap/bar unchanged\n")}
+		assert.False(t, result.Changed())
+
+		result = &ApplyResult{Objects: parseApplyOutput("configmap/foo unchanged\nconfigmap/bar configured\n")}
+		assert.True(t, result.Changed())
+	})
+
+	t.Run("ApplyResult_sorts_pruned_objects_by_dry_run", func(t *testing.T) {
+		result := &ApplyResult{}
+		result.add(parseApplyOutput("configmap/foo pruned\nconfigmap/bar pruned (server dry run)\n"))
+
+		assert.Equal(t, []ObjectRef{{Kind: "configmap", Name: "foo"}}, result.Pruned)
+		assert.Equal(t, []ObjectRef{{Kind: "configmap", Name: "bar"}}, result.WouldPrune)
+	})
+}
+
+func TestParseDeleteOutput(t *testing.T) {
+	t.Run("parseDeleteOutput_parses_object_lines", func(t *testing.T) {
+		out := `deployment.apps "nginx" deleted
+configmap "foo" force deleted
+warning: deleting cluster-scoped resources, not scoped to the provided namespace
+namespace "bar" deleted (server dry run)
+configmap "baz" deleted (dry run)
+`
+
+		result := parseDeleteOutput(out)
+
+		assert.Equal(t, []ObjectRef{
+			{Group: "apps", Kind: "deployment", Name: "nginx"},
+			{Kind: "configmap", Name: "foo"},
+		}, result.Deleted)
+		assert.Equal(t, []ObjectRef{
+			{Kind: "namespace", Name: "bar"},
+			{Kind: "configmap", Name: "baz"},
+		}, result.WouldDelete)
+	})
+
+	t.Run("notFoundObjects_returns_the_objects_that_were_not_deleted", func(t *testing.T) {
+		objs := mustDecodeManifests(t, configMapDataManifest("foo", "a", "b")+"---\n"+configMapDataManifest("bar", "a", "b"))
+		result := parseDeleteOutput("configmap \"foo\" deleted\n")
+
+		assert.Equal(t, []ObjectRef{{Kind: "configmap", Name: "bar"}}, notFoundObjects(objs, result))
+	})
+}
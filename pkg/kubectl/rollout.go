@@ -0,0 +1,242 @@
+package kubectl
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/kubectl/pkg/polymorphichelpers"
+	"k8s.io/kubectl/pkg/scheme"
+)
+
+type RolloutOptions struct {
+	/*
+		The kind of the workload, e.g. deployment, daemonset, statefulset or deployments.apps
+	*/
+	Kind string
+	/*
+		The name of the workload
+	*/
+	Name string
+	/*
+		The namespace of the workload, the namespace of the current context when not set
+	*/
+	Namespace string
+}
+
+/*
+RolloutRestart restarts the pods of the workload in the cluster that the kubeconfigPath points to i.e. kubectl rollout
+restart KIND/NAME. Like kubectl, the kubectl.kubernetes.io/restartedAt annotation of the pod template is set to the
+current time, which triggers a new rollout, e.g. to pick up a rotated secret. Deployments, DaemonSets and StatefulSets
+can be restarted, paused Deployments cannot. RolloutRestart returns once the rollout is triggered, use RolloutStatus to
+wait for it to complete.
+
+The workload is patched through the API with the restarter of kubectl, instead of running the kubectl rollout command,
+so it does not wait for the kubectl lock.
+
+Example:
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	err := RolloutRestart(ctx, "/path/to/kubeconfig", &RolloutOptions{
+		Kind:      "deployment",
+		Name:      "nginx",
+		Namespace: "default",
+	})
+	if err != nil {
+		// Handle error
+	}
+*/
+func RolloutRestart(ctx context.Context, kubeconfigPath string, opts *RolloutOptions) error {
+	if err := operations.begin(); err != nil {
+		return err
+	}
+	defer operations.end()
+
+	client, _, err := rolloutClient(kubeconfigPath, opts)
+	if err != nil {
+		return err
+	}
+
+	live, err := client.Get(ctx, opts.Name, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("could not get %s %s: %w", opts.Kind, opts.Name, err)
+	}
+
+	obj, err := scheme.Scheme.New(live.GroupVersionKind())
+	if err != nil {
+		return fmt.Errorf("could not restart %s %s: %w", opts.Kind, opts.Name, err)
+	}
+
+	err = runtime.DefaultUnstructuredConverter.FromUnstructured(live.Object, obj)
+	if err != nil {
+		return fmt.Errorf("could not convert %s %s: %w", opts.Kind, opts.Name, err)
+	}
+
+	patch, err := restartPatch(obj)
+	if err != nil {
+		return fmt.Errorf("could not restart %s %s: %w", opts.Kind, opts.Name, err)
+	}
+
+	if string(patch) == "{}" {
+		return fmt.Errorf("could not restart %s %s: it was already restarted within the past second", opts.Kind, opts.Name)
+	}
+
+	_, err = client.Patch(ctx, opts.Name, types.StrategicMergePatchType, patch, metav1.PatchOptions{FieldManager: FieldManager})
+	if err != nil {
+		return fmt.Errorf("could not patch %s %s: %w", opts.Kind, opts.Name, err)
+	}
+
+	return nil
+}
+
+/*
+RolloutStatus waits until the rollout of the workload in the cluster that the kubeconfigPath points to is complete, or
+the context is done i.e. kubectl rollout status KIND/NAME. Deployments, DaemonSets and StatefulSets are supported. The
+wait is bounded by the deadline of the context, or 15 seconds when the context has none, like the kubectl commands.
+
+The workload is polled through the API with the status viewer of kubectl, instead of running the kubectl rollout
+command, so it neither waits for the kubectl lock nor holds it while it waits.
+
+Example:
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	err := RolloutStatus(ctx, "/path/to/kubeconfig", &RolloutOptions{
+		Kind:      "deployment",
+		Name:      "nginx",
+		Namespace: "default",
+	})
+	if err != nil {
+		// Handle error
+	}
+*/
+func RolloutStatus(ctx context.Context, kubeconfigPath string, opts *RolloutOptions) error {
+	if err := operations.begin(); err != nil {
+		return err
+	}
+	defer operations.end()
+
+	client, mapping, err := rolloutClient(kubeconfigPath, opts)
+	if err != nil {
+		return err
+	}
+
+	viewer, err := polymorphichelpers.StatusViewerFn(mapping)
+	if err != nil {
+		return fmt.Errorf("could not get the rollout status of %s %s: %w", opts.Kind, opts.Name, err)
+	}
+
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 15*time.Second) // The same arbitrary deadline as watchContext
+		defer cancel()
+	}
+
+	status := ""
+	err = wait.PollUntilContextCancel(ctx, time.Second, true, func(ctx context.Context) (bool, error) {
+		obj, err := client.Get(ctx, opts.Name, metav1.GetOptions{})
+		if err != nil {
+			return false, fmt.Errorf("could not get %s %s: %w", opts.Kind, opts.Name, err)
+		}
+
+		var done bool
+		status, done, err = viewer.Status(obj, 0)
+		if err != nil {
+			return false, fmt.Errorf("rollout of %s %s failed: %w", opts.Kind, opts.Name, err)
+		}
+
+		return done, nil
+	})
+	if err != nil {
+		return fmt.Errorf("rollout of %s %s did not complete, last status: %s: %w", opts.Kind, opts.Name, status, err)
+	}
+
+	return nil
+}
+
+/*
+restartPatch returns the strategic merge patch that restarts the workload, like kubectl creates it from the difference
+between the original and the restarted workload. The workload is changed by it.
+*/
+func restartPatch(obj runtime.Object) ([]byte, error) {
+	// The restarter sets the annotation on the workload itself, so the original is encoded first
+	original, err := json.Marshal(obj)
+	if err != nil {
+		return nil, fmt.Errorf("could not encode workload: %w", err)
+	}
+
+	restarted, err := polymorphichelpers.ObjectRestarterFn(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	patch, err := strategicpatch.CreateTwoWayMergePatch(original, restarted, obj)
+	if err != nil {
+		return nil, fmt.Errorf("could not create patch: %w", err)
+	}
+
+	return patch, nil
+}
+
+/*
+rolloutClient validates the options of a rollout, and returns the client of the resource of the workload in its
+namespace, along with the mapping of the resource.
+*/
+func rolloutClient(kubeconfigPath string, opts *RolloutOptions) (dynamic.ResourceInterface, *meta.RESTMapping, error) {
+	if kubeconfigPath == "" {
+		return nil, nil, fmt.Errorf("kubeconfig path cannot be empty")
+	}
+
+	if opts == nil {
+		return nil, nil, fmt.Errorf("options cannot be nil")
+	}
+
+	if opts.Kind == "" || opts.Name == "" {
+		return nil, nil, fmt.Errorf("kind and name cannot be empty")
+	}
+
+	f := newNamespacedFactory(kubeconfigPath, opts.Namespace)
+
+	dynamicClient, err := f.DynamicClient()
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not create dynamic client: %w", err)
+	}
+
+	mapper, err := f.ToRESTMapper()
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not create rest mapper: %w", err)
+	}
+
+	namespace, _, err := f.ToRawKubeConfigLoader().Namespace()
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not determine default namespace: %w", err)
+	}
+
+	gvr, err := resolveResource(mapper, opts.Kind, "")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	gvk, err := mapper.KindFor(gvr)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not find kind for %s: %w", opts.Kind, err)
+	}
+
+	mapping, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not find resource for %s: %w", opts.Kind, err)
+	}
+
+	return dynamicClient.Resource(mapping.Resource).Namespace(namespace), mapping, nil
+}
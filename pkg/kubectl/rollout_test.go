@@ -0,0 +1,129 @@
+package kubectl
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Arneproductions/go-kube/internal/resources"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestRolloutOptions(t *testing.T) {
+	t.Run("RolloutRestart_and_RolloutStatus_should_reject_incomplete_options", func(t *testing.T) {
+		for _, opts := range []*RolloutOptions{
+			nil,
+			{Name: "nginx"},
+			{Kind: "deployment"},
+		} {
+			assert.Error(t, RolloutRestart(context.Background(), "/path/to/kubeconfig", opts))
+			assert.Error(t, RolloutStatus(context.Background(), "/path/to/kubeconfig", opts))
+		}
+
+		assert.Error(t, RolloutRestart(context.Background(), "", &RolloutOptions{Kind: "deployment", Name: "nginx"}))
+	})
+
+	t.Run("RolloutRestart_and_RolloutStatus_should_be_rejected_after_Shutdown", func(t *testing.T) {
+		original := operations
+		operations = &operationTracker{}
+		t.Cleanup(func() {
+			operations = original
+		})
+
+		require.NoError(t, Shutdown(context.Background()))
+
+		opts := &RolloutOptions{Kind: "deployment", Name: "nginx"}
+		assert.ErrorIs(t, RolloutRestart(context.Background(), "/path/to/kubeconfig", opts), ErrShuttingDown)
+		assert.ErrorIs(t, RolloutStatus(context.Background(), "/path/to/kubeconfig", opts), ErrShuttingDown)
+	})
+}
+
+func TestRestartPatch(t *testing.T) {
+	t.Run("restartPatch_should_set_the_restartedAt_annotation_of_the_pod_template", func(t *testing.T) {
+		deploy := &appsv1.Deployment{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "apps/v1", Kind: "Deployment"},
+			ObjectMeta: metav1.ObjectMeta{Name: "nginx", Namespace: "default"},
+		}
+
+		patch, err := restartPatch(deploy)
+		require.NoError(t, err)
+
+		restartedAt, found, err := unstructured.NestedString(decodePatch(t, patch), "spec", "template", "metadata", "annotations", "kubectl.kubernetes.io/restartedAt")
+		require.NoError(t, err)
+		require.True(t, found, string(patch))
+
+		_, err = time.Parse(time.RFC3339, restartedAt)
+		assert.NoError(t, err)
+	})
+
+	t.Run("restartPatch_should_reject_paused_deployments", func(t *testing.T) {
+		deploy := &appsv1.Deployment{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "apps/v1", Kind: "Deployment"},
+			ObjectMeta: metav1.ObjectMeta{Name: "nginx", Namespace: "default"},
+			Spec:       appsv1.DeploymentSpec{Paused: true},
+		}
+
+		_, err := restartPatch(deploy)
+		assert.Error(t, err)
+	})
+}
+
+func decodePatch(t *testing.T, patch []byte) map[string]interface{} {
+	decoded := map[string]interface{}{}
+	require.NoError(t, json.Unmarshal(patch, &decoded))
+
+	return decoded
+}
+
+func TestRollout(t *testing.T) {
+	c := resources.NewEphemeralCluster()
+	require.NoError(t, c.Start())
+
+	t.Cleanup(func() {
+		require.NoError(t, c.Stop())
+	})
+
+	t.Run("RolloutRestart_should_trigger_a_new_rollout_that_RolloutStatus_waits_for", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Minute)
+		defer cancel()
+
+		name := fmt.Sprintf("test-deploy-%s", uuid.New().String())
+		opts := &RolloutOptions{Kind: "deployment", Name: name, Namespace: "default"}
+
+		err := ApplyManifests(ctx, c.KubeConfigFilePath(), &ApplyManifestsOptions{}, writeTestManifest(t, loggingDeploymentManifest(name, 1)))
+		require.NoError(t, err)
+
+		err = RolloutStatus(ctx, c.KubeConfigFilePath(), opts)
+		require.NoError(t, err)
+
+		before, err := c.Client().AppsV1().Deployments("default").Get(ctx, name, metav1.GetOptions{})
+		require.NoError(t, err)
+
+		err = RolloutRestart(ctx, c.KubeConfigFilePath(), opts)
+		require.NoError(t, err)
+
+		err = RolloutStatus(ctx, c.KubeConfigFilePath(), opts)
+		require.NoError(t, err)
+
+		after, err := c.Client().AppsV1().Deployments("default").Get(ctx, name, metav1.GetOptions{})
+		require.NoError(t, err)
+
+		assert.NotEmpty(t, after.Spec.Template.Annotations["kubectl.kubernetes.io/restartedAt"])
+		assert.Greater(t, after.Generation, before.Generation)
+	})
+
+	t.Run("RolloutStatus_should_fail_for_missing_workloads", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		err := RolloutStatus(ctx, c.KubeConfigFilePath(), &RolloutOptions{Kind: "deployment", Name: "does-not-exist", Namespace: "default"})
+		assert.Error(t, err)
+	})
+}
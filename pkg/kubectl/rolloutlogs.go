@@ -0,0 +1,209 @@
+package kubectl
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+)
+
+/*
+LogsForRollout follows the logs of every pod of the workload in the namespace of the cluster that the kubeconfigPath
+points to, and writes them to w until the context is done. The pods are discovered as they come and go, so the logs
+of the old pods that are terminating during a rollout are interleaved with the logs of the new pods. Each line is
+prefixed with the pod and container it came from, like with LogsBySelector. A stream that fails is resumed after the
+last line that was written, so lines are neither lost nor repeated.
+
+The resourceType is a Deployment, StatefulSet, DaemonSet, ReplicaSet or Service, like with PodsFor. The pods of a
+Deployment are discovered across all of its ReplicaSets.
+
+Example:
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	err := LogsForRollout(ctx, "/path/to/kubeconfig", "deployment", "nginx", "default", os.Stdout)
+	if err != nil {
+		// Handle error
+	}
+*/
+func LogsForRollout(ctx context.Context, kubeconfigPath string, resourceType, name, namespace string, w io.Writer) error {
+	if kubeconfigPath == "" {
+		return fmt.Errorf("kubeconfig path cannot be empty")
+	}
+
+	if resourceType == "" || name == "" {
+		return fmt.Errorf("resource type and name cannot be empty")
+	}
+
+	clientset, err := newFactory(kubeconfigPath).KubernetesClientSet()
+	if err != nil {
+		return fmt.Errorf("could not create clientset: %w", err)
+	}
+
+	// The pods of all ReplicaSets of a Deployment match its selector, so the old and new pods are found alike
+	selector, err := workloadSelector(ctx, clientset, resourceType, name, namespace)
+	if err != nil {
+		return err
+	}
+
+	lw := &lineWriter{w: w}
+	wg := &sync.WaitGroup{}
+	defer wg.Wait()
+
+	// The containers of the pods that are streamed, or whose streams have ended
+	streamed := sets.New[string]()
+	// How far the logs of the containers were streamed, such that a stream that failed resumes where it left off
+	positions := map[string]*logPosition{}
+	mu := &sync.Mutex{}
+
+	// We discover the pods until the context is done, as a rollout creates new pods along the way
+	_ = wait.PollUntilContextCancel(ctx, time.Second, true, func(ctx context.Context) (bool, error) {
+		pods, err := clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{LabelSelector: selector.String()})
+		if err != nil {
+			// The pods are discovered again on the next poll
+			return false, nil
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		for _, pod := range pods.Items {
+			// The logs cannot be streamed before the containers have started
+			if pod.Status.Phase == corev1.PodPending {
+				continue
+			}
+
+			for _, container := range logContainers(pod, "") {
+				key := fmt.Sprintf("%s/%s", pod.UID, container)
+				if streamed.Has(key) {
+					continue
+				}
+				streamed.Insert(key)
+
+				position, ok := positions[key]
+				if !ok {
+					position = &logPosition{}
+					positions[key] = position
+				}
+
+				wg.Add(1)
+
+				go func(pod corev1.Pod, container, key string) {
+					defer wg.Done()
+
+					err := streamLogsFrom(ctx, clientset, pod, container, position, lw)
+					if err != nil {
+						// The stream is resumed on the next poll, as long as the pod is still there
+						mu.Lock()
+						streamed.Delete(key)
+						mu.Unlock()
+					}
+				}(pod, container, key)
+			}
+		}
+
+		return false, nil
+	})
+
+	return nil
+}
+
+/*
+logPosition is how far the logs of a container were streamed, i.e. the timestamp of the last line, and how many lines
+had that timestamp.
+*/
+type logPosition struct {
+	time  time.Time
+	lines int
+	// The lines at the timestamp that are yet to be skipped, as an earlier stream already returned them
+	skip int
+}
+
+/*
+streamLogsFrom follows the logs of the container from the position, and writes them to the lineWriter like streamLogs,
+advancing the position with every line. The logs are read since the second of the position, as that is the precision
+of the API, so the lines that were already written are skipped by their timestamps.
+*/
+func streamLogsFrom(ctx context.Context, clientset kubernetes.Interface, pod corev1.Pod, container string, position *logPosition, lw *lineWriter) error {
+	logOpts := &corev1.PodLogOptions{
+		Container:  container,
+		Follow:     true,
+		Timestamps: true,
+	}
+
+	if !position.time.IsZero() {
+		since := metav1.NewTime(position.time)
+		logOpts.SinceTime = &since
+	}
+
+	stream, err := clientset.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, logOpts).Stream(ctx)
+	if err != nil {
+		return fmt.Errorf("could not stream logs of pod %s container %s: %w", pod.Name, container, err)
+	}
+	defer stream.Close()
+
+	prefix := fmt.Sprintf("[pod/%s/%s] ", pod.Name, container)
+	position.skip = position.lines
+
+	scanner := bufio.NewScanner(stream)
+	for scanner.Scan() {
+		line, ok := position.advance(scanner.Text())
+		if !ok {
+			continue
+		}
+
+		err := lw.writeLine(prefix + line)
+		if err != nil {
+			return err
+		}
+	}
+
+	// A followed stream ends with an error when the context is done, which is how following is stopped
+	if err := scanner.Err(); err != nil && ctx.Err() == nil {
+		return fmt.Errorf("could not read logs of pod %s container %s: %w", pod.Name, container, err)
+	}
+
+	return nil
+}
+
+/*
+advance moves the position past the timestamped line, and returns the line without its timestamp. Lines before the
+position are not returned, nor are the lines at the position that are to be skipped.
+Lines without a valid timestamp are returned as they are, without moving the position.
+*/
+func (p *logPosition) advance(line string) (string, bool) {
+	timestamp, text, _ := strings.Cut(line, " ")
+
+	t, err := time.Parse(time.RFC3339Nano, timestamp)
+	if err != nil {
+		return line, true
+	}
+
+	switch {
+	case t.Before(p.time):
+		return "", false
+	case t.Equal(p.time):
+		if p.skip > 0 {
+			p.skip--
+			return "", false
+		}
+
+		p.lines++
+	default:
+		p.time = t
+		p.lines = 1
+		p.skip = 0
+	}
+
+	return text, true
+}
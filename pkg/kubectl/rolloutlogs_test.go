@@ -0,0 +1,116 @@
+package kubectl
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/Arneproductions/go-kube/internal/resources"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+func TestLogPosition(t *testing.T) {
+	t.Run("advance_should_skip_the_lines_of_an_earlier_stream", func(t *testing.T) {
+		position := &logPosition{}
+
+		for _, line := range []string{
+			"2024-01-01T00:00:00.100000000Z first",
+			"2024-01-01T00:00:00.200000000Z second",
+			"2024-01-01T00:00:00.200000000Z third",
+		} {
+			_, ok := position.advance(line)
+			require.True(t, ok)
+		}
+
+		// A resumed stream starts at the second of the position again
+		position.skip = position.lines
+
+		got := []string{}
+		for _, line := range []string{
+			"2024-01-01T00:00:00.100000000Z first",
+			"2024-01-01T00:00:00.200000000Z second",
+			"2024-01-01T00:00:00.200000000Z third",
+			"2024-01-01T00:00:00.200000000Z fourth",
+			"2024-01-01T00:00:01.000000000Z fifth",
+			"not timestamped",
+		} {
+			if text, ok := position.advance(line); ok {
+				got = append(got, text)
+			}
+		}
+
+		assert.Equal(t, []string{"fourth", "fifth", "not timestamped"}, got)
+	})
+}
+
+func TestLogsForRollout(t *testing.T) {
+	c := resources.NewEphemeralCluster()
+	require.NoError(t, c.Start())
+
+	t.Cleanup(func() {
+		require.NoError(t, c.Stop())
+	})
+
+	t.Run("LogsForRollout_reads_logs_from_old_and_new_pods", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Minute)
+		defer cancel()
+
+		name := fmt.Sprintf("test-rollout-%s", uuid.New().String())
+		manifest := writeTestManifest(t, loggingDeploymentManifest(name, 1))
+
+		err := ApplyManifests(ctx, c.KubeConfigFilePath(), &ApplyManifestsOptions{WaitForReady: true}, manifest)
+		require.NoError(t, err)
+
+		t.Cleanup(func() {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			defer cancel()
+
+			_ = c.Client().AppsV1().Deployments("default").Delete(ctx, name, metav1.DeleteOptions{})
+		})
+
+		logsCtx, stopLogs := context.WithCancel(ctx)
+		defer stopLogs()
+
+		buf := &syncBuffer{}
+		done := make(chan error)
+		go func() {
+			done <- LogsForRollout(logsCtx, c.KubeConfigFilePath(), "deployment", name, "default", buf)
+		}()
+
+		// Changing the pod template rolls out a new pod
+		patch := `{"spec":{"template":{"metadata":{"annotations":{"test.go-kube.io/restarted":"true"}}}}}`
+		_, err = c.Client().AppsV1().Deployments("default").Patch(ctx, name, types.MergePatchType, []byte(patch), metav1.PatchOptions{})
+		require.NoError(t, err)
+
+		hello := regexp.MustCompile(`\[pod/(\S+)/logger\] hello from (\S+)`)
+		err = wait.PollUntilContextCancel(ctx, time.Second, true, func(ctx context.Context) (bool, error) {
+			pods := sets.New[string]()
+			for _, match := range hello.FindAllStringSubmatch(buf.String(), -1) {
+				pods.Insert(match[1])
+			}
+
+			return pods.Len() >= 2, nil
+		})
+		require.NoError(t, err, buf.String())
+
+		stopLogs()
+		assert.NoError(t, <-done)
+	})
+
+	t.Run("LogsForRollout_should_error_for_unknown_workloads", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		err := LogsForRollout(ctx, c.KubeConfigFilePath(), "deployment", "does-not-exist", "default", &syncBuffer{})
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,147 @@
+package kubectl
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/kubectl/pkg/cmd/annotate"
+	"k8s.io/kubectl/pkg/cmd/apiresources"
+	"k8s.io/kubectl/pkg/cmd/apply"
+	"k8s.io/kubectl/pkg/cmd/create"
+	"k8s.io/kubectl/pkg/cmd/delete"
+	"k8s.io/kubectl/pkg/cmd/describe"
+	"k8s.io/kubectl/pkg/cmd/diff"
+	"k8s.io/kubectl/pkg/cmd/drain"
+	"k8s.io/kubectl/pkg/cmd/events"
+	"k8s.io/kubectl/pkg/cmd/explain"
+	"k8s.io/kubectl/pkg/cmd/expose"
+	"k8s.io/kubectl/pkg/cmd/get"
+	"k8s.io/kubectl/pkg/cmd/label"
+	"k8s.io/kubectl/pkg/cmd/logs"
+	"k8s.io/kubectl/pkg/cmd/patch"
+	"k8s.io/kubectl/pkg/cmd/replace"
+	"k8s.io/kubectl/pkg/cmd/scale"
+	"k8s.io/kubectl/pkg/cmd/set"
+	"k8s.io/kubectl/pkg/cmd/taint"
+	"k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/cmd/version"
+	"k8s.io/kubectl/pkg/cmd/wait"
+)
+
+/*
+RunKubectl runs an arbitrary kubectl command against the cluster that the kubeconfigPath points to, and returns what
+it wrote to its output and error streams, e.g. for the verbs and flags that have no function of their own. The args
+are the arguments of kubectl without the kubectl itself. The commands that are interactive, or that read from
+standard input, are not available.
+
+Example:
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	stdout, stderr, err := RunKubectl(ctx, "/path/to/kubeconfig", "get", "namespaces", "-o", "name")
+	if err != nil {
+		// Handle error, stderr tells why the command failed
+	}
+*/
+func RunKubectl(ctx context.Context, kubeconfigPath string, args ...string) (stdout string, stderr string, err error) {
+	// Like the apply command, any kubectl command may encounter a fatal error which
+	// changes global behaviour
+	lock, err := beginKubectl(ctx, fmt.Sprintf("kubectl %s", strings.Join(args, " ")))
+	if err != nil {
+		return "", "", err
+	}
+	defer lock.unlock()
+
+	if kubeconfigPath == "" {
+		return "", "", fmt.Errorf("kubeconfig path cannot be empty")
+	}
+
+	if len(args) == 0 {
+		return "", "", fmt.Errorf("no kubectl arguments given")
+	}
+
+	ioStreams, streamOut, streamErr := newIOStreams()
+
+	errChan := newErrChan()
+
+	// Send an error to the error channel when the context is cancelled or its deadline is reached
+	operation := fmt.Sprintf("kubectl %s", strings.Join(args, " "))
+	timeLeft, stopWatching := watchContext(ctx, operation, errChan, streamOut, streamErr)
+	defer stopWatching()
+
+	// The deadline may have passed while we waited for the kubectl lock
+	if err := expiredError(operation, timeLeft, streamOut, streamErr); err != nil {
+		return "", "", err
+	}
+
+	kubectlCmd := newKubectlCommand(kubeconfigPath, ioStreams, timeLeft)
+	kubectlCmd.SetArgs(args)
+
+	lock.run(errChan, streamOut, streamErr, func() error {
+		// Most kubectl commands call the fatal error handler which run overrides when they fail,
+		// while the others return their error
+		return kubectlCmd.ExecuteContext(ctx)
+	})
+
+	err = <-errChan
+
+	return streamOut.String(), streamErr.String(), err
+}
+
+/*
+newKubectlCommand creates a kubectl command with the subcommands that can run without a terminal, for the cluster that
+the kubeconfigPath points to. The global flags, e.g. --namespace, are added to the command as well. The request timeout
+defaults to the time left of the command, see withRequestTimeout.
+*/
+func newKubectlCommand(kubeconfigPath string, ioStreams genericiooptions.IOStreams, timeLeft time.Duration) *cobra.Command {
+	kubectlCmd := &cobra.Command{
+		Use:           "kubectl",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+	kubectlCmd.SetIn(ioStreams.In)
+	kubectlCmd.SetOut(ioStreams.Out)
+	kubectlCmd.SetErr(ioStreams.ErrOut)
+
+	config := withRequestTimeout(newConfigFlags(kubeconfigPath, ""), timeLeft)
+	config.AddFlags(kubectlCmd.PersistentFlags())
+
+	matchVersionConfig := util.NewMatchVersionFlags(config)
+	matchVersionConfig.AddFlags(kubectlCmd.PersistentFlags())
+
+	f := util.NewFactory(matchVersionConfig)
+
+	kubectlCmd.AddCommand(
+		annotate.NewCmdAnnotate("kubectl", f, ioStreams),
+		apiresources.NewCmdAPIResources(f, ioStreams),
+		apiresources.NewCmdAPIVersions(f, ioStreams),
+		apply.NewCmdApply("kubectl", f, ioStreams),
+		create.NewCmdCreate(f, ioStreams),
+		delete.NewCmdDelete(f, ioStreams),
+		describe.NewCmdDescribe("kubectl", f, ioStreams),
+		diff.NewCmdDiff(f, ioStreams),
+		drain.NewCmdCordon(f, ioStreams),
+		drain.NewCmdUncordon(f, ioStreams),
+		drain.NewCmdDrain(f, ioStreams),
+		events.NewCmdEvents(f, ioStreams),
+		explain.NewCmdExplain("kubectl", f, ioStreams),
+		expose.NewCmdExposeService(f, ioStreams),
+		get.NewCmdGet("kubectl", f, ioStreams),
+		label.NewCmdLabel(f, ioStreams),
+		logs.NewCmdLogs(f, ioStreams),
+		patch.NewCmdPatch(f, ioStreams),
+		replace.NewCmdReplace(f, ioStreams),
+		scale.NewCmdScale(f, ioStreams),
+		set.NewCmdSet(f, ioStreams),
+		taint.NewCmdTaint(f, ioStreams),
+		version.NewCmdVersion(f, ioStreams),
+		wait.NewCmdWait(f, ioStreams),
+	)
+
+	return kubectlCmd
+}
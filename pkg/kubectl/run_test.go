@@ -0,0 +1,66 @@
+package kubectl
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Arneproductions/go-kube/internal/resources"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewKubectlCommand(t *testing.T) {
+	t.Run("RunKubectl_should_reject_unknown_commands", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		_, _, err := RunKubectl(ctx, "/path/to/kubeconfig", "not-a-command")
+		assert.ErrorContains(t, err, "unknown command")
+	})
+
+	t.Run("newKubectlCommand_should_default_to_the_kubeconfig", func(t *testing.T) {
+		// The subcommands read the fatal error handler when they are created, which the kubectl lock guards
+		mu := kubectlLock()
+		mu.Lock()
+		defer mu.Unlock()
+
+		ioStreams, _, _ := newIOStreams()
+		kubectlCmd := newKubectlCommand("/path/to/kubeconfig", ioStreams, time.Minute)
+
+		flag := kubectlCmd.PersistentFlags().Lookup("kubeconfig")
+		require.NotNil(t, flag)
+		assert.Equal(t, "/path/to/kubeconfig", flag.Value.String())
+	})
+}
+
+func TestRunKubectl(t *testing.T) {
+	c := resources.NewEphemeralCluster()
+	require.NoError(t, c.Start())
+
+	t.Cleanup(func() {
+		require.NoError(t, c.Stop())
+	})
+
+	t.Run("RunKubectl_should_return_the_output_of_the_command", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		stdout, _, err := RunKubectl(ctx, c.KubeConfigFilePath(), "get", "namespaces", "-o", "name")
+		require.NoError(t, err)
+		assert.Contains(t, stdout, "namespace/default")
+	})
+
+	t.Run("RunKubectl_should_return_an_error_for_failing_commands", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		_, _, err := RunKubectl(ctx, c.KubeConfigFilePath(), "get", "not-a-resource")
+		assert.Error(t, err)
+	})
+
+	t.Run("RunKubectl_should_reject_empty_arguments", func(t *testing.T) {
+		_, _, err := RunKubectl(context.Background(), c.KubeConfigFilePath())
+		assert.Error(t, err)
+	})
+}
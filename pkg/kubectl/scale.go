@@ -0,0 +1,118 @@
+package kubectl
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/kubectl/pkg/cmd/scale"
+)
+
+type ScaleOptions struct {
+	/*
+		The kind of the workload to scale, e.g. deployment, statefulset or deployments.apps
+	*/
+	Kind string
+	/*
+		The name of the workload to scale
+	*/
+	Name string
+	/*
+		The namespace of the workload, the namespace of the current context when not set
+	*/
+	Namespace string
+	/*
+		The number of replicas to scale to i.e. kubectl scale --replicas
+	*/
+	Replicas int32
+	/*
+		Only scales the workload when it currently has this number of replicas i.e. kubectl scale --current-replicas.
+		There is no precondition when not set
+	*/
+	CurrentReplicas *int32
+}
+
+/*
+Scale sets the number of replicas of the workload in the cluster that the kubeconfigPath points to i.e. kubectl scale
+KIND/NAME --replicas=REPLICAS. Any kind with a scale subresource can be scaled, e.g. a Deployment or StatefulSet. Scale
+returns once the number of replicas is set, use WaitForReplicas to wait for the replicas to be ready.
+
+Example:
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	current := int32(1)
+	err := Scale(ctx, "/path/to/kubeconfig", &ScaleOptions{
+		Kind:            "deployment",
+		Name:            "nginx",
+		Namespace:       "default",
+		Replicas:        3,
+		CurrentReplicas: &current,
+	})
+	if err != nil {
+		// Handle error
+	}
+*/
+func Scale(ctx context.Context, kubeconfigPath string, opts *ScaleOptions) error {
+	// Like the apply command, the scale command may encounter a fatal error which
+	// changes global behaviour
+	lock, err := beginKubectl(ctx, "kubectl scale")
+	if err != nil {
+		return err
+	}
+	defer lock.unlock()
+
+	if kubeconfigPath == "" {
+		return fmt.Errorf("kubeconfig path cannot be empty")
+	}
+
+	if opts == nil {
+		return fmt.Errorf("options cannot be nil")
+	}
+
+	if opts.Kind == "" || opts.Name == "" {
+		return fmt.Errorf("kind and name cannot be empty")
+	}
+
+	if opts.Replicas < 0 {
+		return fmt.Errorf("replicas cannot be negative, got %d", opts.Replicas)
+	}
+
+	if opts.CurrentReplicas != nil && *opts.CurrentReplicas < 0 {
+		return fmt.Errorf("current replicas cannot be negative, got %d", *opts.CurrentReplicas)
+	}
+
+	resource := opts.Kind + "/" + opts.Name
+
+	ioStreams, streamOut, streamErr := newIOStreams()
+
+	errChan := newErrChan()
+
+	// Send an error to the error channel when the context is cancelled or its deadline is reached
+	operation := fmt.Sprintf("kubectl scale of %s to %d replicas", resource, opts.Replicas)
+	timeLeft, stopWatching := watchContext(ctx, operation, errChan, streamOut, streamErr)
+	defer stopWatching()
+
+	// The deadline may have passed while we waited for the kubectl lock
+	if err := expiredError(operation, timeLeft, streamOut, streamErr); err != nil {
+		return err
+	}
+
+	f := newCommandFactory(kubeconfigPath, opts.Namespace, timeLeft)
+
+	scaleCmd := scale.NewCmdScale(f, ioStreams)
+	scaleCmd.Flags().Set("replicas", fmt.Sprint(opts.Replicas))
+
+	if opts.CurrentReplicas != nil {
+		scaleCmd.Flags().Set("current-replicas", fmt.Sprint(*opts.CurrentReplicas))
+	}
+
+	lock.run(errChan, streamOut, streamErr, func() error {
+		// scaleCmd is blocking. Should it fail it should have called the fatal error handler which
+		// run overrides to send an error to errChan
+		scaleCmd.Run(scaleCmd, []string{resource})
+		return nil
+	})
+
+	return <-errChan
+}
@@ -0,0 +1,75 @@
+package kubectl
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Arneproductions/go-kube/internal/resources"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestScaleOptions(t *testing.T) {
+	t.Run("Scale_should_reject_invalid_options", func(t *testing.T) {
+		negative := int32(-1)
+
+		for _, opts := range []*ScaleOptions{
+			nil,
+			{Name: "nginx", Replicas: 1},
+			{Kind: "deployment", Replicas: 1},
+			{Kind: "deployment", Name: "nginx", Replicas: -1},
+			{Kind: "deployment", Name: "nginx", Replicas: 1, CurrentReplicas: &negative},
+		} {
+			assert.Error(t, Scale(context.Background(), "/path/to/kubeconfig", opts))
+		}
+	})
+}
+
+func TestScale(t *testing.T) {
+	c := resources.NewEphemeralCluster()
+	require.NoError(t, c.Start())
+
+	t.Cleanup(func() {
+		require.NoError(t, c.Stop())
+	})
+
+	t.Run("Scale_should_set_the_replicas_of_the_deployment", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		name := fmt.Sprintf("test-deploy-%s", uuid.New().String())
+
+		err := ApplyManifests(ctx, c.KubeConfigFilePath(), &ApplyManifestsOptions{}, writeTestManifest(t, loggingDeploymentManifest(name, 1)))
+		require.NoError(t, err)
+
+		current := int32(1)
+		err = Scale(ctx, c.KubeConfigFilePath(), &ScaleOptions{Kind: "deployment", Name: name, Namespace: "default", Replicas: 3, CurrentReplicas: &current})
+		require.NoError(t, err)
+
+		deploy, err := c.Client().AppsV1().Deployments("default").Get(ctx, name, metav1.GetOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, int32(3), *deploy.Spec.Replicas)
+	})
+
+	t.Run("Scale_should_fail_when_the_current_replicas_do_not_match", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		name := fmt.Sprintf("test-deploy-%s", uuid.New().String())
+
+		err := ApplyManifests(ctx, c.KubeConfigFilePath(), &ApplyManifestsOptions{}, writeTestManifest(t, loggingDeploymentManifest(name, 1)))
+		require.NoError(t, err)
+
+		current := int32(2)
+		err = Scale(ctx, c.KubeConfigFilePath(), &ScaleOptions{Kind: "deployment", Name: name, Namespace: "default", Replicas: 3, CurrentReplicas: &current})
+		assert.Error(t, err)
+
+		deploy, err := c.Client().AppsV1().Deployments("default").Get(ctx, name, metav1.GetOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, int32(1), *deploy.Spec.Replicas)
+	})
+}
@@ -0,0 +1,83 @@
+package kubectl
+
+import (
+	"context"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+/*
+ApplyServerSideAndGet server-side applies the given files like ApplyManifests with the ServerSide option set, and returns
+the objects as they are in the cluster after the apply, in the order of the manifests. The objects carry the fields that
+the API server and admission defaulted, e.g. the strategy of a Deployment.
+
+Example:
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objs, err := ApplyServerSideAndGet(ctx, "/path/to/kubeconfig", &ApplyManifestsOptions{}, "/path/to/deployment.yaml")
+	if err != nil {
+		// Handle error
+	}
+
+	strategy, _, _ := unstructured.NestedString(objs[0].Object, "spec", "strategy", "type")
+*/
+func ApplyServerSideAndGet(ctx context.Context, kubeconfigPath string, opts *ApplyManifestsOptions, filePaths ...string) ([]*unstructured.Unstructured, error) {
+	if opts == nil {
+		return nil, fmt.Errorf("options cannot be nil")
+	}
+
+	if opts.DryRun != DryRunNone {
+		return nil, fmt.Errorf("dry-run is not supported, as the objects are read from the cluster after the apply")
+	}
+
+	serverSideOpts := *opts
+	serverSideOpts.ServerSide = true
+
+	err := ApplyManifests(ctx, kubeconfigPath, &serverSideOpts, filePaths...)
+	if err != nil {
+		return nil, err
+	}
+
+	objs, err := readManifests(filePaths, opts.Recursive)
+	if err != nil {
+		return nil, err
+	}
+
+	f := newFactory(kubeconfigPath)
+
+	dynamicClient, err := f.DynamicClient()
+	if err != nil {
+		return nil, fmt.Errorf("could not create dynamic client: %w", err)
+	}
+
+	mapper, err := f.ToRESTMapper()
+	if err != nil {
+		return nil, fmt.Errorf("could not create rest mapper: %w", err)
+	}
+
+	namespace, _, err := f.ToRawKubeConfigLoader().Namespace()
+	if err != nil {
+		return nil, fmt.Errorf("could not determine default namespace: %w", err)
+	}
+
+	live := []*unstructured.Unstructured{}
+	for _, obj := range objs {
+		client, err := objectClient(dynamicClient, mapper, namespace, obj)
+		if err != nil {
+			return nil, err
+		}
+
+		liveObj, err := client.Get(ctx, obj.GetName(), metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("could not get %s %s: %w", obj.GetKind(), obj.GetName(), err)
+		}
+
+		live = append(live, liveObj)
+	}
+
+	return live, nil
+}
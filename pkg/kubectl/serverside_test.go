@@ -0,0 +1,65 @@
+package kubectl
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Arneproductions/go-kube/internal/resources"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestApplyServerSideAndGet(t *testing.T) {
+	c := resources.NewEphemeralCluster()
+	require.NoError(t, c.Start())
+
+	t.Cleanup(func() {
+		require.NoError(t, c.Stop())
+	})
+
+	t.Run("ApplyServerSideAndGet_should_return_server_defaulted_fields", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		name := fmt.Sprintf("test-deploy-%s", uuid.New().String())
+
+		objs, err := ApplyServerSideAndGet(ctx, c.KubeConfigFilePath(), &ApplyManifestsOptions{}, writeTestManifest(t, loggingDeploymentManifest(name, 1)))
+		require.NoError(t, err)
+
+		t.Cleanup(func() {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			defer cancel()
+
+			_ = c.Client().AppsV1().Deployments("default").Delete(ctx, name, metav1.DeleteOptions{})
+		})
+
+		require.Len(t, objs, 1)
+		assert.Equal(t, name, objs[0].GetName())
+
+		// The manifest leaves the strategy out, so it can only have been defaulted by the API server
+		strategy, found, err := unstructured.NestedString(objs[0].Object, "spec", "strategy", "type")
+		require.NoError(t, err)
+		assert.True(t, found)
+		assert.Equal(t, "RollingUpdate", strategy)
+
+		for _, entry := range objs[0].GetManagedFields() {
+			if entry.Manager == FieldManager {
+				assert.Equal(t, metav1.ManagedFieldsOperationApply, entry.Operation)
+			}
+		}
+	})
+
+	t.Run("ApplyServerSideAndGet_should_reject_dry_run", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		_, err := ApplyServerSideAndGet(ctx, c.KubeConfigFilePath(), &ApplyManifestsOptions{DryRun: DryRunServer}, writeTestManifest(t, configMapDataManifest("foo", "foo", "bar")))
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,80 @@
+package kubectl
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/version"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+var (
+	// Clusters older than this version create a token secret for every service account
+	legacyTokenVersion = version.MajorMinor(1, 24)
+)
+
+/*
+WaitForDefaultServiceAccount waits until the default ServiceAccount of the namespace exists in the cluster that the
+kubeconfigPath points to. On clusters older than v1.24 it also waits for the token secret of the ServiceAccount.
+
+Pods cannot be created in a namespace before its default ServiceAccount exists.
+
+Example:
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	err := WaitForDefaultServiceAccount(ctx, "/path/to/kubeconfig", "my-namespace")
+	if err != nil {
+		// Handle error
+	}
+*/
+func WaitForDefaultServiceAccount(ctx context.Context, kubeconfigPath string, namespace string) error {
+	if kubeconfigPath == "" {
+		return fmt.Errorf("kubeconfig path cannot be empty")
+	}
+
+	if namespace == "" {
+		return fmt.Errorf("namespace cannot be empty")
+	}
+
+	clientset, err := newFactory(kubeconfigPath).KubernetesClientSet()
+	if err != nil {
+		return fmt.Errorf("could not create clientset: %w", err)
+	}
+
+	serverVersion, err := clientset.Discovery().ServerVersion()
+	if err != nil {
+		return fmt.Errorf("could not get server version: %w", err)
+	}
+
+	parsedVersion, err := version.ParseGeneric(serverVersion.GitVersion)
+	if err != nil {
+		return fmt.Errorf("could not parse server version %s: %w", serverVersion.GitVersion, err)
+	}
+	needsToken := parsedVersion.LessThan(legacyTokenVersion)
+
+	err = wait.PollUntilContextCancel(ctx, time.Second, true, func(ctx context.Context) (bool, error) {
+		sa, err := clientset.CoreV1().ServiceAccounts(namespace).Get(ctx, "default", metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+
+		if needsToken && len(sa.Secrets) == 0 {
+			return false, nil
+		}
+
+		return true, nil
+	})
+	if err != nil {
+		return fmt.Errorf("default service account in namespace %s is not ready: %w", namespace, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,82 @@
+package kubectl
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/Arneproductions/go-kube/internal/resources"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestWaitForDefaultServiceAccount(t *testing.T) {
+	c := resources.NewEphemeralCluster()
+	require.NoError(t, c.Start())
+
+	t.Cleanup(func() {
+		require.NoError(t, c.Stop())
+	})
+
+	t.Run("WaitForDefaultServiceAccount_allows_pods_to_be_applied", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		ns := fmt.Sprintf("test-ns-%s", uuid.New().String())
+
+		_, err := c.Client().CoreV1().Namespaces().Create(ctx, &corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{Name: ns},
+		}, metav1.CreateOptions{})
+		require.NoError(t, err)
+
+		t.Cleanup(func() {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			defer cancel()
+
+			_ = c.Client().CoreV1().Namespaces().Delete(ctx, ns, metav1.DeleteOptions{})
+		})
+
+		err = WaitForDefaultServiceAccount(ctx, c.KubeConfigFilePath(), ns)
+		require.NoError(t, err)
+
+		manifest := fmt.Sprintf(`
+apiVersion: v1
+kind: Pod
+metadata:
+  name: test-pod
+  namespace: %s
+spec:
+  containers:
+  - name: nginx
+    image: nginx:1.14.2
+`, ns)
+
+		tmpFile, err := os.CreateTemp("", "test-pod-*.yaml")
+		require.NoError(t, err)
+
+		t.Cleanup(func() {
+			os.Remove(tmpFile.Name())
+		})
+
+		_, err = tmpFile.WriteString(manifest)
+		require.NoError(t, err)
+		require.NoError(t, tmpFile.Close())
+
+		err = applyFunc(ctx, c.KubeConfigFilePath(), &applyOptions{}, tmpFile.Name())
+		assert.NoError(t, err)
+	})
+
+	t.Run("WaitForDefaultServiceAccount_should_error_when_namespace_is_empty", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		err := WaitForDefaultServiceAccount(ctx, c.KubeConfigFilePath(), "")
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,91 @@
+package kubectl
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+var (
+	// ErrShuttingDown is returned by operations that are started after Shutdown has been called
+	ErrShuttingDown = errors.New("go-kube is shutting down")
+
+	operations = &operationTracker{}
+)
+
+/*
+operationTracker keeps track of the operations in flight, such that they can be drained on shutdown.
+*/
+type operationTracker struct {
+	mu       sync.Mutex
+	inFlight int
+	closed   bool
+	drained  chan struct{}
+}
+
+/*
+begin registers a new operation, unless the tracker has been shut down. Every successful call must be followed by a call to end.
+*/
+func (t *operationTracker) begin() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.closed {
+		return ErrShuttingDown
+	}
+
+	t.inFlight++
+
+	return nil
+}
+
+func (t *operationTracker) end() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.inFlight--
+
+	if t.closed && t.inFlight == 0 {
+		close(t.drained)
+	}
+}
+
+func (t *operationTracker) shutdown(ctx context.Context) error {
+	t.mu.Lock()
+	if !t.closed {
+		t.closed = true
+		t.drained = make(chan struct{})
+
+		if t.inFlight == 0 {
+			close(t.drained)
+		}
+	}
+	drained := t.drained
+	t.mu.Unlock()
+
+	select {
+	case <-drained:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+/*
+Shutdown stops accepting new apply and delete operations, and waits for the operations in flight to finish or the
+context to be done. Operations started after Shutdown has been called fail with ErrShuttingDown. A kubectl command
+that timed out is in flight until kubectl gives up on it, also when the function that started it has returned.
+
+Example:
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	err := Shutdown(ctx)
+	if err != nil {
+		// Operations did not finish in time
+	}
+*/
+func Shutdown(ctx context.Context) error {
+	return operations.shutdown(ctx)
+}
@@ -0,0 +1,116 @@
+package kubectl
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Arneproductions/go-kube/internal/resources"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+func TestShutdown(t *testing.T) {
+	c := resources.NewEphemeralCluster()
+	require.NoError(t, c.Start())
+
+	t.Cleanup(func() {
+		require.NoError(t, c.Stop())
+	})
+
+	t.Run("Shutdown_rejects_new_operations_and_drains_in_flight_ones", func(t *testing.T) {
+		// We use a tracker of our own, as shutting down the package tracker would break the other tests
+		original := operations
+		operations = &operationTracker{}
+		t.Cleanup(func() {
+			operations = original
+		})
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		randomNS := fmt.Sprintf("test-ns-%s", uuid.New().String())
+		manifest := writeTestManifest(t, namespaceManifest(randomNS))
+
+		// We hold the kubectl lock, such that the in-flight apply is kept waiting until we release it
+		mu := kubectlLock()
+		mu.Lock()
+
+		inFlightErr := make(chan error, 1)
+		go func() {
+			inFlightErr <- applyFunc(ctx, c.KubeConfigFilePath(), &applyOptions{}, manifest)
+		}()
+
+		err := wait.PollUntilContextCancel(ctx, 10*time.Millisecond, true, func(ctx context.Context) (bool, error) {
+			operations.mu.Lock()
+			defer operations.mu.Unlock()
+
+			return operations.inFlight == 1, nil
+		})
+		require.NoError(t, err)
+
+		shutdownErr := make(chan error, 1)
+		go func() {
+			shutdownErr <- Shutdown(ctx)
+		}()
+
+		err = wait.PollUntilContextCancel(ctx, 10*time.Millisecond, true, func(ctx context.Context) (bool, error) {
+			operations.mu.Lock()
+			defer operations.mu.Unlock()
+
+			return operations.closed, nil
+		})
+		require.NoError(t, err)
+
+		err = applyFunc(ctx, c.KubeConfigFilePath(), &applyOptions{}, manifest)
+		assert.ErrorIs(t, err, ErrShuttingDown)
+
+		mu.Unlock()
+
+		assert.NoError(t, <-inFlightErr)
+		assert.NoError(t, <-shutdownErr)
+
+		_, err = c.Client().CoreV1().Namespaces().Get(ctx, randomNS, metav1.GetOptions{})
+		assert.NoError(t, err)
+	})
+}
+
+func TestBeginKubectl(t *testing.T) {
+	t.Run("beginKubectl_should_keep_the_operation_in_flight_until_the_command_is_done", func(t *testing.T) {
+		original := operations
+		operations = &operationTracker{}
+		t.Cleanup(func() {
+			operations = original
+		})
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+
+		lock, err := beginKubectl(ctx, "kubectl apply")
+		require.NoError(t, err)
+
+		release := make(chan struct{})
+		errChan := newErrChan()
+		lock.run(errChan, &syncBuffer{}, &syncBuffer{}, func() error {
+			<-release
+			return nil
+		})
+
+		// The caller returns, e.g. as the command timed out, while the command is still running
+		lock.unlock()
+
+		shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancelShutdown()
+		assert.ErrorIs(t, Shutdown(shutdownCtx), context.DeadlineExceeded)
+
+		close(release)
+		assert.NoError(t, <-errChan)
+
+		assert.NoError(t, Shutdown(ctx))
+	})
+}
@@ -0,0 +1,132 @@
+package kubectl
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+/*
+NamespaceSnapshot holds the objects of a namespace at the time it was snapshotted
+*/
+type NamespaceSnapshot struct {
+	Namespace string
+	// The objects of the namespace, stripped of the fields managed by the API server
+	Objects []*unstructured.Unstructured
+}
+
+/*
+SnapshotNamespace captures the objects of the namespace in the cluster that the kubeconfigPath points to, such that the
+namespace can be restored with RestoreNamespace later. The same resources as ExportNamespace are captured.
+
+Example:
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	snap, err := SnapshotNamespace(ctx, "/path/to/kubeconfig", "my-namespace")
+	if err != nil {
+		// Handle error
+	}
+
+	// Mutate the namespace
+
+	err = RestoreNamespace(ctx, "/path/to/kubeconfig", snap)
+	if err != nil {
+		// Handle error
+	}
+*/
+func SnapshotNamespace(ctx context.Context, kubeconfigPath string, ns string) (*NamespaceSnapshot, error) {
+	if kubeconfigPath == "" {
+		return nil, fmt.Errorf("kubeconfig path cannot be empty")
+	}
+
+	if ns == "" {
+		return nil, fmt.Errorf("namespace cannot be empty")
+	}
+
+	dynamicClient, err := newFactory(kubeconfigPath).DynamicClient()
+	if err != nil {
+		return nil, fmt.Errorf("could not create dynamic client: %w", err)
+	}
+
+	objs, err := exportNamespaceObjects(ctx, dynamicClient, ns)
+	if err != nil {
+		return nil, err
+	}
+
+	return &NamespaceSnapshot{
+		Namespace: ns,
+		Objects:   objs,
+	}, nil
+}
+
+/*
+RestoreNamespace restores the namespace to the snapshot, by re-applying the objects of the snapshot and deleting the
+objects that were created since the snapshot was taken.
+*/
+func RestoreNamespace(ctx context.Context, kubeconfigPath string, snap *NamespaceSnapshot) error {
+	if kubeconfigPath == "" {
+		return fmt.Errorf("kubeconfig path cannot be empty")
+	}
+
+	if snap == nil {
+		return fmt.Errorf("snapshot cannot be nil")
+	}
+
+	if len(snap.Objects) > 0 {
+		manifestPath, err := writeManifests(snap.Objects)
+		if err != nil {
+			return err
+		}
+		defer os.Remove(manifestPath)
+
+		err = ApplyManifests(ctx, kubeconfigPath, &ApplyManifestsOptions{}, manifestPath)
+		if err != nil {
+			return fmt.Errorf("could not restore namespace %s: %w", snap.Namespace, err)
+		}
+	}
+
+	dynamicClient, err := newFactory(kubeconfigPath).DynamicClient()
+	if err != nil {
+		return fmt.Errorf("could not create dynamic client: %w", err)
+	}
+
+	snapshotted := map[ObjectRef]bool{}
+	for _, obj := range snap.Objects {
+		snapshotted[objectRefOf(obj)] = true
+	}
+
+	for _, gvr := range exportResources {
+		client := dynamicClient.Resource(gvr).Namespace(snap.Namespace)
+
+		list, err := client.List(ctx, metav1.ListOptions{})
+		if apierrors.IsNotFound(err) {
+			// The resource is not served by the cluster
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("could not list %s in namespace %s: %w", gvr.Resource, snap.Namespace, err)
+		}
+
+		for i := range list.Items {
+			obj := &list.Items[i]
+
+			// Objects created by a controller are cleaned up by the controller
+			if snapshotted[objectRefOf(obj)] || metav1.GetControllerOf(obj) != nil {
+				continue
+			}
+
+			err := client.Delete(ctx, obj.GetName(), metav1.DeleteOptions{})
+			if err != nil && !apierrors.IsNotFound(err) {
+				return fmt.Errorf("could not delete %s %s: %w", obj.GetKind(), obj.GetName(), err)
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,79 @@
+package kubectl
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Arneproductions/go-kube/internal/resources"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestSnapshotNamespace(t *testing.T) {
+	c := resources.NewEphemeralCluster()
+	require.NoError(t, c.Start())
+
+	t.Cleanup(func() {
+		require.NoError(t, c.Stop())
+	})
+
+	t.Run("RestoreNamespace_should_only_keep_the_snapshot_objects", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+		defer cancel()
+
+		namespace := fmt.Sprintf("test-ns-%s", uuid.New().String())
+		manifest := writeTestManifest(t, namespaceManifest(namespace)+fmt.Sprintf(`
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: kept
+  namespace: %s
+data:
+  foo: bar
+`, namespace))
+
+		err := ApplyManifests(ctx, c.KubeConfigFilePath(), &ApplyManifestsOptions{EnsureNamespaces: true}, manifest)
+		require.NoError(t, err)
+
+		t.Cleanup(func() {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			defer cancel()
+
+			_ = c.Client().CoreV1().Namespaces().Delete(ctx, namespace, metav1.DeleteOptions{})
+		})
+
+		snap, err := SnapshotNamespace(ctx, c.KubeConfigFilePath(), namespace)
+		require.NoError(t, err)
+
+		// We mutate the namespace after the snapshot
+		kept, err := c.Client().CoreV1().ConfigMaps(namespace).Get(ctx, "kept", metav1.GetOptions{})
+		require.NoError(t, err)
+
+		kept.Data["foo"] = "changed"
+		_, err = c.Client().CoreV1().ConfigMaps(namespace).Update(ctx, kept, metav1.UpdateOptions{})
+		require.NoError(t, err)
+
+		_, err = c.Client().CoreV1().ConfigMaps(namespace).Create(ctx, &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "extra"},
+		}, metav1.CreateOptions{})
+		require.NoError(t, err)
+
+		err = RestoreNamespace(ctx, c.KubeConfigFilePath(), snap)
+		require.NoError(t, err)
+
+		kept, err = c.Client().CoreV1().ConfigMaps(namespace).Get(ctx, "kept", metav1.GetOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, "bar", kept.Data["foo"])
+
+		_, err = c.Client().CoreV1().ConfigMaps(namespace).Get(ctx, "extra", metav1.GetOptions{})
+		assert.True(t, apierrors.IsNotFound(err))
+	})
+}
@@ -0,0 +1,202 @@
+package kubectl
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"sigs.k8s.io/kustomize/api/konfig"
+)
+
+// sourceKind is the kind of input that a Source reads its manifests from
+type sourceKind uint8
+
+const (
+	sourceKindPath sourceKind = iota
+	sourceKindURL
+	sourceKindBytes
+)
+
+// Source is an input of manifests for ApplySources, created with PathSource, URLSource or BytesSource
+type Source struct {
+	kind         sourceKind
+	path         string
+	data         []byte
+	fieldManager string
+}
+
+/*
+PathSource is a manifest file, a directory of manifest files or a kustomization directory. Directories with a
+kustomization file are built as kustomizations.
+*/
+func PathSource(path string) Source {
+	return Source{kind: sourceKindPath, path: path}
+}
+
+/*
+URLSource is a manifest fetched from the http or https URL.
+*/
+func URLSource(url string) Source {
+	return Source{kind: sourceKindURL, path: url}
+}
+
+/*
+BytesSource is a YAML or JSON manifest of one or more documents.
+*/
+func BytesSource(data []byte) Source {
+	return Source{kind: sourceKindBytes, data: data}
+}
+
+/*
+WithFieldManager returns the source applied server-side with its own field manager instead of FieldManager, such that
+the fields of its objects are owned by the field manager, e.g. to tell the parts of a composite deploy apart.
+*/
+func (s Source) WithFieldManager(fieldManager string) Source {
+	s.fieldManager = fieldManager
+	return s
+}
+
+func (s Source) String() string {
+	if s.kind == sourceKindBytes {
+		return fmt.Sprintf("%d bytes", len(s.data))
+	}
+
+	return s.path
+}
+
+/*
+ApplySources applies the manifests of every source to the cluster that the kubeconfigPath points to with the given
+ApplyManifestsOptions, one source at a time in the given order. Kustomizations are built before they are applied, such
+that the options apply to their objects like to the objects of any other manifest. The results of the sources are merged.
+Sources with their own field manager are applied server-side with it, see Source.WithFieldManager. Prune is rejected, as
+pruning the objects of one source would delete the objects of the sources applied before it.
+
+Example:
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	result, err := ApplySources(
+		ctx,
+		"/path/to/kubeconfig",
+		&ApplyManifestsOptions{},
+		PathSource("/path/to/manifest.yaml"),
+		PathSource("/path/to/kustomization"),
+		URLSource("https://example.com/manifest.yaml"),
+		BytesSource(manifest),
+		PathSource("/path/to/addons").WithFieldManager("addons"),
+	)
+	if err != nil {
+		// Handle error
+	}
+*/
+func ApplySources(ctx context.Context, kubeconfigPath string, opts *ApplyManifestsOptions, sources ...Source) (*ApplyResult, error) {
+	if opts == nil {
+		return nil, fmt.Errorf("options cannot be nil")
+	}
+
+	if len(sources) == 0 {
+		return nil, fmt.Errorf("no sources to apply")
+	}
+
+	if opts.Prune {
+		return nil, fmt.Errorf("sources cannot be applied with prune, as every source would prune the objects of the others")
+	}
+
+	result := &ApplyResult{}
+	for _, source := range sources {
+		manifestPath, cleanup, err := sourceManifest(ctx, source)
+		if err != nil {
+			return result, err
+		}
+
+		sourceResult, err := ApplyManifestsWithResult(ctx, kubeconfigPath, sourceOptions(opts, source), manifestPath)
+		cleanup()
+		if sourceResult != nil {
+			result.merge(sourceResult)
+		}
+		if err != nil {
+			return result, fmt.Errorf("could not apply source %s: %w", source, err)
+		}
+	}
+
+	return result, nil
+}
+
+/*
+sourceOptions returns the options to apply the source with, which apply server-side with the field manager of the source
+when it has one.
+*/
+func sourceOptions(opts *ApplyManifestsOptions, source Source) *ApplyManifestsOptions {
+	if source.fieldManager == "" {
+		return opts
+	}
+
+	sourceOpts := *opts
+	sourceOpts.ServerSide = true
+	sourceOpts.fieldManager = source.fieldManager
+
+	return &sourceOpts
+}
+
+/*
+sourceManifest returns the path or URL to apply for the source. Kustomizations and bytes are written to a temporary
+manifest file, which the returned cleanup function removes.
+*/
+func sourceManifest(ctx context.Context, source Source) (string, func(), error) {
+	cleanup := func() {}
+
+	switch source.kind {
+	case sourceKindURL:
+		return source.path, cleanup, nil
+
+	case sourceKindBytes:
+		objs, err := decodeManifests(source.data, source.String())
+		if err != nil {
+			return "", cleanup, err
+		}
+
+		manifestPath, err := writeManifests(objs)
+		if err != nil {
+			return "", cleanup, err
+		}
+
+		return manifestPath, func() { os.Remove(manifestPath) }, nil
+	}
+
+	if !isKustomization(source.path) {
+		return source.path, cleanup, nil
+	}
+
+	objs, err := buildKustomization(ctx, LoadRestrictorRootOnly, source.path)
+	if err != nil {
+		return "", cleanup, err
+	}
+
+	manifestPath, err := writeManifests(objs)
+	if err != nil {
+		return "", cleanup, err
+	}
+
+	return manifestPath, func() { os.Remove(manifestPath) }, nil
+}
+
+/*
+isKustomization reports whether the path is a directory with a kustomization file.
+*/
+func isKustomization(path string) bool {
+	if strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://") {
+		return false
+	}
+
+	for _, name := range konfig.RecognizedKustomizationFileNames() {
+		_, err := os.Stat(filepath.Join(path, name))
+		if err == nil {
+			return true
+		}
+	}
+
+	return false
+}
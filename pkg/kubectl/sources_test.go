@@ -0,0 +1,175 @@
+package kubectl
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Arneproductions/go-kube/internal/resources"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// writeTestKustomization writes a kustomization of a config map with the name to a temporary directory
+func writeTestKustomization(t *testing.T, name string) string {
+	dir, err := os.MkdirTemp("", "test-kustomization-*")
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		os.RemoveAll(dir)
+	})
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "kustomization.yaml"), []byte("resources:\n- configmap.yaml\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "configmap.yaml"), []byte(configMapDataManifest(name, "foo", "bar")), 0644))
+
+	return dir
+}
+
+func TestSourceManifest(t *testing.T) {
+	t.Run("sourceManifest_should_build_kustomizations", func(t *testing.T) {
+		dir := writeTestKustomization(t, "kustomized")
+		require.True(t, isKustomization(dir))
+
+		manifestPath, cleanup, err := sourceManifest(context.Background(), PathSource(dir))
+		require.NoError(t, err)
+		defer cleanup()
+
+		objs, err := readManifests([]string{manifestPath}, false)
+		require.NoError(t, err)
+		require.Len(t, objs, 1)
+		assert.Equal(t, "kustomized", objs[0].GetName())
+	})
+
+	t.Run("sourceManifest_should_write_bytes_to_a_manifest", func(t *testing.T) {
+		manifestPath, cleanup, err := sourceManifest(context.Background(), BytesSource([]byte(configMapDataManifest("from-bytes", "foo", "bar"))))
+		require.NoError(t, err)
+
+		objs, err := readManifests([]string{manifestPath}, false)
+		require.NoError(t, err)
+		require.Len(t, objs, 1)
+		assert.Equal(t, "from-bytes", objs[0].GetName())
+
+		cleanup()
+		assert.NoFileExists(t, manifestPath)
+	})
+
+	t.Run("sourceOptions_should_apply_server_side_with_the_field_manager_of_the_source", func(t *testing.T) {
+		opts := &ApplyManifestsOptions{}
+
+		assert.Same(t, opts, sourceOptions(opts, BytesSource(nil)))
+
+		sourceOpts := sourceOptions(opts, BytesSource(nil).WithFieldManager("team-a"))
+		assert.True(t, sourceOpts.ServerSide)
+		assert.Equal(t, "team-a", fieldManagerOf(sourceOpts))
+		assert.Equal(t, FieldManager, fieldManagerOf(opts), "the options of the caller are left as they are")
+	})
+
+	t.Run("sourceManifest_should_pass_plain_paths_through", func(t *testing.T) {
+		manifest := writeTestManifest(t, configMapDataManifest("plain", "foo", "bar"))
+		assert.False(t, isKustomization(manifest))
+
+		manifestPath, cleanup, err := sourceManifest(context.Background(), PathSource(manifest))
+		require.NoError(t, err)
+		defer cleanup()
+
+		assert.Equal(t, manifest, manifestPath)
+	})
+}
+
+func TestApplySourcesOptions(t *testing.T) {
+	t.Run("ApplySources_should_reject_prune", func(t *testing.T) {
+		_, err := ApplySources(context.Background(), "/path/to/kubeconfig", &ApplyManifestsOptions{Prune: true, Selector: "app=foo"}, BytesSource(nil))
+		assert.ErrorContains(t, err, "prune")
+	})
+}
+
+func TestApplySources(t *testing.T) {
+	c := resources.NewEphemeralCluster()
+	require.NoError(t, c.Start())
+
+	t.Cleanup(func() {
+		require.NoError(t, c.Stop())
+	})
+
+	t.Run("ApplySources_should_apply_manifests_and_kustomizations_in_one_call", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		plainName := fmt.Sprintf("test-cm-%s", uuid.New().String())
+		kustomizedName := fmt.Sprintf("test-cm-%s", uuid.New().String())
+
+		t.Cleanup(func() {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			defer cancel()
+
+			_ = c.Client().CoreV1().ConfigMaps("default").Delete(ctx, plainName, metav1.DeleteOptions{})
+			_ = c.Client().CoreV1().ConfigMaps("default").Delete(ctx, kustomizedName, metav1.DeleteOptions{})
+		})
+
+		result, err := ApplySources(
+			ctx,
+			c.KubeConfigFilePath(),
+			&ApplyManifestsOptions{},
+			PathSource(writeTestManifest(t, configMapDataManifest(plainName, "foo", "bar"))),
+			PathSource(writeTestKustomization(t, kustomizedName)),
+		)
+		require.NoError(t, err)
+
+		assert.Equal(t, []ObjectResult{
+			{ObjectRef: ObjectRef{Kind: "configmap", Name: plainName}, Namespace: "default", Operation: OperationCreated},
+			{ObjectRef: ObjectRef{Kind: "configmap", Name: kustomizedName}, Namespace: "default", Operation: OperationCreated},
+		}, result.Objects)
+
+		for _, name := range []string{plainName, kustomizedName} {
+			_, err = c.Client().CoreV1().ConfigMaps("default").Get(ctx, name, metav1.GetOptions{})
+			assert.NoError(t, err, name)
+		}
+	})
+
+	t.Run("ApplySources_should_apply_every_source_with_its_own_field_manager", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		names := map[string]string{
+			"team-a": fmt.Sprintf("test-cm-%s", uuid.New().String()),
+			"team-b": fmt.Sprintf("test-cm-%s", uuid.New().String()),
+		}
+
+		t.Cleanup(func() {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			defer cancel()
+
+			for _, name := range names {
+				_ = c.Client().CoreV1().ConfigMaps("default").Delete(ctx, name, metav1.DeleteOptions{})
+			}
+		})
+
+		_, err := ApplySources(
+			ctx,
+			c.KubeConfigFilePath(),
+			&ApplyManifestsOptions{},
+			BytesSource([]byte(configMapDataManifest(names["team-a"], "foo", "bar"))).WithFieldManager("team-a"),
+			BytesSource([]byte(configMapDataManifest(names["team-b"], "foo", "bar"))).WithFieldManager("team-b"),
+		)
+		require.NoError(t, err)
+
+		for manager, name := range names {
+			cm, err := c.Client().CoreV1().ConfigMaps("default").Get(ctx, name, metav1.GetOptions{})
+			require.NoError(t, err)
+
+			managers := []string{}
+			for _, entry := range cm.ManagedFields {
+				if entry.Operation == metav1.ManagedFieldsOperationApply {
+					managers = append(managers, entry.Manager)
+				}
+			}
+			assert.Equal(t, []string{manager}, managers, name)
+		}
+	})
+}
@@ -0,0 +1,134 @@
+package kubectl
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var (
+	// The subresources that objects can be applied to
+	applySubresources = []string{"status", "scale"}
+)
+
+/*
+ApplyStatus applies the status of the objects in the given files to their status subresource, like ApplyManifests
+with the Subresource option set to status. The objects must exist, and only their status is changed.
+
+Example:
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	err := ApplyStatus(ctx, "/path/to/kubeconfig", &ApplyManifestsOptions{}, "/path/to/status.yaml")
+	if err != nil {
+		// Handle error
+	}
+*/
+func ApplyStatus(ctx context.Context, kubeconfigPath string, opts *ApplyManifestsOptions, filePaths ...string) error {
+	if opts == nil {
+		return fmt.Errorf("options cannot be nil")
+	}
+
+	statusOpts := *opts
+	statusOpts.Subresource = "status"
+
+	return ApplyManifests(ctx, kubeconfigPath, &statusOpts, filePaths...)
+}
+
+/*
+applySubresource server-side applies the objects in the given files to the subresource of the objects. This is done through
+the dynamic client, as the kubectl apply command of this kubectl version cannot apply to subresources.
+*/
+func applySubresource(ctx context.Context, kubeconfigPath string, opts *ApplyManifestsOptions, filePaths ...string) (*ApplyResult, error) {
+	if err := operations.begin(); err != nil {
+		return nil, err
+	}
+	defer operations.end()
+
+	if kubeconfigPath == "" {
+		return nil, fmt.Errorf("kubeconfig path cannot be empty")
+	}
+
+	if len(filePaths) == 0 {
+		return nil, fmt.Errorf("no files to apply")
+	}
+
+	if !isApplySubresource(opts.Subresource) {
+		return nil, fmt.Errorf("unsupported subresource %s, must be one of %s", opts.Subresource, strings.Join(applySubresources, ", "))
+	}
+
+	if opts.Prune {
+		return nil, fmt.Errorf("pruning is not supported when applying to a subresource")
+	}
+
+	// The objects are applied through the dynamic client, which only dry-runs on the API server
+	if opts.DryRun == DryRunClient {
+		return nil, fmt.Errorf("client-side dry-run is not supported when applying to a subresource")
+	}
+
+	objs, err := readManifests(filePaths, opts.Recursive)
+	if err != nil {
+		return nil, err
+	}
+
+	f := newFactory(kubeconfigPath)
+
+	dynamicClient, err := f.DynamicClient()
+	if err != nil {
+		return nil, fmt.Errorf("could not create dynamic client: %w", err)
+	}
+
+	mapper, err := f.ToRESTMapper()
+	if err != nil {
+		return nil, fmt.Errorf("could not create rest mapper: %w", err)
+	}
+
+	namespace, _, err := f.ToRawKubeConfigLoader().Namespace()
+	if err != nil {
+		return nil, fmt.Errorf("could not determine default namespace: %w", err)
+	}
+
+	applyOpts := metav1.ApplyOptions{
+		FieldManager: fieldManagerOf(opts),
+		Force:        opts.ConflictPolicy == ConflictPolicyForce,
+	}
+	if opts.DryRun != DryRunNone {
+		applyOpts.DryRun = []string{metav1.DryRunAll}
+	}
+
+	result := &ApplyResult{}
+	for _, obj := range objs {
+		client, err := objectClient(dynamicClient, mapper, namespace, obj)
+		if err != nil {
+			return nil, err
+		}
+
+		_, err = client.Apply(ctx, obj.GetName(), obj, applyOpts, opts.Subresource)
+		if err != nil {
+			return nil, fmt.Errorf("could not apply %s of %s %s: %w", opts.Subresource, obj.GetKind(), obj.GetName(), err)
+		}
+
+		result.add([]ObjectResult{
+			{
+				ObjectRef: objectRefOf(obj),
+				Operation: OperationServerSideApplied,
+				DryRun:    opts.DryRun != DryRunNone,
+			},
+		})
+	}
+
+	return result, nil
+}
+
+func isApplySubresource(subresource string) bool {
+	for _, s := range applySubresources {
+		if s == subresource {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,136 @@
+package kubectl
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Arneproductions/go-kube/internal/resources"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+const widgetCRDManifest = `
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: widgets.test.go-kube.io
+spec:
+  group: test.go-kube.io
+  scope: Namespaced
+  names:
+    plural: widgets
+    singular: widget
+    kind: Widget
+  versions:
+  - name: v1
+    served: true
+    storage: true
+    subresources:
+      status: {}
+    schema:
+      openAPIV3Schema:
+        type: object
+        properties:
+          spec:
+            type: object
+            properties:
+              size:
+                type: integer
+          status:
+            type: object
+            properties:
+              phase:
+                type: string
+`
+
+func widgetManifest(name string, size int, phase string) string {
+	manifest := fmt.Sprintf(`
+apiVersion: test.go-kube.io/v1
+kind: Widget
+metadata:
+  name: %s
+  namespace: default
+spec:
+  size: %d
+`, name, size)
+
+	if phase != "" {
+		manifest += fmt.Sprintf(`status:
+  phase: %s
+`, phase)
+	}
+
+	return manifest
+}
+
+func TestApplyStatus(t *testing.T) {
+	c := resources.NewEphemeralCluster()
+	require.NoError(t, c.Start())
+
+	t.Cleanup(func() {
+		require.NoError(t, c.Stop())
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	err := ApplyManifests(ctx, c.KubeConfigFilePath(), &ApplyManifestsOptions{}, writeTestManifest(t, widgetCRDManifest))
+	require.NoError(t, err)
+
+	// The widget kind is only served once the CRD is established
+	widget := writeTestManifest(t, widgetManifest("status-widget", 1, ""))
+	err = wait.PollUntilContextCancel(ctx, time.Second, true, func(ctx context.Context) (bool, error) {
+		return ApplyManifests(ctx, c.KubeConfigFilePath(), &ApplyManifestsOptions{}, widget) == nil, nil
+	})
+	require.NoError(t, err)
+
+	t.Run("ApplyStatus_should_only_change_the_status", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		status := writeTestManifest(t, widgetManifest("status-widget", 5, "Ready"))
+
+		err := ApplyStatus(ctx, c.KubeConfigFilePath(), &ApplyManifestsOptions{}, status)
+		require.NoError(t, err)
+
+		live, err := getTestWidget(ctx, t, c.KubeConfigFilePath(), "status-widget")
+		require.NoError(t, err)
+
+		phase, _, _ := unstructured.NestedString(live.Object, "status", "phase")
+		size, _, _ := unstructured.NestedInt64(live.Object, "spec", "size")
+
+		assert.Equal(t, "Ready", phase)
+		assert.Equal(t, int64(1), size)
+	})
+
+	t.Run("ApplyManifests_should_reject_unsupported_subresources", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		err := ApplyManifests(ctx, c.KubeConfigFilePath(), &ApplyManifestsOptions{Subresource: "logs"}, widget)
+		assert.Error(t, err)
+	})
+}
+
+func getTestWidget(ctx context.Context, t *testing.T, kubeconfigPath string, name string) (*unstructured.Unstructured, error) {
+	f := newFactory(kubeconfigPath)
+
+	dynamicClient, err := f.DynamicClient()
+	require.NoError(t, err)
+
+	mapper, err := f.ToRESTMapper()
+	require.NoError(t, err)
+
+	obj := mustDecodeManifests(t, widgetManifest(name, 0, ""))[0]
+
+	client, err := objectClient(dynamicClient, mapper, "default", obj)
+	require.NoError(t, err)
+
+	return client.Get(ctx, name, metav1.GetOptions{})
+}
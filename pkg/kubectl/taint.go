@@ -0,0 +1,156 @@
+package kubectl
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/kubectl/pkg/cmd/taint"
+)
+
+/*
+NodeTaint is a taint on a node i.e. the KEY=VALUE:EFFECT argument of kubectl taint
+*/
+type NodeTaint struct {
+	Key   string
+	Value string
+	/*
+		The effect of the taint, can be left empty when removing the taint to remove it for every effect
+	*/
+	Effect corev1.TaintEffect
+}
+
+/*
+Returns the taint in the key=value:Effect form that kubectl taint uses for adding taints
+*/
+func (t NodeTaint) String() string {
+	s := t.Key
+	if t.Value != "" {
+		s += "=" + t.Value
+	}
+
+	return s + ":" + string(t.Effect)
+}
+
+/*
+Returns the taint in the key:Effect- form that kubectl taint uses for removing taints
+*/
+func (t NodeTaint) removal() string {
+	if t.Effect == "" {
+		return t.Key + "-"
+	}
+
+	return t.Key + ":" + string(t.Effect) + "-"
+}
+
+/*
+Taint adds the taints to the node of the cluster that the kubeconfigPath points to i.e. kubectl taint node NAME KEY=VALUE:EFFECT.
+Existing taints with the same key and effect are overwritten.
+
+Example:
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	err := Taint(ctx, "/path/to/kubeconfig", "my-node", NodeTaint{
+		Key:    "dedicated",
+		Value:  "gpu",
+		Effect: corev1.TaintEffectNoSchedule,
+	})
+	if err != nil {
+		// Handle error
+	}
+*/
+func Taint(ctx context.Context, kubeconfigPath string, nodeName string, taints ...NodeTaint) error {
+	args := []string{}
+	for _, t := range taints {
+		if t.Key == "" || t.Effect == "" {
+			return fmt.Errorf("taint %q must have a key and an effect", t.String())
+		}
+
+		args = append(args, t.String())
+	}
+
+	return taintFunc(ctx, kubeconfigPath, nodeName, args)
+}
+
+/*
+Untaint removes the taints from the node of the cluster that the kubeconfigPath points to i.e. kubectl taint node NAME KEY:EFFECT-.
+Only the key of a taint is required, the taint is removed for every effect when the effect is left empty.
+
+Example:
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	err := Untaint(ctx, "/path/to/kubeconfig", "my-node", NodeTaint{Key: "dedicated"})
+	if err != nil {
+		// Handle error
+	}
+*/
+func Untaint(ctx context.Context, kubeconfigPath string, nodeName string, taints ...NodeTaint) error {
+	args := []string{}
+	for _, t := range taints {
+		if t.Key == "" {
+			return fmt.Errorf("taint to remove must have a key")
+		}
+
+		args = append(args, t.removal())
+	}
+
+	return taintFunc(ctx, kubeconfigPath, nodeName, args)
+}
+
+/*
+taintFunc runs kubectl taint on the node with the given taint arguments.
+*/
+func taintFunc(ctx context.Context, kubeconfigPath string, nodeName string, taintArgs []string) error {
+	// Like the apply command, the taint command may encounter a fatal error which
+	// changes global behaviour
+	lock, err := beginKubectl(ctx, "kubectl taint")
+	if err != nil {
+		return err
+	}
+	defer lock.unlock()
+
+	if kubeconfigPath == "" {
+		return fmt.Errorf("kubeconfig path cannot be empty")
+	}
+
+	if nodeName == "" {
+		return fmt.Errorf("node name cannot be empty")
+	}
+
+	if len(taintArgs) == 0 {
+		return fmt.Errorf("no taints given")
+	}
+
+	ioStreams, streamOut, streamErr := newIOStreams()
+
+	errChan := newErrChan()
+
+	// Send an error to the error channel when the context is cancelled or its deadline is reached
+	operation := fmt.Sprintf("kubectl taint of node %s", nodeName)
+	timeLeft, stopWatching := watchContext(ctx, operation, errChan, streamOut, streamErr)
+	defer stopWatching()
+
+	// The deadline may have passed while we waited for the kubectl lock
+	if err := expiredError(operation, timeLeft, streamOut, streamErr); err != nil {
+		return err
+	}
+
+	f := newCommandFactory(kubeconfigPath, "", timeLeft)
+
+	taintCmd := taint.NewCmdTaint(f, ioStreams)
+	taintCmd.Flags().Set("overwrite", "true")
+	taintCmd.Flags().Set("field-manager", FieldManager)
+
+	lock.run(errChan, streamOut, streamErr, func() error {
+		// taintCmd is blocking. Should it fail it should have called the fatal error handler which
+		// run overrides to send an error to errChan
+		taintCmd.Run(taintCmd, append([]string{"node", nodeName}, taintArgs...))
+		return nil
+	})
+
+	return <-errChan
+}
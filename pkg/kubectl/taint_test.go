@@ -0,0 +1,122 @@
+package kubectl
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Arneproductions/go-kube/internal/resources"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+func TestTaint(t *testing.T) {
+	c := resources.NewEphemeralCluster()
+	require.NoError(t, c.Start())
+
+	t.Cleanup(func() {
+		require.NoError(t, c.Stop())
+	})
+
+	t.Run("Taint_should_only_schedule_pods_with_matching_toleration", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+		defer cancel()
+
+		nodes, err := c.Client().CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+		require.NoError(t, err)
+		require.NotEmpty(t, nodes.Items)
+
+		nodeName := nodes.Items[0].Name
+		nodeTaint := NodeTaint{Key: "go-kube/test", Value: "taint", Effect: corev1.TaintEffectNoSchedule}
+
+		err = Taint(ctx, c.KubeConfigFilePath(), nodeName, nodeTaint)
+		require.NoError(t, err)
+
+		t.Cleanup(func() {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			defer cancel()
+
+			require.NoError(t, Untaint(ctx, c.KubeConfigFilePath(), nodeName, NodeTaint{Key: nodeTaint.Key}))
+		})
+
+		node, err := c.Client().CoreV1().Nodes().Get(ctx, nodeName, metav1.GetOptions{})
+		require.NoError(t, err)
+		assert.Contains(t, node.Spec.Taints, corev1.Taint{Key: nodeTaint.Key, Value: nodeTaint.Value, Effect: nodeTaint.Effect})
+
+		intolerant := fmt.Sprintf("test-pod-%s", uuid.New().String())
+		tolerant := fmt.Sprintf("test-pod-%s", uuid.New().String())
+
+		manifest := writeTestManifest(t, fmt.Sprintf(`
+apiVersion: v1
+kind: Pod
+metadata:
+  name: %[1]s
+  namespace: default
+spec:
+  containers:
+  - name: app
+    image: busybox:1.36
+    command: ["sleep", "3600"]
+---
+apiVersion: v1
+kind: Pod
+metadata:
+  name: %[2]s
+  namespace: default
+spec:
+  tolerations:
+  - key: %[3]s
+    operator: Equal
+    value: %[4]s
+    effect: %[5]s
+  containers:
+  - name: app
+    image: busybox:1.36
+    command: ["sleep", "3600"]
+`, intolerant, tolerant, nodeTaint.Key, nodeTaint.Value, nodeTaint.Effect))
+
+		err = ApplyManifests(ctx, c.KubeConfigFilePath(), &ApplyManifestsOptions{}, manifest)
+		require.NoError(t, err)
+
+		t.Cleanup(func() {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			defer cancel()
+
+			_ = c.Client().CoreV1().Pods("default").Delete(ctx, intolerant, metav1.DeleteOptions{})
+			_ = c.Client().CoreV1().Pods("default").Delete(ctx, tolerant, metav1.DeleteOptions{})
+		})
+
+		err = wait.PollUntilContextCancel(ctx, time.Second, true, func(ctx context.Context) (bool, error) {
+			pod, err := c.Client().CoreV1().Pods("default").Get(ctx, tolerant, metav1.GetOptions{})
+			if err != nil {
+				return false, err
+			}
+
+			return pod.Spec.NodeName != "", nil
+		})
+		require.NoError(t, err)
+
+		pod, err := c.Client().CoreV1().Pods("default").Get(ctx, intolerant, metav1.GetOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, corev1.PodPending, pod.Status.Phase)
+		assert.Empty(t, pod.Spec.NodeName)
+	})
+}
+
+func TestNodeTaint(t *testing.T) {
+	t.Run("NodeTaint_should_format_as_kubectl_arguments", func(t *testing.T) {
+		withValue := NodeTaint{Key: "dedicated", Value: "gpu", Effect: corev1.TaintEffectNoSchedule}
+		withoutValue := NodeTaint{Key: "dedicated", Effect: corev1.TaintEffectNoExecute}
+
+		assert.Equal(t, "dedicated=gpu:NoSchedule", withValue.String())
+		assert.Equal(t, "dedicated:NoExecute", withoutValue.String())
+		assert.Equal(t, "dedicated:NoSchedule-", withValue.removal())
+		assert.Equal(t, "dedicated-", NodeTaint{Key: "dedicated"}.removal())
+	})
+}
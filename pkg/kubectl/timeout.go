@@ -0,0 +1,148 @@
+package kubectl
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+)
+
+/*
+syncBuffer is a buffer that can be read while a kubectl command is still writing to it, e.g. when the command times out.
+*/
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buf.String()
+}
+
+/*
+newIOStreams returns the streams to run a kubectl command with, and the buffers capturing its output and error streams.
+*/
+func newIOStreams() (genericiooptions.IOStreams, *syncBuffer, *syncBuffer) {
+	streamOut := &syncBuffer{}
+	streamErr := &syncBuffer{}
+
+	return genericiooptions.IOStreams{In: &bytes.Buffer{}, Out: streamOut, ErrOut: streamErr}, streamOut, streamErr
+}
+
+/*
+deadlineError describes the kubectl command that did not finish in time, and what it wrote before then. The error wraps
+context.DeadlineExceeded, also when the deadline is the fallback of a context without one.
+*/
+func deadlineError(operation string, timeLeft time.Duration, hasDeadline bool, streamOut, streamErr *syncBuffer) error {
+	deadline := "the context deadline"
+	if !hasDeadline {
+		deadline = "the default deadline, as the context has none"
+	}
+
+	return fmt.Errorf(
+		"%s did not finish within %s, %s\nout stream: %s\nerror stream: %s\n: %w",
+		operation,
+		timeLeft.Round(time.Millisecond),
+		deadline,
+		streamOut.String(),
+		streamErr.String(),
+		context.DeadlineExceeded,
+	)
+}
+
+/*
+cancelledError describes the kubectl command whose context was cancelled before it finished, and what it wrote before
+then. The error wraps the error of the context.
+*/
+func cancelledError(operation string, ctxErr error, streamOut, streamErr *syncBuffer) error {
+	return fmt.Errorf(
+		"%s was cancelled before it finished\nout stream: %s\nerror stream: %s\n: %w",
+		operation,
+		streamOut.String(),
+		streamErr.String(),
+		ctxErr,
+	)
+}
+
+/*
+expiredError returns the deadline error of the operation when no time is left for it, such that a command is not
+started after its deadline, and nil otherwise.
+*/
+func expiredError(operation string, timeLeft time.Duration, streamOut, streamErr *syncBuffer) error {
+	if timeLeft > 0 {
+		return nil
+	}
+
+	// Only the deadline of a context can have passed, as the default deadline is always ahead
+	return deadlineError(operation, timeLeft, true, streamOut, streamErr)
+}
+
+/*
+watchContext sends an error to errChan when the context is cancelled, or when its deadline is reached. Contexts without
+a deadline get an arbitrary deadline of 15 seconds. It returns the time left until the deadline, and a function that
+stops watching, which has to be called once the command is done such that neither the timer nor the watcher outlive it.
+*/
+func watchContext(ctx context.Context, operation string, errChan chan<- error, streamOut, streamErr *syncBuffer) (time.Duration, func()) {
+	// We find out if the context have a deadline, from there we derive amount of time left
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(15 * time.Second) // This deadline is arbitary
+	}
+	timeLeft := time.Until(deadline)
+
+	timer := time.NewTimer(timeLeft)
+	done := make(chan struct{})
+
+	go func() {
+		var err error
+
+		select {
+		case <-timer.C:
+			err = deadlineError(operation, timeLeft, ok, streamOut, streamErr)
+		case <-ctx.Done():
+			err = cancelledError(operation, ctx.Err(), streamOut, streamErr)
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				err = deadlineError(operation, timeLeft, ok, streamOut, streamErr)
+			}
+		case <-done:
+			return
+		}
+
+		// The command may have finished while the context was done as well, in which case there is nothing to report
+		select {
+		case <-done:
+			return
+		default:
+		}
+
+		// The command may finish while we report the error, in which case nobody is receiving anymore
+		select {
+		case errChan <- err:
+		case <-done:
+		}
+	}()
+
+	var once sync.Once
+	stop := func() {
+		once.Do(func() {
+			timer.Stop()
+			close(done)
+		})
+	}
+
+	return timeLeft, stop
+}
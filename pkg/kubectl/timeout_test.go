@@ -0,0 +1,262 @@
+package kubectl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Arneproductions/go-kube/internal/resources"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeadlineError(t *testing.T) {
+	t.Run("deadlineError_should_describe_the_operation_and_wrap_DeadlineExceeded", func(t *testing.T) {
+		_, streamOut, streamErr := newIOStreams()
+		fmt.Fprint(streamOut, "configmap/foo created")
+
+		err := deadlineError("kubectl apply of 2 files", 15*time.Second, false, streamOut, streamErr)
+
+		assert.True(t, errors.Is(err, context.DeadlineExceeded))
+		assert.Contains(t, err.Error(), "kubectl apply of 2 files did not finish within 15s")
+		assert.Contains(t, err.Error(), "the default deadline")
+		assert.Contains(t, err.Error(), "configmap/foo created")
+	})
+}
+
+func TestExpiredError(t *testing.T) {
+	t.Run("expiredError_should_report_a_deadline_without_time_left", func(t *testing.T) {
+		for _, timeLeft := range []time.Duration{0, -time.Second} {
+			_, streamOut, streamErr := newIOStreams()
+
+			err := expiredError("kubectl apply of 2 files", timeLeft, streamOut, streamErr)
+			assert.True(t, errors.Is(err, context.DeadlineExceeded), timeLeft.String())
+		}
+	})
+
+	t.Run("expiredError_should_accept_time_left", func(t *testing.T) {
+		_, streamOut, streamErr := newIOStreams()
+
+		assert.NoError(t, expiredError("kubectl apply of 2 files", time.Millisecond, streamOut, streamErr))
+	})
+}
+
+func TestWithRequestTimeout(t *testing.T) {
+	t.Run("withRequestTimeout_should_never_disable_the_timeout", func(t *testing.T) {
+		for timeLeft, want := range map[time.Duration]string{
+			-time.Second:            "1s",
+			0:                       "1s",
+			time.Millisecond:        "1s",
+			1500 * time.Millisecond: "2s",
+			time.Minute:             "60s",
+		} {
+			config := withRequestTimeout(newConfigFlags("/path/to/kubeconfig", ""), timeLeft)
+			assert.Equal(t, want, *config.Timeout, timeLeft.String())
+		}
+	})
+}
+
+func TestWatchContext(t *testing.T) {
+	t.Run("watchContext_should_report_cancelled_contexts", func(t *testing.T) {
+		_, streamOut, streamErr := newIOStreams()
+		errChan := make(chan error)
+
+		ctx, cancel := context.WithCancel(context.Background())
+
+		timeLeft, stop := watchContext(ctx, "kubectl apply of 1 files", errChan, streamOut, streamErr)
+		defer stop()
+
+		assert.InDelta(t, 15*time.Second, timeLeft, float64(time.Second), "contexts without a deadline get the default deadline")
+
+		cancel()
+
+		select {
+		case err := <-errChan:
+			assert.True(t, errors.Is(err, context.Canceled))
+			assert.Contains(t, err.Error(), "kubectl apply of 1 files was cancelled")
+		case <-time.After(5 * time.Second):
+			t.Fatal("the cancellation was not reported")
+		}
+	})
+
+	t.Run("watchContext_should_report_the_deadline", func(t *testing.T) {
+		_, streamOut, streamErr := newIOStreams()
+		errChan := make(chan error)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		_, stop := watchContext(ctx, "kubectl delete of 1 files", errChan, streamOut, streamErr)
+		defer stop()
+
+		select {
+		case err := <-errChan:
+			assert.True(t, errors.Is(err, context.DeadlineExceeded))
+			assert.Contains(t, err.Error(), "the context deadline")
+		case <-time.After(5 * time.Second):
+			t.Fatal("the deadline was not reported")
+		}
+	})
+
+	t.Run("watchContext_should_not_report_after_it_is_stopped", func(t *testing.T) {
+		_, streamOut, streamErr := newIOStreams()
+		errChan := make(chan error)
+
+		ctx, cancel := context.WithCancel(context.Background())
+
+		_, stop := watchContext(ctx, "kubectl apply of 1 files", errChan, streamOut, streamErr)
+		stop()
+		stop()
+		cancel()
+
+		select {
+		case err := <-errChan:
+			t.Fatalf("unexpected error after stopping: %v", err)
+		case <-time.After(100 * time.Millisecond):
+		}
+	})
+}
+
+func TestApplyDeadline(t *testing.T) {
+	c := resources.NewEphemeralCluster()
+	require.NoError(t, c.Start())
+
+	t.Cleanup(func() {
+		require.NoError(t, c.Stop())
+	})
+
+	t.Run("ApplyManifests_should_wrap_the_deadline_error", func(t *testing.T) {
+		name := fmt.Sprintf("test-cm-%s", uuid.New().String())
+		manifest := writeTestManifest(t, configMapDataManifest(name, "foo", "bar"))
+
+		// The deadline is reached long before kubectl is done
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+		defer cancel()
+
+		err := ApplyManifests(ctx, c.KubeConfigFilePath(), &ApplyManifestsOptions{}, manifest)
+		require.Error(t, err)
+
+		assert.True(t, errors.Is(err, context.DeadlineExceeded))
+		assert.Contains(t, err.Error(), "kubectl apply of 1 files did not finish")
+		assert.Contains(t, err.Error(), "the context deadline")
+	})
+}
+
+func TestApplyCancellation(t *testing.T) {
+	c := resources.NewEphemeralCluster()
+	require.NoError(t, c.Start())
+
+	t.Cleanup(func() {
+		require.NoError(t, c.Stop())
+	})
+
+	t.Run("ApplyManifests_should_return_when_the_context_is_cancelled", func(t *testing.T) {
+		name := fmt.Sprintf("test-cm-%s", uuid.New().String())
+		manifest := writeTestManifest(t, configMapDataManifest(name, "foo", "bar"))
+
+		// The context has no deadline, so only the cancellation stops the apply before the default deadline
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		start := time.Now()
+		err := ApplyManifests(ctx, c.KubeConfigFilePath(), &ApplyManifestsOptions{}, manifest)
+		require.Error(t, err)
+
+		assert.True(t, errors.Is(err, context.Canceled))
+		assert.Less(t, time.Since(start), 15*time.Second)
+	})
+}
+
+func TestApplyTimeoutGoroutines(t *testing.T) {
+	// timedOutApply applies against a server that does not answer before the deadline, and answers with an error
+	// afterwards, such that the apply command finishes after ApplyManifests returned
+	timedOutApply := func(t *testing.T) {
+		release := make(chan struct{})
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			<-release
+
+			w.Header().Set("Connection", "close")
+			http.Error(w, "not available", http.StatusInternalServerError)
+		}))
+		defer server.Close()
+		defer close(release)
+
+		kubeconfig := strings.ReplaceAll(unreachableKubeConfig, "https://127.0.0.1:1", server.URL)
+		kubeconfigPath := filepath.Join(t.TempDir(), "kubeconfig")
+		require.NoError(t, os.WriteFile(kubeconfigPath, []byte(kubeconfig), 0o600))
+
+		manifest := writeTestManifest(t, configMapDataManifest("test-cm", "foo", "bar"))
+
+		// The deadline is reached before the request timeout of kubectl, which is rounded up to a second
+		ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+		defer cancel()
+
+		err := ApplyManifests(ctx, kubeconfigPath, &ApplyManifestsOptions{}, manifest)
+		require.Error(t, err)
+		assert.True(t, errors.Is(err, context.DeadlineExceeded), err.Error())
+	}
+
+	t.Run("ApplyManifests_should_not_leak_the_command_goroutine_when_it_times_out", func(t *testing.T) {
+		// The first apply starts the goroutines that live as long as the process, e.g. those of klog
+		timedOutApply(t)
+
+		before := runtime.NumGoroutine()
+
+		timedOutApply(t)
+
+		assert.Eventually(t, func() bool {
+			return runtime.NumGoroutine() <= before
+		}, 10*time.Second, 100*time.Millisecond, "the goroutines of the timed out apply did not finish")
+	})
+}
+
+func TestTimedOutCommands(t *testing.T) {
+	t.Run("a_timed_out_delete_should_neither_keep_the_next_apply_waiting_nor_hold_the_lock", func(t *testing.T) {
+		// The server never answers, such that only the timeouts of the commands end their requests
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			<-r.Context().Done()
+		}))
+		defer server.Close()
+
+		kubeconfig := strings.ReplaceAll(unreachableKubeConfig, "https://127.0.0.1:1", server.URL)
+		kubeconfigPath := filepath.Join(t.TempDir(), "kubeconfig")
+		require.NoError(t, os.WriteFile(kubeconfigPath, []byte(kubeconfig), 0o600))
+
+		manifest := writeTestManifest(t, configMapDataManifest("test-cm", "foo", "bar"))
+
+		deleteCtx, cancelDelete := context.WithTimeout(context.Background(), 500*time.Millisecond)
+		defer cancelDelete()
+
+		err := DeleteManifests(deleteCtx, kubeconfigPath, manifest)
+		require.Error(t, err)
+		assert.True(t, errors.Is(err, context.DeadlineExceeded), err.Error())
+
+		// The apply gives up at its own deadline, whether the delete still holds the lock or not
+		applyCtx, cancelApply := context.WithTimeout(context.Background(), 500*time.Millisecond)
+		defer cancelApply()
+
+		start := time.Now()
+		err = ApplyManifests(applyCtx, kubeconfigPath, &ApplyManifestsOptions{}, manifest)
+		require.Error(t, err)
+		assert.True(t, errors.Is(err, context.DeadlineExceeded), err.Error())
+		assert.Less(t, time.Since(start), 2*time.Second)
+
+		// Both commands give up on the server once their request timeouts are reached, and release the lock
+		lockCtx, cancelLock := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancelLock()
+
+		lock, err := lockKubectl(lockCtx, "kubectl apply")
+		require.NoError(t, err)
+		lock.unlock()
+	})
+}
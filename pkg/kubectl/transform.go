@@ -0,0 +1,263 @@
+package kubectl
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+/*
+Transform changes an object of a manifest before it is applied, returning an error when the object cannot be changed
+*/
+type Transform func(obj *unstructured.Unstructured) error
+
+const (
+	// The label of a namespace that sets the Pod Security Standard that pods in the namespace must meet
+	podSecurityEnforceLabel = "pod-security.kubernetes.io/enforce"
+)
+
+var (
+	// The levels of the Pod Security Standards
+	podSecurityLevels = sets.New("privileged", "baseline", "restricted")
+
+	// The paths to the pod spec of the kinds that run pods
+	podSpecPaths = map[string][]string{
+		"Pod":         {"spec"},
+		"Deployment":  {"spec", "template", "spec"},
+		"StatefulSet": {"spec", "template", "spec"},
+		"DaemonSet":   {"spec", "template", "spec"},
+		"ReplicaSet":  {"spec", "template", "spec"},
+		"Job":         {"spec", "template", "spec"},
+		"CronJob":     {"spec", "jobTemplate", "spec", "template", "spec"},
+	}
+)
+
+/*
+transformManifests runs every transform on every object of the given files, and writes the transformed objects to a
+temporary manifest file, which is returned instead of the files. The returned cleanup function removes the temporary file.
+*/
+func transformManifests(transforms []Transform, recursive bool, filePaths ...string) ([]string, func(), error) {
+	cleanup := func() {}
+
+	if len(transforms) == 0 {
+		return filePaths, cleanup, nil
+	}
+
+	objs, err := readManifests(filePaths, recursive)
+	if err != nil {
+		return nil, cleanup, err
+	}
+
+	for _, obj := range objs {
+		for _, transform := range transforms {
+			err := transform(obj)
+			if err != nil {
+				return nil, cleanup, fmt.Errorf("could not transform %s %s: %w", obj.GetKind(), obj.GetName(), err)
+			}
+		}
+	}
+
+	manifestPath, err := writeManifests(objs)
+	if err != nil {
+		return nil, cleanup, err
+	}
+
+	return []string{manifestPath}, func() { os.Remove(manifestPath) }, nil
+}
+
+/*
+applyTransforms returns the transforms of the options, followed by the transforms that other options are backed by.
+*/
+func applyTransforms(ctx context.Context, opts *ApplyManifestsOptions) ([]Transform, error) {
+	transforms := append([]Transform{}, opts.Transforms...)
+
+	if opts.DefaultResources != nil {
+		transform, err := defaultResources(*opts.DefaultResources)
+		if err != nil {
+			return nil, err
+		}
+
+		transforms = append(transforms, transform)
+	}
+
+	if len(opts.OmitFields) > 0 {
+		transforms = append(transforms, omitFields(opts.OmitFields))
+	}
+
+	if opts.PodSecurityLevel != "" {
+		transform, err := podSecurityLevel(opts.PodSecurityLevel)
+		if err != nil {
+			return nil, err
+		}
+
+		transforms = append(transforms, transform)
+	}
+
+	if opts.PinImages {
+		transforms = append(transforms, pinImages(ctx))
+	}
+
+	return transforms, nil
+}
+
+/*
+podSecurityLevel returns a Transform that sets the label of the Pod Security Standard to enforce on namespaces, that
+do not set the label themselves.
+*/
+func podSecurityLevel(level string) (Transform, error) {
+	if !podSecurityLevels.Has(level) {
+		return nil, fmt.Errorf("invalid pod security level %s, must be one of %s", level, strings.Join(sets.List(podSecurityLevels), ", "))
+	}
+
+	return func(obj *unstructured.Unstructured) error {
+		if obj.GetKind() != "Namespace" {
+			return nil
+		}
+
+		labels := obj.GetLabels()
+		if _, ok := labels[podSecurityEnforceLabel]; ok {
+			return nil
+		}
+
+		if labels == nil {
+			labels = map[string]string{}
+		}
+		labels[podSecurityEnforceLabel] = level
+		obj.SetLabels(labels)
+
+		return nil
+	}, nil
+}
+
+/*
+podSecurityLabels returns the labels of the namespaces that enforce the Pod Security Standard, none when the level is empty.
+*/
+func podSecurityLabels(level string) map[string]string {
+	if level == "" {
+		return nil
+	}
+
+	return map[string]string{podSecurityEnforceLabel: level}
+}
+
+/*
+omitFields returns a Transform that removes the fields at the dot-separated paths from the objects, e.g. spec.replicas.
+*/
+func omitFields(paths []string) Transform {
+	return func(obj *unstructured.Unstructured) error {
+		for _, path := range paths {
+			unstructured.RemoveNestedField(obj.Object, strings.Split(path, ".")...)
+		}
+
+		return nil
+	}
+}
+
+/*
+defaultResources returns a Transform that sets the requests and limits of every container of the objects that run pods,
+that the container does not set itself. A default request is skipped when it exceeds the limit that the container sets,
+and a default limit when it is below the request that the container sets, as the container would be invalid otherwise.
+*/
+func defaultResources(requirements corev1.ResourceRequirements) (Transform, error) {
+	defaults, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&requirements)
+	if err != nil {
+		return nil, fmt.Errorf("could not convert default resources: %w", err)
+	}
+
+	return func(obj *unstructured.Unstructured) error {
+		path, ok := podSpecPaths[obj.GetKind()]
+		if !ok {
+			return nil
+		}
+
+		for _, field := range []string{"initContainers", "containers"} {
+			containers, found, err := unstructured.NestedSlice(obj.Object, append(path, field)...)
+			if err != nil {
+				return err
+			}
+			if !found {
+				continue
+			}
+
+			for _, container := range containers {
+				containerMap, ok := container.(map[string]interface{})
+				if !ok {
+					continue
+				}
+
+				for _, kind := range []string{"requests", "limits"} {
+					defaultValues, _ := defaults[kind].(map[string]interface{})
+
+					for resourceName, value := range defaultValues {
+						_, set, err := unstructured.NestedFieldNoCopy(containerMap, "resources", kind, resourceName)
+						if err != nil {
+							return err
+						}
+						if set {
+							continue
+						}
+
+						fits, err := defaultResourceFits(containerMap, kind, resourceName, value)
+						if err != nil {
+							return fmt.Errorf("could not default %s %s: %w", kind, resourceName, err)
+						}
+						if !fits {
+							continue
+						}
+
+						err = unstructured.SetNestedField(containerMap, value, "resources", kind, resourceName)
+						if err != nil {
+							return err
+						}
+					}
+				}
+			}
+
+			err = unstructured.SetNestedSlice(obj.Object, containers, append(path, field)...)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}, nil
+}
+
+/*
+defaultResourceFits reports whether the default value of the requests or limits of a resource keeps the request of the
+container at or below its limit.
+*/
+func defaultResourceFits(container map[string]interface{}, kind, resourceName string, value interface{}) (bool, error) {
+	counterpart := "limits"
+	if kind == "limits" {
+		counterpart = "requests"
+	}
+
+	existing, set, err := unstructured.NestedFieldNoCopy(container, "resources", counterpart, resourceName)
+	if err != nil || !set {
+		return true, err
+	}
+
+	defaultQuantity, err := resource.ParseQuantity(fmt.Sprint(value))
+	if err != nil {
+		return false, err
+	}
+
+	existingQuantity, err := resource.ParseQuantity(fmt.Sprint(existing))
+	if err != nil {
+		return false, err
+	}
+
+	if kind == "requests" {
+		return defaultQuantity.Cmp(existingQuantity) <= 0, nil
+	}
+
+	return defaultQuantity.Cmp(existingQuantity) >= 0, nil
+}
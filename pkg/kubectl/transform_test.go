@@ -0,0 +1,299 @@
+package kubectl
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Arneproductions/go-kube/internal/resources"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+var testDefaultResources = corev1.ResourceRequirements{
+	Requests: corev1.ResourceList{
+		corev1.ResourceCPU:    resource.MustParse("10m"),
+		corev1.ResourceMemory: resource.MustParse("16Mi"),
+	},
+	Limits: corev1.ResourceList{
+		corev1.ResourceCPU:    resource.MustParse("100m"),
+		corev1.ResourceMemory: resource.MustParse("64Mi"),
+	},
+}
+
+func TestDefaultResources(t *testing.T) {
+	t.Run("defaultResources_should_only_set_missing_resources", func(t *testing.T) {
+		obj := mustDecodeManifests(t, loggingDeploymentManifest("foo", 1))[0]
+
+		containers, _, err := unstructured.NestedSlice(obj.Object, "spec", "template", "spec", "containers")
+		require.NoError(t, err)
+		require.NoError(t, unstructured.SetNestedField(containers[0].(map[string]interface{}), "32Mi", "resources", "limits", "memory"))
+		require.NoError(t, unstructured.SetNestedSlice(obj.Object, containers, "spec", "template", "spec", "containers"))
+
+		transform, err := defaultResources(testDefaultResources)
+		require.NoError(t, err)
+		require.NoError(t, transform(obj))
+
+		containers, _, err = unstructured.NestedSlice(obj.Object, "spec", "template", "spec", "containers")
+		require.NoError(t, err)
+
+		got := containers[0].(map[string]interface{})["resources"]
+		assert.Equal(t, map[string]interface{}{
+			"requests": map[string]interface{}{"cpu": "10m", "memory": "16Mi"},
+			"limits":   map[string]interface{}{"cpu": "100m", "memory": "32Mi"},
+		}, got)
+	})
+
+	t.Run("defaultResources_should_keep_requests_within_limits", func(t *testing.T) {
+		obj := mustDecodeManifests(t, loggingDeploymentManifest("foo", 1))[0]
+
+		containers, _, err := unstructured.NestedSlice(obj.Object, "spec", "template", "spec", "containers")
+		require.NoError(t, err)
+		require.NoError(t, unstructured.SetNestedField(containers[0].(map[string]interface{}), "5m", "resources", "limits", "cpu"))
+		require.NoError(t, unstructured.SetNestedField(containers[0].(map[string]interface{}), "128Mi", "resources", "requests", "memory"))
+		require.NoError(t, unstructured.SetNestedSlice(obj.Object, containers, "spec", "template", "spec", "containers"))
+
+		transform, err := defaultResources(testDefaultResources)
+		require.NoError(t, err)
+		require.NoError(t, transform(obj))
+
+		containers, _, err = unstructured.NestedSlice(obj.Object, "spec", "template", "spec", "containers")
+		require.NoError(t, err)
+
+		got := containers[0].(map[string]interface{})["resources"]
+		assert.Equal(t, map[string]interface{}{
+			"requests": map[string]interface{}{"memory": "128Mi"},
+			"limits":   map[string]interface{}{"cpu": "5m"},
+		}, got)
+	})
+
+	t.Run("defaultResources_should_set_requests_below_an_existing_limit", func(t *testing.T) {
+		obj := mustDecodeManifests(t, loggingDeploymentManifest("foo", 1))[0]
+
+		containers, _, err := unstructured.NestedSlice(obj.Object, "spec", "template", "spec", "containers")
+		require.NoError(t, err)
+		require.NoError(t, unstructured.SetNestedField(containers[0].(map[string]interface{}), "100m", "resources", "limits", "cpu"))
+		require.NoError(t, unstructured.SetNestedSlice(obj.Object, containers, "spec", "template", "spec", "containers"))
+
+		transform, err := defaultResources(testDefaultResources)
+		require.NoError(t, err)
+		require.NoError(t, transform(obj))
+
+		containers, _, err = unstructured.NestedSlice(obj.Object, "spec", "template", "spec", "containers")
+		require.NoError(t, err)
+
+		got := containers[0].(map[string]interface{})["resources"]
+		assert.Equal(t, map[string]interface{}{
+			"requests": map[string]interface{}{"cpu": "10m", "memory": "16Mi"},
+			"limits":   map[string]interface{}{"cpu": "100m", "memory": "64Mi"},
+		}, got)
+	})
+
+	t.Run("defaultResources_should_leave_objects_without_pods_alone", func(t *testing.T) {
+		obj := mustDecodeManifests(t, configMapDataManifest("foo", "foo", "bar"))[0]
+		before := obj.DeepCopy()
+
+		transform, err := defaultResources(testDefaultResources)
+		require.NoError(t, err)
+		require.NoError(t, transform(obj))
+
+		assert.Equal(t, before, obj)
+	})
+}
+
+func TestOmitFields(t *testing.T) {
+	t.Run("omitFields_should_remove_the_fields_that_are_set", func(t *testing.T) {
+		obj := mustDecodeManifests(t, loggingDeploymentManifest("foo", 3))[0]
+
+		require.NoError(t, omitFields([]string{"spec.replicas", "spec.paused"})(obj))
+
+		_, found, err := unstructured.NestedInt64(obj.Object, "spec", "replicas")
+		require.NoError(t, err)
+		assert.False(t, found)
+
+		_, found, err = unstructured.NestedMap(obj.Object, "spec", "selector")
+		require.NoError(t, err)
+		assert.True(t, found)
+	})
+}
+
+// privilegedPodManifest is a pod in the namespace that only the privileged Pod Security Standard admits
+func privilegedPodManifest(namespace, name string) string {
+	return fmt.Sprintf(`
+apiVersion: v1
+kind: Pod
+metadata:
+  name: %s
+  namespace: %s
+spec:
+  containers:
+  - name: pause
+    image: registry.k8s.io/pause:3.9
+    securityContext:
+      privileged: true
+`, name, namespace)
+}
+
+func TestPodSecurityLevel(t *testing.T) {
+	t.Run("podSecurityLevel_should_label_namespaces_without_a_level", func(t *testing.T) {
+		transform, err := podSecurityLevel("restricted")
+		require.NoError(t, err)
+
+		ns := mustDecodeManifests(t, namespaceManifest("foo"))[0]
+		require.NoError(t, transform(ns))
+		assert.Equal(t, "restricted", ns.GetLabels()[podSecurityEnforceLabel])
+
+		labelled := mustDecodeManifests(t, namespaceManifest("bar"))[0]
+		labelled.SetLabels(map[string]string{podSecurityEnforceLabel: "baseline"})
+		require.NoError(t, transform(labelled))
+		assert.Equal(t, "baseline", labelled.GetLabels()[podSecurityEnforceLabel])
+
+		cm := mustDecodeManifests(t, configMapDataManifest("foo", "foo", "bar"))[0]
+		require.NoError(t, transform(cm))
+		assert.Empty(t, cm.GetLabels())
+	})
+
+	t.Run("podSecurityLevel_should_reject_unknown_levels", func(t *testing.T) {
+		_, err := podSecurityLevel("strict")
+		assert.Error(t, err)
+	})
+}
+
+func TestTransforms(t *testing.T) {
+	c := resources.NewEphemeralCluster()
+	require.NoError(t, c.Start())
+
+	t.Cleanup(func() {
+		require.NoError(t, c.Stop())
+	})
+
+	t.Run("ApplyManifests_should_inject_DefaultResources", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		name := fmt.Sprintf("test-deploy-%s", uuid.New().String())
+		opts := &ApplyManifestsOptions{DefaultResources: &testDefaultResources}
+
+		err := ApplyManifests(ctx, c.KubeConfigFilePath(), opts, writeTestManifest(t, loggingDeploymentManifest(name, 1)))
+		require.NoError(t, err)
+
+		t.Cleanup(func() {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			defer cancel()
+
+			_ = c.Client().AppsV1().Deployments("default").Delete(ctx, name, metav1.DeleteOptions{})
+		})
+
+		deployment, err := c.Client().AppsV1().Deployments("default").Get(ctx, name, metav1.GetOptions{})
+		require.NoError(t, err)
+
+		limits := deployment.Spec.Template.Spec.Containers[0].Resources.Limits
+		assert.True(t, limits.Cpu().Equal(resource.MustParse("100m")))
+		assert.True(t, limits.Memory().Equal(resource.MustParse("64Mi")))
+	})
+
+	t.Run("ApplyManifests_should_run_Transforms_before_applying", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		name := fmt.Sprintf("test-cm-%s", uuid.New().String())
+		opts := &ApplyManifestsOptions{
+			Transforms: []Transform{
+				func(obj *unstructured.Unstructured) error {
+					obj.SetLabels(map[string]string{"transformed": "true"})
+					return nil
+				},
+			},
+		}
+
+		err := ApplyManifests(ctx, c.KubeConfigFilePath(), opts, writeTestManifest(t, configMapDataManifest(name, "foo", "bar")))
+		require.NoError(t, err)
+
+		t.Cleanup(func() {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			defer cancel()
+
+			_ = c.Client().CoreV1().ConfigMaps("default").Delete(ctx, name, metav1.DeleteOptions{})
+		})
+
+		cm, err := c.Client().CoreV1().ConfigMaps("default").Get(ctx, name, metav1.GetOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, "true", cm.Labels["transformed"])
+	})
+
+	t.Run("ApplyManifests_should_leave_OmitFields_to_other_managers", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		name := fmt.Sprintf("test-deploy-%s", uuid.New().String())
+		manifest := writeTestManifest(t, loggingDeploymentManifest(name, 1))
+		opts := &ApplyManifestsOptions{ServerSide: true, OmitFields: []string{"spec.replicas"}}
+
+		err := ApplyManifests(ctx, c.KubeConfigFilePath(), opts, manifest)
+		require.NoError(t, err)
+
+		t.Cleanup(func() {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			defer cancel()
+
+			_ = c.Client().AppsV1().Deployments("default").Delete(ctx, name, metav1.DeleteOptions{})
+		})
+
+		// Another manager, e.g. a HorizontalPodAutoscaler, scales the deployment
+		patch := fmt.Sprintf(`{"apiVersion":"apps/v1","kind":"Deployment","metadata":{"name":"%s","namespace":"default"},"spec":{"replicas":3}}`, name)
+		_, err = c.Client().AppsV1().Deployments("default").Patch(
+			ctx,
+			name,
+			types.ApplyPatchType,
+			[]byte(patch),
+			metav1.PatchOptions{FieldManager: "test-autoscaler"},
+		)
+		require.NoError(t, err)
+
+		err = ApplyManifests(ctx, c.KubeConfigFilePath(), opts, manifest)
+		require.NoError(t, err)
+
+		deployment, err := c.Client().AppsV1().Deployments("default").Get(ctx, name, metav1.GetOptions{})
+		require.NoError(t, err)
+		require.NotNil(t, deployment.Spec.Replicas)
+		assert.Equal(t, int32(3), *deployment.Spec.Replicas)
+	})
+
+	t.Run("ApplyManifests_should_enforce_the_PodSecurityLevel", func(t *testing.T) {
+		for level, admitted := range map[string]bool{"restricted": false, "privileged": true} {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			defer cancel()
+
+			namespace := fmt.Sprintf("test-psa-%s", uuid.New().String())
+			manifest := namespaceManifest(namespace) + "\n---\n" + privilegedPodManifest(namespace, "privileged")
+
+			err := ApplyManifests(ctx, c.KubeConfigFilePath(), &ApplyManifestsOptions{PodSecurityLevel: level}, writeTestManifest(t, manifest))
+
+			t.Cleanup(func() {
+				ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+				defer cancel()
+
+				_ = c.Client().CoreV1().Namespaces().Delete(ctx, namespace, metav1.DeleteOptions{})
+			})
+
+			if admitted {
+				assert.NoError(t, err, level)
+			} else {
+				require.Error(t, err, level)
+				assert.Contains(t, err.Error(), "violates PodSecurity", level)
+			}
+
+			ns, err := c.Client().CoreV1().Namespaces().Get(ctx, namespace, metav1.GetOptions{})
+			require.NoError(t, err)
+			assert.Equal(t, level, ns.Labels[podSecurityEnforceLabel])
+		}
+	})
+}
@@ -18,3 +18,102 @@ func (d DryRunType) String() string {
 // We implement the unexported interface to make sure that the DryRunType
 // cannot be extended/changed outside the package
 func (d DryRunType) unexported() {}
+
+// ConflictPolicy decides how conflicts with other field managers are resolved during a server-side apply
+type ConflictPolicy uint8
+
+const (
+	// ConflictPolicyFail fails the apply when another field manager owns a field that is changed
+	ConflictPolicyFail ConflictPolicy = iota
+	// ConflictPolicyForce takes ownership of the conflicting fields i.e. kubectl apply --force-conflicts
+	ConflictPolicyForce
+	// ConflictPolicyRetryMerge retries the apply without the fields that are owned by other field managers
+	ConflictPolicyRetryMerge
+)
+
+func (c ConflictPolicy) String() string {
+	return [...]string{"fail", "force", "retry-merge"}[c]
+}
+
+// We implement the unexported interface to make sure that the ConflictPolicy
+// cannot be extended/changed outside the package
+func (c ConflictPolicy) unexported() {}
+
+// PatchType is the type of a patch i.e. kubectl patch --type
+type PatchType uint8
+
+const (
+	// PatchTypeStrategic is a strategic merge patch, which is the default of kubectl patch
+	PatchTypeStrategic PatchType = iota
+	// PatchTypeMerge is a JSON merge patch as described in RFC 7386
+	PatchTypeMerge
+	// PatchTypeJSON is a JSON patch as described in RFC 6902
+	PatchTypeJSON
+)
+
+func (p PatchType) String() string {
+	return [...]string{"strategic", "merge", "json"}[p]
+}
+
+// We implement the unexported interface to make sure that the PatchType
+// cannot be extended/changed outside the package
+func (p PatchType) unexported() {}
+
+// DuplicateStrategy decides what happens when several manifests define the same object
+type DuplicateStrategy uint8
+
+const (
+	// DuplicateStrategyError fails the apply before anything is applied
+	DuplicateStrategyError DuplicateStrategy = iota
+	// DuplicateStrategyLastWins applies the last definition of the object
+	DuplicateStrategyLastWins
+	// DuplicateStrategyMerge merges the definitions of the object, where later definitions override earlier fields
+	DuplicateStrategyMerge
+)
+
+func (d DuplicateStrategy) String() string {
+	return [...]string{"error", "last-wins", "merge"}[d]
+}
+
+// We implement the unexported interface to make sure that the DuplicateStrategy
+// cannot be extended/changed outside the package
+func (d DuplicateStrategy) unexported() {}
+
+// OversizePolicy decides what happens to objects too large to be applied client-side
+type OversizePolicy uint8
+
+const (
+	// OversizePolicyError fails the apply before anything is applied
+	OversizePolicyError OversizePolicy = iota
+	// OversizePolicyServerSide applies the oversized objects server-side, and the other objects as usual
+	OversizePolicyServerSide
+)
+
+func (o OversizePolicy) String() string {
+	return [...]string{"error", "server-side"}[o]
+}
+
+// We implement the unexported interface to make sure that the OversizePolicy
+// cannot be extended/changed outside the package
+func (o OversizePolicy) unexported() {}
+
+// LoadRestrictor decides which files a kustomization may load i.e. kustomize build --load-restrictor
+type LoadRestrictor uint8
+
+const (
+	// LoadRestrictorRootOnly only allows files in or below the directory of the kustomization, and remote bases
+	LoadRestrictorRootOnly LoadRestrictor = iota
+	// LoadRestrictorNone allows files anywhere, e.g. patches shared by the kustomizations of a repository
+	LoadRestrictorNone
+)
+
+func (l LoadRestrictor) String() string {
+	return [...]string{"root-only", "none"}[l]
+}
+
+// We implement the unexported interface to make sure that the LoadRestrictor
+// cannot be extended/changed outside the package
+func (l LoadRestrictor) unexported() {}
+
+// FieldManager is the name of the field manager that resources are applied with
+const FieldManager = "go-kube"
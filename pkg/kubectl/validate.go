@@ -0,0 +1,39 @@
+package kubectl
+
+import (
+	"errors"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+/*
+Validator checks an object of a manifest before it is applied, returning an error when the object is not valid
+*/
+type Validator func(obj *unstructured.Unstructured) error
+
+/*
+validateManifests runs every validator on every object of the given files, and returns the errors of all of them.
+*/
+func validateManifests(validators []Validator, recursive bool, filePaths ...string) error {
+	objs, err := readManifests(filePaths, recursive)
+	if err != nil {
+		return err
+	}
+
+	errs := []error{}
+	for _, obj := range objs {
+		for _, validate := range validators {
+			err := validate(obj)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s %s: %w", obj.GetKind(), obj.GetName(), err))
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("manifests failed validation: %w", errors.Join(errs...))
+	}
+
+	return nil
+}
@@ -0,0 +1,59 @@
+package kubectl
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func requireTeamLabel(obj *unstructured.Unstructured) error {
+	if obj.GetLabels()["team"] == "" {
+		return fmt.Errorf("missing team label")
+	}
+
+	return nil
+}
+
+func TestValidators(t *testing.T) {
+	t.Run("ApplyManifests_should_reject_invalid_manifests_before_applying", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		manifest := writeTestManifest(t, labelledConfigMapManifest("no-team", "validators"))
+
+		// The kubeconfig does not exist, so the validation must fail before the cluster is contacted
+		err := ApplyManifests(ctx, "/does/not/exist/kubeconfig", &ApplyManifestsOptions{
+			Validators: []Validator{requireTeamLabel},
+		}, manifest)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "ConfigMap no-team: missing team label")
+	})
+
+	t.Run("validateManifests_should_report_every_failing_object", func(t *testing.T) {
+		manifest := writeTestManifest(t, labelledConfigMapManifest("first", "validators")+"---"+labelledConfigMapManifest("second", "validators"))
+
+		err := validateManifests([]Validator{requireTeamLabel}, false, manifest)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "ConfigMap first")
+		assert.Contains(t, err.Error(), "ConfigMap second")
+	})
+
+	t.Run("validateManifests_should_pass_valid_manifests", func(t *testing.T) {
+		manifest := writeTestManifest(t, `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: with-team
+  labels:
+    team: platform
+`)
+
+		assert.NoError(t, validateManifests([]Validator{requireTeamLabel}, false, manifest))
+	})
+}
@@ -0,0 +1,161 @@
+package kubectl
+
+import (
+	"context"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/rest"
+	"k8s.io/kubectl/pkg/cmd/util"
+)
+
+/*
+ValidateKustomization builds the kustomization in dir, and validates the objects it renders against the schema of the
+cluster that the kubeconfigPath points to with a server-side dry-run apply, without changing the cluster. Unknown and
+duplicate fields are errors, like kubectl apply --validate=strict.
+
+The problems found are returned, i.e. the validation errors of the objects, e.g. unknown fields or kinds that the
+cluster does not serve, and the warnings of the API server, e.g. about deprecated API versions. The error is only set
+when the kustomization could not be validated, e.g. when it does not build. Objects in namespaces that the
+kustomization creates cannot be validated before the namespaces exist, and are skipped, as are objects of the kinds
+that the CRDs of the kustomization define.
+
+Example:
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	problems, err := ValidateKustomization(ctx, "/path/to/kubeconfig", "/path/to/kustomization")
+	if err != nil {
+		// Handle error
+	}
+
+	for _, problem := range problems {
+		fmt.Println(problem)
+	}
+*/
+func ValidateKustomization(ctx context.Context, kubeconfigPath string, dir string) ([]string, error) {
+	if err := operations.begin(); err != nil {
+		return nil, err
+	}
+	defer operations.end()
+
+	if kubeconfigPath == "" {
+		return nil, fmt.Errorf("kubeconfig path cannot be empty")
+	}
+
+	if dir == "" {
+		return nil, fmt.Errorf("kustomization directory cannot be empty")
+	}
+
+	objs, err := buildKustomization(ctx, LoadRestrictorRootOnly, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	warnings := &warningCollector{}
+
+	config := newConfigFlags(kubeconfigPath, "")
+	config.WrapConfigFn = func(c *rest.Config) *rest.Config {
+		c.WarningHandler = warnings
+		return c
+	}
+
+	f := util.NewFactory(config)
+
+	dynamicClient, err := f.DynamicClient()
+	if err != nil {
+		return nil, fmt.Errorf("could not create dynamic client: %w", err)
+	}
+
+	mapper, err := f.ToRESTMapper()
+	if err != nil {
+		return nil, fmt.Errorf("could not create rest mapper: %w", err)
+	}
+
+	namespace, _, err := f.ToRawKubeConfigLoader().Namespace()
+	if err != nil {
+		return nil, fmt.Errorf("could not determine default namespace: %w", err)
+	}
+
+	// The namespaces that the kustomization creates, whose objects cannot be validated before they exist
+	created := map[string]bool{}
+	for _, obj := range objs {
+		if obj.GroupVersionKind().GroupKind().String() == "Namespace" {
+			created[obj.GetName()] = true
+		}
+	}
+
+	// The kinds that the kustomization defines, which the cluster cannot serve before the CRDs are applied
+	defined := definedKinds(objs)
+
+	// The apply is forced, such that fields owned by other managers are not reported as conflicts. The apply options
+	// of the dynamic client have no field validation, so the apply is sent as a patch
+	force := true
+	patchOpts := metav1.PatchOptions{
+		FieldManager:    FieldManager,
+		Force:           &force,
+		DryRun:          []string{metav1.DryRunAll},
+		FieldValidation: metav1.FieldValidationStrict,
+	}
+
+	problems := []string{}
+
+	for _, obj := range objs {
+		ref := objectRefOf(obj)
+
+		client, err := objectClient(dynamicClient, mapper, namespace, obj)
+		if meta.IsNoMatchError(err) && defined.Has(obj.GroupVersionKind().GroupKind()) {
+			continue
+		}
+		if meta.IsNoMatchError(err) {
+			problems = append(problems, fmt.Sprintf("%s/%s: %s", ref.Kind, ref.Name, err))
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		data, err := obj.MarshalJSON()
+		if err != nil {
+			return nil, fmt.Errorf("could not encode %s %s: %w", obj.GetKind(), obj.GetName(), err)
+		}
+
+		_, err = client.Patch(ctx, obj.GetName(), types.ApplyPatchType, data, patchOpts)
+		switch {
+		case err == nil:
+		case apierrors.IsNotFound(err) && created[obj.GetNamespace()]:
+			// The namespace of the object does not exist until the kustomization is applied
+		case apierrors.IsInvalid(err) || apierrors.IsBadRequest(err):
+			problems = append(problems, fmt.Sprintf("%s/%s: %s", ref.Kind, ref.Name, err))
+		default:
+			return nil, fmt.Errorf("could not dry-run apply %s %s: %w", obj.GetKind(), obj.GetName(), err)
+		}
+	}
+
+	return append(problems, warnings.Warnings()...), nil
+}
+
+/*
+definedKinds returns the kinds that the CRDs among the objects define.
+*/
+func definedKinds(objs []*unstructured.Unstructured) sets.Set[schema.GroupKind] {
+	kinds := sets.New[schema.GroupKind]()
+	for _, obj := range objs {
+		if obj.GroupVersionKind().GroupKind() != (schema.GroupKind{Group: "apiextensions.k8s.io", Kind: "CustomResourceDefinition"}) {
+			continue
+		}
+
+		group, _, _ := unstructured.NestedString(obj.Object, "spec", "group")
+		kind, _, _ := unstructured.NestedString(obj.Object, "spec", "names", "kind")
+		kinds.Insert(schema.GroupKind{Group: group, Kind: kind})
+	}
+
+	return kinds
+}
@@ -0,0 +1,107 @@
+package kubectl
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Arneproductions/go-kube/internal/resources"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// writeKustomizationOf writes a kustomization of the manifest to a temporary directory
+func writeKustomizationOf(t *testing.T, manifest string) string {
+	dir := t.TempDir()
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "kustomization.yaml"), []byte("resources:\n- manifest.yaml\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "manifest.yaml"), []byte(manifest), 0644))
+
+	return dir
+}
+
+func TestValidateKustomizationArguments(t *testing.T) {
+	t.Run("ValidateKustomization_should_reject_empty_arguments", func(t *testing.T) {
+		_, err := ValidateKustomization(context.Background(), "", "/path/to/kustomization")
+		assert.Error(t, err)
+
+		_, err = ValidateKustomization(context.Background(), "/path/to/kubeconfig", "")
+		assert.Error(t, err)
+	})
+
+	t.Run("ValidateKustomization_should_fail_when_the_kustomization_does_not_build", func(t *testing.T) {
+		_, err := ValidateKustomization(context.Background(), "/path/to/kubeconfig", t.TempDir())
+		assert.Error(t, err)
+	})
+}
+
+func TestDefinedKinds(t *testing.T) {
+	t.Run("definedKinds_should_return_the_kinds_of_the_CRDs", func(t *testing.T) {
+		objs := mustDecodeManifests(t, widgetCRDManifest+"---"+widgetManifest("foo", 1, ""))
+
+		assert.Equal(t, []schema.GroupKind{{Group: "test.go-kube.io", Kind: "Widget"}}, definedKinds(objs).UnsortedList())
+	})
+}
+
+func TestValidateKustomization(t *testing.T) {
+	c := resources.NewEphemeralCluster()
+	require.NoError(t, c.Start())
+
+	t.Cleanup(func() {
+		require.NoError(t, c.Stop())
+	})
+
+	t.Run("ValidateKustomization_should_report_unknown_fields_without_applying", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		name := fmt.Sprintf("test-cm-%s", uuid.New().String())
+		dir := writeKustomizationOf(t, configMapDataManifest(name, "foo", "bar")+"notAField: true\n")
+
+		problems, err := ValidateKustomization(ctx, c.KubeConfigFilePath(), dir)
+		require.NoError(t, err)
+
+		require.Len(t, problems, 1)
+		assert.Contains(t, problems[0], name)
+		assert.Contains(t, problems[0], "notAField")
+
+		_, err = c.Client().CoreV1().ConfigMaps("default").Get(ctx, name, metav1.GetOptions{})
+		assert.True(t, apierrors.IsNotFound(err))
+	})
+
+	t.Run("ValidateKustomization_should_report_nothing_for_valid_kustomizations", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		ns := fmt.Sprintf("test-ns-%s", uuid.New().String())
+		name := fmt.Sprintf("test-cm-%s", uuid.New().String())
+
+		// The deployment is in a namespace that the kustomization creates
+		manifest := namespaceManifest(ns) + "---" + configMapDataManifest(name, "foo", "bar") + "---" + namespacedDeploymentManifest(ns, "nginx", 1)
+
+		problems, err := ValidateKustomization(ctx, c.KubeConfigFilePath(), writeKustomizationOf(t, manifest))
+		require.NoError(t, err)
+		assert.Empty(t, problems)
+
+		_, err = c.Client().CoreV1().ConfigMaps("default").Get(ctx, name, metav1.GetOptions{})
+		assert.True(t, apierrors.IsNotFound(err))
+	})
+
+	t.Run("ValidateKustomization_should_skip_objects_of_the_CRDs_it_defines", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		manifest := widgetCRDManifest + "---" + widgetManifest(fmt.Sprintf("test-widget-%s", uuid.New().String()), 1, "")
+
+		problems, err := ValidateKustomization(ctx, c.KubeConfigFilePath(), writeKustomizationOf(t, manifest))
+		require.NoError(t, err)
+		assert.Empty(t, problems)
+	})
+}
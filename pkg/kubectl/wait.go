@@ -0,0 +1,123 @@
+package kubectl
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/kubectl/pkg/cmd/wait"
+)
+
+type WaitOptions struct {
+	/*
+		The condition to wait for i.e. kubectl wait --for, e.g. condition=Available, delete or
+		jsonpath='{.status.phase}'=Running
+	*/
+	For string
+	/*
+		The kind of the objects to wait for, e.g. deployment or deployment.apps
+	*/
+	Kind string
+	/*
+		The name of the object to wait for. Either the Name or the LabelSelector must be set
+	*/
+	Name string
+	/*
+		Label selector of the objects to wait for, e.g. app=nginx
+	*/
+	LabelSelector string
+	/*
+		The namespace of the objects, the namespace of the current context when not set
+	*/
+	Namespace string
+}
+
+/*
+Wait waits until the objects of the options meet the condition in the cluster that the kubeconfigPath points to i.e.
+kubectl wait --for=CONDITION KIND/NAME. The wait is bounded by the deadline of the context.
+
+Like every kubectl command, the wait holds the kubectl lock while it runs, so every other kubectl command, e.g. an apply
+or a delete, waits until the wait is done. Keep the deadline short, or wait with WaitForCondition or RolloutStatus,
+which poll the API without the lock.
+
+Example:
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	err := Wait(ctx, "/path/to/kubeconfig", &WaitOptions{
+		For:       "condition=Available",
+		Kind:      "deployment",
+		Name:      "nginx",
+		Namespace: "default",
+	})
+	if err != nil {
+		// Handle error
+	}
+*/
+func Wait(ctx context.Context, kubeconfigPath string, opts *WaitOptions) error {
+	// Like the apply command, the wait command may encounter a fatal error which
+	// changes global behaviour
+	lock, err := beginKubectl(ctx, "kubectl wait")
+	if err != nil {
+		return err
+	}
+	defer lock.unlock()
+
+	if kubeconfigPath == "" {
+		return fmt.Errorf("kubeconfig path cannot be empty")
+	}
+
+	if opts == nil {
+		return fmt.Errorf("options cannot be nil")
+	}
+
+	if opts.For == "" {
+		return fmt.Errorf("a condition to wait for is required")
+	}
+
+	if opts.Kind == "" {
+		return fmt.Errorf("a kind is required")
+	}
+
+	if opts.Name == "" && opts.LabelSelector == "" {
+		return fmt.Errorf("either a name or a label selector is required")
+	}
+
+	args := []string{opts.Kind}
+	if opts.Name != "" {
+		args = []string{opts.Kind + "/" + opts.Name}
+	}
+
+	ioStreams, streamOut, streamErr := newIOStreams()
+
+	errChan := newErrChan()
+
+	// Send an error to the error channel when the context is cancelled or its deadline is reached
+	operation := fmt.Sprintf("kubectl wait for %s of %s", opts.For, args[0])
+	timeLeft, stopWatching := watchContext(ctx, operation, errChan, streamOut, streamErr)
+	defer stopWatching()
+
+	// The deadline may have passed while we waited for the kubectl lock
+	if err := expiredError(operation, timeLeft, streamOut, streamErr); err != nil {
+		return err
+	}
+
+	f := newCommandFactory(kubeconfigPath, opts.Namespace, timeLeft)
+
+	waitCmd := wait.NewCmdWait(f, ioStreams)
+	waitCmd.Flags().Set("for", opts.For)
+	waitCmd.Flags().Set("timeout", timeLeft.String())
+
+	if opts.Name == "" {
+		waitCmd.Flags().Set("selector", opts.LabelSelector)
+	}
+
+	lock.run(errChan, streamOut, streamErr, func() error {
+		// waitCmd is blocking. Should it fail it should have called the fatal error handler which
+		// run overrides to send an error to errChan
+		waitCmd.Run(waitCmd, args)
+		return nil
+	})
+
+	return <-errChan
+}
@@ -0,0 +1,88 @@
+package kubectl
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Arneproductions/go-kube/internal/resources"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestWaitOptions(t *testing.T) {
+	t.Run("Wait_should_reject_incomplete_options", func(t *testing.T) {
+		for _, opts := range []*WaitOptions{
+			nil,
+			{Kind: "deployment", Name: "nginx"},
+			{For: "condition=Available", Name: "nginx"},
+			{For: "condition=Available", Kind: "deployment"},
+		} {
+			assert.Error(t, Wait(context.Background(), "/path/to/kubeconfig", opts))
+		}
+	})
+}
+
+func TestWait(t *testing.T) {
+	c := resources.NewEphemeralCluster()
+	require.NoError(t, c.Start())
+
+	t.Cleanup(func() {
+		require.NoError(t, c.Stop())
+	})
+
+	t.Run("Wait_should_return_once_the_deployment_is_available", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Minute)
+		defer cancel()
+
+		name := fmt.Sprintf("test-deploy-%s", uuid.New().String())
+
+		err := ApplyManifests(ctx, c.KubeConfigFilePath(), &ApplyManifestsOptions{}, writeTestManifest(t, loggingDeploymentManifest(name, 1)))
+		require.NoError(t, err)
+
+		err = Wait(ctx, c.KubeConfigFilePath(), &WaitOptions{For: "condition=Available", Kind: "deployment", Name: name, Namespace: "default"})
+		require.NoError(t, err)
+
+		deploy, err := c.Client().AppsV1().Deployments("default").Get(ctx, name, metav1.GetOptions{})
+		require.NoError(t, err)
+
+		available := false
+		for _, condition := range deploy.Status.Conditions {
+			if condition.Type == appsv1.DeploymentAvailable {
+				available = condition.Status == corev1.ConditionTrue
+			}
+		}
+		assert.True(t, available)
+	})
+
+	t.Run("Wait_should_wait_for_the_objects_of_the_label_selector", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Minute)
+		defer cancel()
+
+		name := fmt.Sprintf("test-deploy-%s", uuid.New().String())
+
+		err := ApplyManifests(ctx, c.KubeConfigFilePath(), &ApplyManifestsOptions{}, writeTestManifest(t, loggingDeploymentManifest(name, 2)))
+		require.NoError(t, err)
+
+		err = Wait(ctx, c.KubeConfigFilePath(), &WaitOptions{For: "condition=Ready", Kind: "pod", LabelSelector: "app=" + name, Namespace: "default"})
+		require.NoError(t, err)
+	})
+
+	t.Run("Wait_should_fail_when_the_condition_is_not_met_in_time", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		name := fmt.Sprintf("test-cm-%s", uuid.New().String())
+
+		err := ApplyManifests(ctx, c.KubeConfigFilePath(), &ApplyManifestsOptions{}, writeTestManifest(t, configMapDataManifest(name, "foo", "bar")))
+		require.NoError(t, err)
+
+		err = Wait(ctx, c.KubeConfigFilePath(), &WaitOptions{For: "delete", Kind: "configmap", Name: name, Namespace: "default"})
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,61 @@
+package kubectl
+
+import (
+	"sync"
+
+	"k8s.io/client-go/rest"
+)
+
+/*
+warningCollector is a client-go warning handler that collects the warnings the API server sends, such as the
+warnings about deprecated API versions. Every warning is only collected once. When the collector has a handler, the
+warnings are passed to the handler instead.
+*/
+type warningCollector struct {
+	mu       sync.Mutex
+	seen     map[string]bool
+	warnings []string
+	handler  func(warning string)
+}
+
+var _ rest.WarningHandler = &warningCollector{}
+
+func (w *warningCollector) HandleWarningHeader(code int, agent string, text string) {
+	// Only warnings with the 299 code are meant to be shown, the same way kubectl does it
+	if code != 299 || len(text) == 0 {
+		return
+	}
+
+	w.mu.Lock()
+
+	if w.seen == nil {
+		w.seen = map[string]bool{}
+	}
+
+	if w.seen[text] {
+		w.mu.Unlock()
+		return
+	}
+
+	w.seen[text] = true
+	if w.handler == nil {
+		w.warnings = append(w.warnings, text)
+	}
+
+	w.mu.Unlock()
+
+	// The handler is called without holding the lock, such that a slow handler does not hold up other requests
+	if w.handler != nil {
+		w.handler(text)
+	}
+}
+
+/*
+Warnings returns the collected warnings in the order they were received.
+*/
+func (w *warningCollector) Warnings() []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return append([]string{}, w.warnings...)
+}
@@ -0,0 +1,150 @@
+package kubectl
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Arneproductions/go-kube/internal/resources"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// gadgetCRDManifest defines a kind whose only version is deprecated, such that the API server warns on every request
+const gadgetCRDManifest = `
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: gadgets.test.go-kube.io
+spec:
+  group: test.go-kube.io
+  scope: Namespaced
+  names:
+    plural: gadgets
+    singular: gadget
+    kind: Gadget
+  versions:
+  - name: v1beta1
+    served: true
+    storage: true
+    deprecated: true
+    deprecationWarning: test.go-kube.io/v1beta1 Gadget is deprecated
+    schema:
+      openAPIV3Schema:
+        type: object
+        x-kubernetes-preserve-unknown-fields: true
+`
+
+func gadgetManifest(name string) string {
+	return fmt.Sprintf(`
+apiVersion: test.go-kube.io/v1beta1
+kind: Gadget
+metadata:
+  name: %s
+  namespace: default
+`, name)
+}
+
+func TestApplyWarnings(t *testing.T) {
+	c := resources.NewEphemeralCluster()
+	require.NoError(t, c.Start())
+
+	t.Cleanup(func() {
+		require.NoError(t, c.Stop())
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	err := ApplyManifests(ctx, c.KubeConfigFilePath(), &ApplyManifestsOptions{}, writeTestManifest(t, gadgetCRDManifest))
+	require.NoError(t, err)
+
+	// The gadget kind is only served once the CRD is established
+	err = wait.PollUntilContextCancel(ctx, time.Second, true, func(ctx context.Context) (bool, error) {
+		return ApplyManifests(ctx, c.KubeConfigFilePath(), &ApplyManifestsOptions{}, writeTestManifest(t, gadgetManifest("established"))) == nil, nil
+	})
+	require.NoError(t, err)
+
+	t.Run("ApplyManifestsWithResult_should_capture_deprecation_warnings", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		manifest := writeTestManifest(t, gadgetManifest(fmt.Sprintf("gadget-%s", uuid.New().String())))
+
+		result, err := ApplyManifestsWithResult(ctx, c.KubeConfigFilePath(), &ApplyManifestsOptions{}, manifest)
+		require.NoError(t, err)
+
+		assert.Contains(t, result.Warnings, "test.go-kube.io/v1beta1 Gadget is deprecated")
+	})
+
+	t.Run("ApplyManifestsWithResult_should_pass_warnings_to_the_WarningHandler", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		manifest := writeTestManifest(t, gadgetManifest(fmt.Sprintf("gadget-%s", uuid.New().String())))
+
+		mu := sync.Mutex{}
+		received := []string{}
+		opts := &ApplyManifestsOptions{
+			WarningHandler: func(warning string) {
+				mu.Lock()
+				defer mu.Unlock()
+
+				received = append(received, warning)
+			},
+		}
+
+		result, err := ApplyManifestsWithResult(ctx, c.KubeConfigFilePath(), opts, manifest)
+		require.NoError(t, err)
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		assert.Contains(t, received, "test.go-kube.io/v1beta1 Gadget is deprecated")
+		assert.Empty(t, result.Warnings)
+	})
+
+	t.Run("ApplyManifestsWithResult_should_suppress_warnings", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		manifest := writeTestManifest(t, gadgetManifest(fmt.Sprintf("gadget-%s", uuid.New().String())))
+
+		result, err := ApplyManifestsWithResult(ctx, c.KubeConfigFilePath(), &ApplyManifestsOptions{SuppressWarnings: true}, manifest)
+		require.NoError(t, err)
+
+		assert.Empty(t, result.Warnings)
+	})
+}
+
+func TestWarningCollector(t *testing.T) {
+	t.Run("warningCollector_should_collect_each_warning_once", func(t *testing.T) {
+		w := &warningCollector{}
+
+		w.HandleWarningHeader(299, "", "first")
+		w.HandleWarningHeader(299, "", "second")
+		w.HandleWarningHeader(299, "", "first")
+		w.HandleWarningHeader(199, "", "ignored")
+
+		assert.Equal(t, []string{"first", "second"}, w.Warnings())
+	})
+
+	t.Run("warningCollector_should_pass_each_warning_once_to_the_handler", func(t *testing.T) {
+		received := []string{}
+		w := &warningCollector{handler: func(warning string) {
+			received = append(received, warning)
+		}}
+
+		w.HandleWarningHeader(299, "", "first")
+		w.HandleWarningHeader(299, "", "first")
+		w.HandleWarningHeader(199, "", "ignored")
+
+		assert.Equal(t, []string{"first"}, received)
+		assert.Empty(t, w.Warnings())
+	})
+}
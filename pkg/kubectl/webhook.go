@@ -0,0 +1,86 @@
+package kubectl
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var (
+	// The API server reports failing admission webhooks as: failed calling webhook "NAME": REASON
+	webhookErrorPattern = regexp.MustCompile(`failed calling webhook "([^"]+)": ([^\n]*)`)
+	// The reasons of webhooks that are still being registered, e.g. before their TLS bundle is injected or their
+	// pods are serving
+	transientWebhookPattern = regexp.MustCompile(`x509|connection refused|no endpoints available|service "[^"]+" not found`)
+	// How long to wait before applying again when a webhook is still being registered
+	webhookRetryInterval = 2 * time.Second
+)
+
+/*
+WebhookError is returned when an apply fails because an admission webhook could not be called, e.g. because
+the service backing it is down or it timed out. This is different from a webhook rejecting an object.
+*/
+type WebhookError struct {
+	// The name of the webhook that could not be called
+	Webhook string
+	// Why the webhook could not be called, as reported by the API server
+	Reason string
+	// Whether the webhook timed out
+	Timeout bool
+	/*
+		Whether the webhook is likely still being registered, i.e. its certificate is not trusted yet or its service
+		is not serving yet
+	*/
+	Transient bool
+
+	err error
+}
+
+func (e *WebhookError) Error() string {
+	return fmt.Sprintf("could not call webhook %s: %s: %v", e.Webhook, e.Reason, e.err)
+}
+
+func (e *WebhookError) Unwrap() error {
+	return e.err
+}
+
+/*
+asWebhookError returns a WebhookError wrapping the error, if the error is caused by a webhook that could not be
+called. Other errors are returned as they are.
+*/
+func asWebhookError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var webhookErr *WebhookError
+	if errors.As(err, &webhookErr) {
+		return err
+	}
+
+	match := webhookErrorPattern.FindStringSubmatch(err.Error())
+	if match == nil {
+		return err
+	}
+
+	reason := strings.TrimSpace(match[2])
+
+	return &WebhookError{
+		Webhook:   match[1],
+		Reason:    reason,
+		Timeout:   strings.Contains(reason, "deadline exceeded") || strings.Contains(reason, "timeout") || strings.Contains(reason, "Timeout"),
+		Transient: transientWebhookPattern.MatchString(reason),
+		err:       err,
+	}
+}
+
+/*
+isTransientWebhookError reports whether the error is caused by a webhook that is still being registered.
+*/
+func isTransientWebhookError(err error) bool {
+	var webhookErr *WebhookError
+
+	return errors.As(asWebhookError(err), &webhookErr) && webhookErr.Transient
+}
@@ -0,0 +1,140 @@
+package kubectl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Arneproductions/go-kube/internal/resources"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// brokenWebhookManifest is a webhook for the config maps labelled broken=true, backed by a service that does not exist
+const brokenWebhookManifest = `
+apiVersion: admissionregistration.k8s.io/v1
+kind: ValidatingWebhookConfiguration
+metadata:
+  name: broken-webhook
+webhooks:
+- name: broken.go-kube.io
+  clientConfig:
+    service:
+      name: does-not-exist
+      namespace: default
+      path: /validate
+  rules:
+  - apiGroups: [""]
+    apiVersions: ["v1"]
+    operations: ["CREATE", "UPDATE"]
+    resources: ["configmaps"]
+  objectSelector:
+    matchLabels:
+      broken: "true"
+  failurePolicy: Fail
+  sideEffects: None
+  admissionReviewVersions: ["v1"]
+`
+
+func TestWebhookError(t *testing.T) {
+	c := resources.NewEphemeralCluster()
+	require.NoError(t, c.Start())
+
+	t.Cleanup(func() {
+		require.NoError(t, c.Stop())
+	})
+
+	t.Run("ApplyManifests_should_return_webhook_error_for_broken_webhook", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		err := ApplyManifests(ctx, c.KubeConfigFilePath(), &ApplyManifestsOptions{}, writeTestManifest(t, brokenWebhookManifest))
+		require.NoError(t, err)
+
+		manifest := writeTestManifest(t, fmt.Sprintf(`
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: test-cm-%s
+  namespace: default
+  labels:
+    broken: "true"
+`, uuid.New().String()))
+
+		err = ApplyManifests(ctx, c.KubeConfigFilePath(), &ApplyManifestsOptions{}, manifest)
+		require.Error(t, err)
+
+		var webhookErr *WebhookError
+		require.True(t, errors.As(err, &webhookErr))
+		assert.Equal(t, "broken.go-kube.io", webhookErr.Webhook)
+		assert.False(t, webhookErr.Timeout)
+	})
+	t.Run("ApplyManifests_should_retry_until_the_webhook_is_registered", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+		defer cancel()
+
+		// The webhook is not serving yet, and is removed a few seconds into the apply as if it became ready
+		webhook := writeTestManifest(t, strings.NewReplacer("broken-webhook", "registering-webhook", "broken.go-kube.io", "registering.go-kube.io", "broken:", "registering:").Replace(brokenWebhookManifest))
+
+		err := ApplyManifests(ctx, c.KubeConfigFilePath(), &ApplyManifestsOptions{}, webhook)
+		require.NoError(t, err)
+
+		go func() {
+			time.Sleep(5 * time.Second)
+			_ = DeleteManifests(ctx, c.KubeConfigFilePath(), webhook)
+		}()
+
+		manifest := writeTestManifest(t, fmt.Sprintf(`
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: test-cm-%s
+  namespace: default
+  labels:
+    registering: "true"
+`, uuid.New().String()))
+
+		err = ApplyManifests(ctx, c.KubeConfigFilePath(), &ApplyManifestsOptions{RetryWebhookRegistration: true}, manifest)
+		assert.NoError(t, err)
+	})
+}
+
+func TestAsWebhookError(t *testing.T) {
+	t.Run("asWebhookError_should_detect_webhook_timeouts", func(t *testing.T) {
+		err := asWebhookError(fmt.Errorf(`fatal error: Internal error occurred: failed calling webhook "slow.go-kube.io": failed to call webhook: Post "https://10.255.255.1:443/validate?timeout=10s": context deadline exceeded`))
+
+		var webhookErr *WebhookError
+		require.True(t, errors.As(err, &webhookErr))
+		assert.Equal(t, "slow.go-kube.io", webhookErr.Webhook)
+		assert.True(t, webhookErr.Timeout)
+	})
+
+	t.Run("asWebhookError_should_keep_other_errors", func(t *testing.T) {
+		original := fmt.Errorf("configmaps \"foo\" is forbidden")
+
+		assert.Equal(t, original, asWebhookError(original))
+	})
+	t.Run("asWebhookError_should_detect_webhooks_that_are_being_registered", func(t *testing.T) {
+		for _, reason := range []string{
+			`failed to call webhook: Post "https://webhook.default.svc:443/mutate?timeout=10s": x509: certificate signed by unknown authority`,
+			`failed to call webhook: Post "https://webhook.default.svc:443/mutate?timeout=10s": dial tcp 10.96.0.12:443: connect: connection refused`,
+			`failed to call webhook: Post "https://webhook.default.svc:443/mutate?timeout=10s": no endpoints available for service "webhook"`,
+			`failed to call webhook: Post "https://webhook.default.svc:443/mutate?timeout=10s": service "webhook" not found`,
+		} {
+			err := fmt.Errorf(`Internal error occurred: failed calling webhook "mutate.go-kube.io": %s`, reason)
+
+			assert.True(t, isTransientWebhookError(err), reason)
+		}
+	})
+
+	t.Run("asWebhookError_should_not_retry_other_webhook_errors", func(t *testing.T) {
+		err := asWebhookError(fmt.Errorf(`Internal error occurred: failed calling webhook "slow.go-kube.io": failed to call webhook: Post "https://10.255.255.1:443/validate?timeout=10s": context deadline exceeded`))
+
+		assert.False(t, isTransientWebhookError(err))
+		assert.False(t, isTransientWebhookError(fmt.Errorf(`admission webhook "deny.go-kube.io" denied the request`)))
+	})
+}
@@ -0,0 +1,207 @@
+package kubetest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Arneproductions/go-kube/pkg/kubectl"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+var (
+	// How long AssertConverged may take to apply and check the manifests, unless the test ends before then
+	convergeTimeout = 2 * time.Minute
+)
+
+/*
+AssertConverged asserts that the manifest files converge, i.e. that applying them leaves nothing to change on the next
+apply, like a GitOps controller syncing them over and over expects. The manifests are server-side applied, and then
+checked in two ways:
+
+  - a server-side dry-run apply of the manifests must not change the objects in the cluster
+  - every field that the manifests set must have the same value in the cluster, which fails for fields that the API
+    server normalizes or overrides, e.g. a cpu quantity of 1000m that is stored as 1
+
+The assertion fails with the diff of the objects that would change, and the fields that differ from their manifests.
+
+Example:
+
+	c := resources.NewEphemeralCluster()
+	require.NoError(t, c.Start())
+
+	kubetest.AssertConverged(t, c, "/path/to/manifest1.yaml", "/path/to/manifest2.yaml")
+*/
+func AssertConverged(t TestingT, cluster Cluster, files ...string) bool {
+	t.Helper()
+
+	timeout := convergeTimeout
+	if deadliner, ok := t.(interface{ Deadline() (time.Time, bool) }); ok {
+		if deadline, ok := deadliner.Deadline(); ok && time.Until(deadline) < timeout {
+			timeout = time.Until(deadline)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	kubeconfigPath := cluster.KubeConfigFilePath()
+
+	objs, err := manifestObjects(files...)
+	if err != nil {
+		t.Errorf("could not read manifests: %s", err)
+		return false
+	}
+
+	err = kubectl.ApplyManifests(ctx, kubeconfigPath, &kubectl.ApplyManifestsOptions{ServerSide: true}, files...)
+	if err != nil {
+		t.Errorf("could not apply %s: %s", strings.Join(files, ", "), err)
+		return false
+	}
+
+	failures := []string{}
+
+	_, err = kubectl.ApplyManifestsWithResult(ctx, kubeconfigPath, &kubectl.ApplyManifestsOptions{ServerSide: true, FailOnDrift: true}, files...)
+	driftErr := &kubectl.DriftError{}
+	switch {
+	case errors.As(err, &driftErr):
+		failures = append(failures, fmt.Sprintf("re-applying changes the objects:\n%s", driftErr.Diff))
+	case err != nil:
+		t.Errorf("could not dry-run apply %s: %s", strings.Join(files, ", "), err)
+		return false
+	}
+
+	for _, obj := range objs {
+		resourceType := strings.ToLower(obj.GetKind())
+		if group := obj.GroupVersionKind().Group; group != "" {
+			resourceType += "." + group
+		}
+
+		live, err := kubectl.Get(ctx, kubeconfigPath, &kubectl.GetOptions{
+			Namespace:  obj.GetNamespace(),
+			APIVersion: obj.GetAPIVersion(),
+		}, resourceType, obj.GetName())
+		if err != nil {
+			t.Errorf("could not get %s %s: %s", resourceType, obj.GetName(), err)
+			return false
+		}
+
+		// The status is not applied along with the object, so the manifests cannot converge on it
+		manifest := obj.DeepCopy()
+		unstructured.RemoveNestedField(manifest.Object, "status")
+
+		for _, mismatch := range fieldMismatches("", manifest.Object, live.Object) {
+			failures = append(failures, fmt.Sprintf("%s %s: %s", resourceType, describeObject(live), mismatch))
+		}
+	}
+
+	if len(failures) == 0 {
+		return true
+	}
+
+	t.Errorf("%s did not converge:\n%s", strings.Join(files, ", "), strings.Join(failures, "\n"))
+
+	return false
+}
+
+func describeObject(obj *unstructured.Unstructured) string {
+	if obj.GetNamespace() == "" {
+		return obj.GetName()
+	}
+
+	return obj.GetNamespace() + "/" + obj.GetName()
+}
+
+/*
+fieldMismatches compares the fields that the manifest sets to the fields of the live object, and describes the fields
+whose values differ. Fields that only the live object has, e.g. defaulted fields, are not compared. Lists are compared
+item by item, and differ when their lengths differ.
+*/
+func fieldMismatches(path string, manifest, live interface{}) []string {
+	switch want := manifest.(type) {
+	case map[string]interface{}:
+		got, ok := live.(map[string]interface{})
+		if !ok {
+			return []string{fmt.Sprintf("%s is %v in the manifest, but %v in the cluster", fieldPath(path), manifest, live)}
+		}
+
+		keys := []string{}
+		for key := range want {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		mismatches := []string{}
+		for _, key := range keys {
+			mismatches = append(mismatches, fieldMismatches(joinPath(path, key), want[key], got[key])...)
+		}
+
+		return mismatches
+
+	case []interface{}:
+		got, ok := live.([]interface{})
+		if !ok || len(got) != len(want) {
+			return []string{fmt.Sprintf("%s has %d items in the manifest, but %d in the cluster", fieldPath(path), len(want), len(got))}
+		}
+
+		mismatches := []string{}
+		for i := range want {
+			mismatches = append(mismatches, fieldMismatches(fmt.Sprintf("%s[%d]", path, i), want[i], got[i])...)
+		}
+
+		return mismatches
+
+	default:
+		if scalarEqual(manifest, live) {
+			return nil
+		}
+
+		return []string{fmt.Sprintf("%s is %v in the manifest, but %v in the cluster", fieldPath(path), manifest, live)}
+	}
+}
+
+/*
+scalarEqual reports whether the scalar values are equal, treating numbers as equal regardless of how they were decoded.
+*/
+func scalarEqual(a, b interface{}) bool {
+	af, aIsNumber := number(a)
+	bf, bIsNumber := number(b)
+	if aIsNumber && bIsNumber {
+		return af == bf
+	}
+
+	return reflect.DeepEqual(a, b)
+}
+
+func number(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case int64:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case float64:
+		return n, true
+	}
+
+	return 0, false
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+
+	return path + "." + key
+}
+
+func fieldPath(path string) string {
+	if path == "" {
+		return "the object"
+	}
+
+	return path
+}
@@ -0,0 +1,88 @@
+package kubetest
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/Arneproductions/go-kube/internal/resources"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// limitRangeManifest defines a ConfigMap, and a LimitRange whose default cpu limit is the quantity
+func limitRangeManifest(name, cpu string) string {
+	return fmt.Sprintf(`
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: %[1]s
+  namespace: default
+data:
+  foo: bar
+---
+apiVersion: v1
+kind: LimitRange
+metadata:
+  name: %[1]s
+  namespace: default
+spec:
+  limits:
+  - type: Container
+    default:
+      cpu: "%[2]s"
+`, name, cpu)
+}
+
+func TestFieldMismatches(t *testing.T) {
+	t.Run("fieldMismatches_should_ignore_fields_only_the_cluster_has", func(t *testing.T) {
+		manifest := map[string]interface{}{
+			"spec": map[string]interface{}{"replicas": int64(2), "ports": []interface{}{int64(80)}},
+		}
+		live := map[string]interface{}{
+			"spec": map[string]interface{}{"replicas": float64(2), "ports": []interface{}{int64(80)}, "paused": false},
+		}
+
+		assert.Empty(t, fieldMismatches("", manifest, live))
+	})
+
+	t.Run("fieldMismatches_should_describe_the_fields_that_differ", func(t *testing.T) {
+		manifest := map[string]interface{}{
+			"spec": map[string]interface{}{"cpu": "1000m", "ports": []interface{}{int64(80), int64(443)}},
+		}
+		live := map[string]interface{}{
+			"spec": map[string]interface{}{"cpu": "1", "ports": []interface{}{int64(80)}},
+		}
+
+		assert.Equal(t, []string{
+			"spec.cpu is 1000m in the manifest, but 1 in the cluster",
+			"spec.ports has 2 items in the manifest, but 1 in the cluster",
+		}, fieldMismatches("", manifest, live))
+	})
+}
+
+func TestAssertConverged(t *testing.T) {
+	c := resources.NewEphemeralCluster()
+	require.NoError(t, c.Start())
+
+	t.Cleanup(func() {
+		require.NoError(t, c.Stop())
+	})
+
+	t.Run("AssertConverged_should_pass_for_a_converging_manifest", func(t *testing.T) {
+		name := fmt.Sprintf("test-%s", uuid.New().String())
+
+		assert.True(t, AssertConverged(t, c, writeManifest(t, limitRangeManifest(name, "1"))))
+	})
+
+	t.Run("AssertConverged_should_fail_for_fields_the_server_normalizes", func(t *testing.T) {
+		name := fmt.Sprintf("test-%s", uuid.New().String())
+
+		recorder := &recordingT{}
+		assert.False(t, AssertConverged(recorder, c, writeManifest(t, limitRangeManifest(name, "1000m"))))
+
+		require.Len(t, recorder.errors, 1)
+		assert.Contains(t, recorder.errors[0], fmt.Sprintf("limitrange default/%s: spec.limits[0].default.cpu is 1000m in the manifest, but 1 in the cluster", name))
+		assert.NotContains(t, recorder.errors[0], "configmap")
+	})
+}
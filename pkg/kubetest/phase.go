@@ -0,0 +1,144 @@
+package kubetest
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Arneproductions/go-kube/pkg/kubectl"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+var (
+	// How long RequirePhase waits for the phase, unless the test ends before then
+	phaseTimeout = 2 * time.Minute
+	// How many of the most recent events of the object a failure includes
+	failureEvents = 10
+)
+
+// TestingT is the part of *testing.T that the assertions use
+type TestingT interface {
+	Helper()
+	Errorf(format string, args ...interface{})
+	FailNow()
+}
+
+// Cluster is a cluster that the assertions run against, e.g. an EphemeralCluster
+type Cluster interface {
+	KubeConfigFilePath() string
+}
+
+/*
+RequirePhase waits until the status.phase of the object in the namespace of the cluster is the phase, e.g. a Pod that
+is Running, and fails the test right away when it is not within two minutes or before the test deadline. The failure
+describes the last observed phase and the most recent events of the object.
+
+Example:
+
+	c := resources.NewEphemeralCluster()
+	require.NoError(t, c.Start())
+
+	kubetest.RequirePhase(t, c, schema.GroupVersionResource{Version: "v1", Resource: "pods"}, "default", "nginx", "Running")
+*/
+func RequirePhase(t TestingT, cluster Cluster, gvr schema.GroupVersionResource, namespace, name, phase string) {
+	t.Helper()
+
+	timeout := phaseTimeout
+	if deadliner, ok := t.(interface{ Deadline() (time.Time, bool) }); ok {
+		if deadline, ok := deadliner.Deadline(); ok && time.Until(deadline) < timeout {
+			timeout = time.Until(deadline)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	restConfig, err := clientcmd.BuildConfigFromFlags("", cluster.KubeConfigFilePath())
+	if err != nil {
+		t.Errorf("could not load kubeconfig %s: %s", cluster.KubeConfigFilePath(), err)
+		t.FailNow()
+		return
+	}
+
+	dynamicClient, err := dynamic.NewForConfig(restConfig)
+	if err != nil {
+		t.Errorf("could not create dynamic client: %s", err)
+		t.FailNow()
+		return
+	}
+
+	client := dynamicClient.Resource(gvr).Namespace(namespace)
+	lastPhase := ""
+	var lastErr error
+
+	err = wait.PollUntilContextCancel(ctx, time.Second, true, func(ctx context.Context) (bool, error) {
+		obj, err := client.Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			// The object may not have been created yet
+			lastErr = err
+			return false, nil
+		}
+		lastErr = nil
+
+		lastPhase, _, _ = unstructured.NestedString(obj.Object, "status", "phase")
+
+		return lastPhase == phase, nil
+	})
+	if err == nil {
+		return
+	}
+
+	observed := fmt.Sprintf("last observed phase %q", lastPhase)
+	if lastErr != nil {
+		observed = fmt.Sprintf("could not get it: %s", lastErr)
+	}
+
+	t.Errorf(
+		"%s %s/%s did not reach phase %s within %s, %s\nrecent events:\n%s",
+		gvr.Resource,
+		namespace,
+		name,
+		phase,
+		timeout.Round(time.Second),
+		observed,
+		recentEvents(cluster, namespace, name),
+	)
+	t.FailNow()
+}
+
+/*
+recentEvents describes the most recent events of the object, one per line.
+*/
+func recentEvents(cluster Cluster, namespace, name string) string {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	events, err := kubectl.NamespaceEvents(ctx, cluster.KubeConfigFilePath(), namespace, &kubectl.EventsOptions{})
+	if err != nil {
+		return fmt.Sprintf("  could not get events: %s", err)
+	}
+
+	lines := []string{}
+	for _, event := range events {
+		if event.Object.Name != name {
+			continue
+		}
+
+		lines = append(lines, fmt.Sprintf("  %s %s %s: %s", event.LastSeen.Format(time.RFC3339), event.Type, event.Reason, event.Message))
+		if len(lines) == failureEvents {
+			break
+		}
+	}
+
+	if len(lines) == 0 {
+		return "  none"
+	}
+
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,125 @@
+package kubetest
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/Arneproductions/go-kube/internal/resources"
+	"github.com/Arneproductions/go-kube/pkg/kubectl"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var pods = schema.GroupVersionResource{Version: "v1", Resource: "pods"}
+
+// recordingT records the failures of an assertion, instead of failing the test
+type recordingT struct {
+	errors []string
+	failed bool
+}
+
+func (r *recordingT) Helper() {}
+
+func (r *recordingT) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func (r *recordingT) FailNow() {
+	r.failed = true
+}
+
+func podManifest(name, nodeSelector string) string {
+	return fmt.Sprintf(`
+apiVersion: v1
+kind: Pod
+metadata:
+  name: %s
+  namespace: default
+spec:
+  nodeSelector:
+    %s
+  containers:
+  - name: pause
+    image: registry.k8s.io/pause:3.9
+`, name, nodeSelector)
+}
+
+func applyPod(ctx context.Context, t *testing.T, c *resources.EphemeralCluster, manifest string) {
+	tmpFile, err := os.CreateTemp("", "test-pod-*.yaml")
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		os.Remove(tmpFile.Name())
+	})
+
+	_, err = tmpFile.WriteString(manifest)
+	require.NoError(t, err)
+	require.NoError(t, tmpFile.Close())
+
+	err = kubectl.ApplyManifests(ctx, c.KubeConfigFilePath(), &kubectl.ApplyManifestsOptions{}, tmpFile.Name())
+	require.NoError(t, err)
+}
+
+func TestRequirePhase(t *testing.T) {
+	c := resources.NewEphemeralCluster()
+	require.NoError(t, c.Start())
+
+	t.Cleanup(func() {
+		require.NoError(t, c.Stop())
+	})
+
+	t.Run("RequirePhase_should_pass_when_the_pod_is_running", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		name := fmt.Sprintf("test-pod-%s", uuid.New().String())
+		applyPod(ctx, t, c, podManifest(name, "kubernetes.io/os: linux"))
+
+		t.Cleanup(func() {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			defer cancel()
+
+			_ = c.Client().CoreV1().Pods("default").Delete(ctx, name, metav1.DeleteOptions{})
+		})
+
+		RequirePhase(t, c, pods, "default", name, "Running")
+	})
+
+	t.Run("RequirePhase_should_fail_with_the_phase_and_events", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		name := fmt.Sprintf("test-pod-%s", uuid.New().String())
+
+		// No node has the label, so the pod is never scheduled
+		applyPod(ctx, t, c, podManifest(name, "test.go-kube.io/nonexistent: \"true\""))
+
+		t.Cleanup(func() {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			defer cancel()
+
+			_ = c.Client().CoreV1().Pods("default").Delete(ctx, name, metav1.DeleteOptions{})
+		})
+
+		timeout := phaseTimeout
+		phaseTimeout = 15 * time.Second
+		t.Cleanup(func() {
+			phaseTimeout = timeout
+		})
+
+		recorder := &recordingT{}
+		RequirePhase(recorder, c, pods, "default", name, "Running")
+
+		assert.True(t, recorder.failed)
+		require.Len(t, recorder.errors, 1)
+		assert.Contains(t, recorder.errors[0], `last observed phase "Pending"`)
+		assert.Contains(t, recorder.errors[0], "FailedScheduling")
+	})
+}
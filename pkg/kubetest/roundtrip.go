@@ -0,0 +1,226 @@
+package kubetest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Arneproductions/go-kube/pkg/kubectl"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+var (
+	// How long AssertRoundTrip waits for the deleted objects to be gone, unless the test ends before then
+	roundTripTimeout = 2 * time.Minute
+
+	namespaces = schema.GroupVersionResource{Version: "v1", Resource: "namespaces"}
+)
+
+/*
+AssertRoundTrip asserts that applying the manifest files to the cluster and deleting them again leaves the cluster as
+it was. The objects of the namespaces that the manifests use, and the namespaces of the cluster, are snapshotted before
+the apply and compared after the delete. The assertion fails with the objects that were left behind, e.g. objects that
+a Job of the manifests created, and the objects that existed before but were removed.
+
+The objects are applied with kubectl.ApplyManifests waiting for them to be ready, and deleted with
+kubectl.DeleteManifests. Objects created by a controller, e.g. the Pods of a Deployment, are not compared.
+
+Example:
+
+	c := resources.NewEphemeralCluster()
+	require.NoError(t, c.Start())
+
+	kubetest.AssertRoundTrip(t, c, "/path/to/manifest1.yaml", "/path/to/manifest2.yaml")
+*/
+func AssertRoundTrip(t TestingT, cluster Cluster, files ...string) bool {
+	t.Helper()
+
+	timeout := roundTripTimeout
+	if deadliner, ok := t.(interface{ Deadline() (time.Time, bool) }); ok {
+		if deadline, ok := deadliner.Deadline(); ok && time.Until(deadline) < timeout {
+			timeout = time.Until(deadline)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	kubeconfigPath := cluster.KubeConfigFilePath()
+
+	manifestNamespaces, err := namespacesOf(files...)
+	if err != nil {
+		t.Errorf("could not read manifests: %s", err)
+		return false
+	}
+
+	before, err := clusterState(ctx, kubeconfigPath, manifestNamespaces)
+	if err != nil {
+		t.Errorf("could not snapshot the cluster before the apply: %s", err)
+		return false
+	}
+
+	err = kubectl.ApplyManifests(ctx, kubeconfigPath, &kubectl.ApplyManifestsOptions{WaitForReady: true}, files...)
+	if err != nil {
+		t.Errorf("could not apply %s: %s", strings.Join(files, ", "), err)
+		return false
+	}
+
+	err = kubectl.DeleteManifests(ctx, kubeconfigPath, files...)
+	if err != nil {
+		t.Errorf("could not delete %s: %s", strings.Join(files, ", "), err)
+		return false
+	}
+
+	// Deleted objects may be terminating for a while, e.g. namespaces, so we wait for the state to settle
+	var after sets.Set[string]
+	err = wait.PollUntilContextCancel(ctx, time.Second, true, func(ctx context.Context) (bool, error) {
+		after, err = clusterState(ctx, kubeconfigPath, manifestNamespaces)
+		if err != nil {
+			return false, err
+		}
+
+		return after.Equal(before), nil
+	})
+	if after == nil {
+		t.Errorf("could not snapshot the cluster after the delete: %s", err)
+		return false
+	}
+	if err == nil {
+		return true
+	}
+
+	t.Errorf(
+		"the cluster differs from before the round trip of %s after %s\nleft behind:\n%s\nremoved:\n%s",
+		strings.Join(files, ", "),
+		timeout.Round(time.Second),
+		describeObjects(after.Difference(before)),
+		describeObjects(before.Difference(after)),
+	)
+
+	return false
+}
+
+/*
+namespacesOf returns the namespaces that the objects of the manifest files are in or define. Objects without a
+namespace are counted to the default namespace, as they are either created there or are cluster-scoped.
+*/
+func namespacesOf(files ...string) (sets.Set[string], error) {
+	objs, err := manifestObjects(files...)
+	if err != nil {
+		return nil, err
+	}
+
+	result := sets.New[string]()
+	for _, obj := range objs {
+		switch {
+		case obj.GetKind() == "Namespace":
+			result.Insert(obj.GetName())
+		case obj.GetNamespace() != "":
+			result.Insert(obj.GetNamespace())
+		default:
+			result.Insert(metav1.NamespaceDefault)
+		}
+	}
+
+	return result, nil
+}
+
+/*
+manifestObjects decodes the objects of the manifest files, skipping empty documents.
+*/
+func manifestObjects(files ...string) ([]*unstructured.Unstructured, error) {
+	objs := []*unstructured.Unstructured{}
+
+	for _, file := range files {
+		f, err := os.Open(file)
+		if err != nil {
+			return nil, err
+		}
+
+		decoder := utilyaml.NewYAMLOrJSONDecoder(f, 4096)
+		for {
+			obj := &unstructured.Unstructured{}
+			err = decoder.Decode(&obj.Object)
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				f.Close()
+				return nil, fmt.Errorf("could not decode %s: %w", file, err)
+			}
+
+			if len(obj.Object) == 0 {
+				continue
+			}
+
+			objs = append(objs, obj)
+		}
+
+		f.Close()
+	}
+
+	return objs, nil
+}
+
+/*
+clusterState returns the namespaces of the cluster, and the objects of the given namespaces as they are snapshotted
+by kubectl.SnapshotNamespace, e.g. "configmap default/foo".
+*/
+func clusterState(ctx context.Context, kubeconfigPath string, manifestNamespaces sets.Set[string]) (sets.Set[string], error) {
+	restConfig, err := clientcmd.BuildConfigFromFlags("", kubeconfigPath)
+	if err != nil {
+		return nil, fmt.Errorf("could not load kubeconfig %s: %w", kubeconfigPath, err)
+	}
+
+	dynamicClient, err := dynamic.NewForConfig(restConfig)
+	if err != nil {
+		return nil, fmt.Errorf("could not create dynamic client: %w", err)
+	}
+
+	list, err := dynamicClient.Resource(namespaces).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("could not list namespaces: %w", err)
+	}
+
+	state := sets.New[string]()
+	for _, ns := range list.Items {
+		state.Insert(fmt.Sprintf("namespace %s", ns.GetName()))
+	}
+
+	for _, ns := range sets.List(manifestNamespaces) {
+		snap, err := kubectl.SnapshotNamespace(ctx, kubeconfigPath, ns)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, obj := range snap.Objects {
+			state.Insert(fmt.Sprintf("%s %s/%s", strings.ToLower(obj.GetKind()), ns, obj.GetName()))
+		}
+	}
+
+	return state, nil
+}
+
+func describeObjects(objs sets.Set[string]) string {
+	if objs.Len() == 0 {
+		return "  none"
+	}
+
+	lines := sets.List(objs)
+	for i := range lines {
+		lines[i] = "  " + lines[i]
+	}
+
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,134 @@
+package kubetest
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Arneproductions/go-kube/internal/resources"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+func writeManifest(t *testing.T, manifest string) string {
+	path := filepath.Join(t.TempDir(), "manifest.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(manifest), 0o644))
+
+	return path
+}
+
+func cleanManifest(name string) string {
+	return fmt.Sprintf(`
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: %[1]s
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: %[1]s
+  namespace: %[1]s
+data:
+  foo: bar
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: %[1]s
+  namespace: default
+data:
+  foo: bar
+`, name)
+}
+
+// generatorManifest defines a Job that creates a ConfigMap, which is not deleted with the manifest
+func generatorManifest(name string) string {
+	return fmt.Sprintf(`
+apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: %[1]s
+  namespace: default
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: Role
+metadata:
+  name: %[1]s
+  namespace: default
+rules:
+- apiGroups: [""]
+  resources: ["configmaps"]
+  verbs: ["create"]
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: RoleBinding
+metadata:
+  name: %[1]s
+  namespace: default
+roleRef:
+  apiGroup: rbac.authorization.k8s.io
+  kind: Role
+  name: %[1]s
+subjects:
+- kind: ServiceAccount
+  name: %[1]s
+  namespace: default
+---
+apiVersion: batch/v1
+kind: Job
+metadata:
+  name: %[1]s
+  namespace: default
+spec:
+  backoffLimit: 2
+  template:
+    spec:
+      serviceAccountName: %[1]s
+      restartPolicy: Never
+      containers:
+      - name: generator
+        image: bitnami/kubectl:1.29
+        command: ["kubectl", "create", "configmap", "%[1]s-generated", "--namespace", "default"]
+`, name)
+}
+
+func TestNamespacesOf(t *testing.T) {
+	t.Run("namespacesOf_should_return_the_namespaces_of_the_objects", func(t *testing.T) {
+		manifest := writeManifest(t, cleanManifest("foo"))
+
+		got, err := namespacesOf(manifest)
+		require.NoError(t, err)
+		assert.Equal(t, sets.New("foo", "default"), got)
+	})
+}
+
+func TestAssertRoundTrip(t *testing.T) {
+	c := resources.NewEphemeralCluster()
+	require.NoError(t, c.Start())
+
+	t.Cleanup(func() {
+		require.NoError(t, c.Stop())
+	})
+
+	t.Run("AssertRoundTrip_should_pass_for_a_clean_manifest", func(t *testing.T) {
+		name := fmt.Sprintf("test-%s", uuid.New().String())
+
+		assert.True(t, AssertRoundTrip(t, c, writeManifest(t, cleanManifest(name))))
+	})
+
+	t.Run("AssertRoundTrip_should_fail_with_the_objects_left_behind", func(t *testing.T) {
+		name := fmt.Sprintf("test-%s", uuid.New().String())
+
+		recorder := &recordingT{}
+		assert.False(t, AssertRoundTrip(recorder, c, writeManifest(t, generatorManifest(name))))
+
+		require.Len(t, recorder.errors, 1)
+		assert.Contains(t, recorder.errors[0], fmt.Sprintf("left behind:\n  configmap default/%s-generated", name))
+		assert.Contains(t, recorder.errors[0], "removed:\n  none")
+	})
+}